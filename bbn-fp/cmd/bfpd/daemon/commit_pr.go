@@ -1,7 +1,6 @@
 package daemon
 
 import (
-	"context"
 	"fmt"
 	"math"
 	"path/filepath"
@@ -12,8 +11,6 @@ import (
 	"github.com/Manta-Network/manta-fp/bbn-fp/store"
 	fpcc "github.com/Manta-Network/manta-fp/clientcontroller"
 	eotsclient "github.com/Manta-Network/manta-fp/eotsmanager/client"
-	"github.com/Manta-Network/manta-fp/ethereum/node"
-	"github.com/Manta-Network/manta-fp/l2chain/opstack"
 	"github.com/Manta-Network/manta-fp/log"
 	"github.com/Manta-Network/manta-fp/metrics"
 	"github.com/Manta-Network/manta-fp/util"
@@ -92,23 +89,9 @@ func runCommandCommitPubRand(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to create EOTS manager client: %w", err)
 	}
-	opClient, err := node.DialEthClient(context.Background(), cfg.OpEventConfig.EthRpc)
-	if err != nil {
-		return fmt.Errorf("failed to create op client: %w", err)
-	}
-	sRStore, err := store.NewOpStateRootStore(db)
-	if err != nil {
-		return fmt.Errorf("failed to initiate op state root store: %w", err)
-	}
-
-	ep, err := opstack.NewEventProvider(context.Background(), logger)
-	if err != nil {
-		return fmt.Errorf("failed to initiate op event provider: %w", err)
-	}
-
 	fp, err := service.NewFinalityProviderInstance(
 		fpPk, cfg, fpStore, pubRandStore, cc, em, metrics.NewFpMetrics(), "",
-		make(chan<- *service.CriticalError), logger, opClient, sRStore, ep)
+		make(chan<- *service.CriticalError), logger, nil, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create bbn-fp %s instance: %w", fpPk.MarshalHex(), err)
 	}
@@ -0,0 +1,93 @@
+package daemon
+
+import (
+	"fmt"
+	"path/filepath"
+
+	fpcfg "github.com/Manta-Network/manta-fp/bbn-fp/config"
+	"github.com/Manta-Network/manta-fp/l2chain/opstack"
+	"github.com/Manta-Network/manta-fp/util"
+
+	"github.com/cosmos/cosmos-sdk/client"
+	"github.com/spf13/cobra"
+)
+
+const (
+	indexFlag          = "index"
+	l2BlockFlag        = "l2-block"
+	fromIndexFlag      = "from-index"
+	toIndexFlag        = "to-index"
+	disputeGamesFlag   = "dispute-games"
+	metricsAddressFlag = "metrics-address"
+)
+
+// CommandOutputs returns the outputs command of bfpd
+func CommandOutputs() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "outputs",
+		Short: "Query the outputs indexed by the running bfpd and whether they were signed.",
+		Example: `bfpd outputs --index 100 --home /home/user/.bfpd
+bfpd outputs --from-index 100 --to-index 120 --home /home/user/.bfpd
+bfpd outputs --l2-block 360000 --home /home/user/.bfpd
+bfpd outputs --dispute-games --index 7 --home /home/user/.bfpd`,
+		Args: cobra.NoArgs,
+		RunE: runOutputsCmd,
+	}
+	cmd.Flags().Uint64(indexFlag, 0, "The L2 output index, or the game index with --dispute-games")
+	cmd.Flags().Uint64(l2BlockFlag, 0, "The L2 block of the outputs and the dispute games")
+	cmd.Flags().Uint64(fromIndexFlag, 0, "The first index of the queried range")
+	cmd.Flags().Uint64(toIndexFlag, 0, "The last index of the queried range")
+	cmd.Flags().Bool(disputeGamesFlag, false, "Query the dispute games instead of the L2OutputOracle outputs")
+	cmd.Flags().String(metricsAddressFlag, "", "The metrics address the bfpd queries are served at, read from bfpd.conf if empty")
+	return cmd
+}
+
+func runOutputsCmd(cmd *cobra.Command, _ []string) error {
+	addr, err := cmd.Flags().GetString(metricsAddressFlag)
+	if err != nil {
+		return fmt.Errorf("failed to read flag %s: %w", metricsAddressFlag, err)
+	}
+	disputeGames, err := cmd.Flags().GetBool(disputeGamesFlag)
+	if err != nil {
+		return fmt.Errorf("failed to read flag %s: %w", disputeGamesFlag, err)
+	}
+
+	if addr == "" {
+		homePath, err := filepath.Abs(client.GetClientContextFromCmd(cmd).HomeDir)
+		if err != nil {
+			return err
+		}
+		cfg, err := fpcfg.LoadConfig(util.CleanAndExpandPath(homePath))
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		addr, err = cfg.Metrics.Address()
+		if err != nil {
+			return err
+		}
+	}
+
+	query := &opstack.OutputsQuery{}
+	for name, v := range map[string]**uint64{
+		indexFlag:     &query.Index,
+		l2BlockFlag:   &query.L2Block,
+		fromIndexFlag: &query.FromIndex,
+		toIndexFlag:   &query.ToIndex,
+	} {
+		if !cmd.Flags().Changed(name) {
+			continue
+		}
+		n, err := cmd.Flags().GetUint64(name)
+		if err != nil {
+			return fmt.Errorf("failed to read flag %s: %w", name, err)
+		}
+		*v = &n
+	}
+
+	outputs, err := opstack.QueryOutputs(cmd.Context(), addr, disputeGames, query)
+	if err != nil {
+		return fmt.Errorf("failed to query the outputs: %w", err)
+	}
+	printRespJSON(outputs)
+	return nil
+}
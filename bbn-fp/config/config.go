@@ -179,6 +179,14 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("invalid metrics config")
 	}
 
+	if cfg.OpEventConfig == nil {
+		return fmt.Errorf("empty op event config")
+	}
+
+	if err := cfg.OpEventConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid op event config: %w", err)
+	}
+
 	return nil
 }
 
@@ -1,10 +1,16 @@
 package config
 
 import (
+	"fmt"
 	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Manta-Network/manta-fp/ethereum/node"
 )
 
 var (
+	defaultHeadMode           = string(node.HeadModeLatest)
 	defaultScanSize           = uint32(500)
 	defaultEthRpc             = "http://127.0.0.1:8545"
 	defaultL2OutputOracleAddr = "0x0"
@@ -13,13 +19,20 @@ var (
 )
 
 type OpEventConfig struct {
-	ChainId            uint          `long:"chain_id" description:"The chain id of the chain"`
-	BlockStep          uint64        `long:"block_step" description:"The block step of chain blocks scan"`
-	BufferSize         uint32        `long:"buffersize" description:"The maximum number of ethereum blocks that can be stored in the buffer"`
-	EthRpc             string        `long:"ethrpc" description:"The rpc uri of ethereum"`
-	L2OutputOracleAddr string        `long:"l2outputoracleaddr" description:"The contract address of L2OutputOracle address"`
-	PollInterval       time.Duration `long:"pollinterval" description:"The interval between each polling of blocks; the value should be set depending on the block production time but could be set smaller for quick catching up"`
-	ScanStartHeight    uint64        `long:"scantartheight" description:"The height from which we start polling the chain"`
+	ChainId                uint          `long:"chain_id" description:"The chain id of the chain"`
+	BlockStep              uint64        `long:"block_step" description:"The block step of chain blocks scan"`
+	BufferSize             uint32        `long:"buffersize" description:"The maximum number of ethereum blocks that can be stored in the buffer"`
+	EthRpc                 string        `long:"ethrpc" description:"The rpc uri of ethereum"`
+	EthRpcs                []string      `long:"ethrpcs" description:"The rpc uris of ethereum the chain events are read from, used instead of ethrpc when set; the reads fail over between the endpoints"`
+	EthRpcQuorum           uint          `long:"ethrpcquorum" description:"The number of the ethrpcs endpoints that must return the same block hashes and logs before they are used, 0 or 1 disables the quorum"`
+	L2Rpc                  string        `long:"l2rpc" description:"The rpc uri of an L2 op-node or execution node, the proposed output roots are verified against it before signing when it's set"`
+	L2OutputOracleAddr     string        `long:"l2outputoracleaddr" description:"The contract address of L2OutputOracle address"`
+	IndexDisputeGames      bool          `long:"indexdisputegames" description:"Whether to index the games created by the DisputeGameFactory and vote on their root claims along with the L2OutputOracle outputs"`
+	DisputeGameFactoryAddr string        `long:"disputegamefactoryaddr" description:"The contract address of DisputeGameFactory, required when the dispute games are indexed"`
+	PollInterval           time.Duration `long:"pollinterval" description:"The interval between each polling of blocks; the value should be set depending on the block production time but could be set smaller for quick catching up"`
+	HeadMode               string        `long:"headmode" description:"The L1 head the chain is polled up to, the safe and finalized heads trade latency for reorg safety" choice:"latest" choice:"safe" choice:"finalized"`
+	ConfirmationDepth      uint64        `long:"confirmationdepth" description:"The number of blocks below the head that are not polled yet"`
+	ScanStartHeight        uint64        `long:"scantartheight" description:"The height from which we start polling the chain"`
 
 	OPFinalityGadgetAddress string `long:"op-finality-gadget" description:"the contract address of the op-finality-gadget"`
 }
@@ -30,6 +43,34 @@ func DefaultOpEventConfig() OpEventConfig {
 		EthRpc:             defaultEthRpc,
 		L2OutputOracleAddr: defaultL2OutputOracleAddr,
 		PollInterval:       defaultPollInterval,
+		HeadMode:           defaultHeadMode,
 		ScanStartHeight:    defaultStartHeight,
 	}
 }
+
+// EthRpcUrls returns the rpc uris the chain events are read from
+func (cfg *OpEventConfig) EthRpcUrls() []string {
+	if len(cfg.EthRpcs) > 0 {
+		return cfg.EthRpcs
+	}
+	return []string{cfg.EthRpc}
+}
+
+func (cfg *OpEventConfig) Validate() error {
+	if _, err := node.ParseHeadMode(cfg.HeadMode); err != nil {
+		return err
+	}
+	if int(cfg.EthRpcQuorum) > len(cfg.EthRpcUrls()) {
+		return fmt.Errorf("the eth rpc quorum %d is above the number of eth rpc uris %d", cfg.EthRpcQuorum, len(cfg.EthRpcUrls()))
+	}
+	if cfg.IndexDisputeGames {
+		if !common.IsHexAddress(cfg.DisputeGameFactoryAddr) || common.HexToAddress(cfg.DisputeGameFactoryAddr) == (common.Address{}) {
+			return fmt.Errorf("invalid dispute game factory address %q, it's required when the dispute games are indexed", cfg.DisputeGameFactoryAddr)
+		}
+		// anyone can create a dispute game, its root claim is only checked against an L2 node
+		if cfg.L2Rpc == "" {
+			return fmt.Errorf("the l2rpc is required when the dispute games are indexed")
+		}
+	}
+	return nil
+}
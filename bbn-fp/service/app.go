@@ -27,6 +27,7 @@ import (
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/lightningnetwork/lnd/kvdb"
 	"go.uber.org/zap"
 )
@@ -45,10 +46,13 @@ type FinalityProviderApp struct {
 	logger       *zap.Logger
 	input        *strings.Reader
 
-	opClient node.EthClient
-	sRStore  *store.OpStateRootStore
-	eP       *opstack.EventProvider
+	indexer *opstack.Indexer
+	// outputVerifier is nil when no L2 rpc is configured
+	outputVerifier *opstack.OutputVerifier
 
+	// fpInsMu guards fpIns, which is replaced by the event loops while the health
+	// checks and the rpc server read it
+	fpInsMu     sync.RWMutex
 	fpIns       *FinalityProviderInstance
 	eotsManager eotsmanager.EOTSManager
 
@@ -95,7 +99,7 @@ func NewFinalityProviderApp(
 	if err != nil {
 		return nil, fmt.Errorf("failed to initiate public randomness store: %w", err)
 	}
-	sRStore, err := store.NewOpStateRootStore(db)
+	sRStore, err := opstack.NewOpStateRootStore(db)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initiate op state root store: %w", err)
 	}
@@ -113,7 +117,7 @@ func NewFinalityProviderApp(
 
 	fpMetrics := metrics.NewFpMetrics()
 
-	opClient, err := node.DialEthClient(context.Background(), config.OpEventConfig.EthRpc)
+	opClient, err := node.DialMultiEthClient(context.Background(), config.OpEventConfig.EthRpcUrls(), config.OpEventConfig.EthRpcQuorum, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create op client: %w", err)
 	}
@@ -123,6 +127,19 @@ func NewFinalityProviderApp(
 		return nil, fmt.Errorf("failed to initiate op event provider: %w", err)
 	}
 
+	indexer, err := opstack.NewIndexer(logger, newIndexerConfig(config.OpEventConfig), opClient, sRStore, ep, fpMetrics)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initiate the indexer: %w", err)
+	}
+
+	var outputVerifier *opstack.OutputVerifier
+	if config.OpEventConfig.L2Rpc != "" {
+		outputVerifier, err = opstack.NewOutputVerifier(context.Background(), config.OpEventConfig.L2Rpc, fpMetrics, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initiate output verifier: %w", err)
+		}
+	}
+
 	return &FinalityProviderApp{
 		cc:                                cc,
 		fps:                               fpStore,
@@ -133,9 +150,8 @@ func NewFinalityProviderApp(
 		input:                             input,
 		fpIns:                             nil,
 		eotsManager:                       em,
-		opClient:                          opClient,
-		sRStore:                           sRStore,
-		eP:                                ep,
+		indexer:                           indexer,
+		outputVerifier:                    outputVerifier,
 		metrics:                           fpMetrics,
 		quit:                              make(chan struct{}),
 		unjailFinalityProviderRequestChan: make(chan *UnjailFinalityProviderRequest),
@@ -144,6 +160,35 @@ func NewFinalityProviderApp(
 	}, nil
 }
 
+// newIndexerConfig returns the indexer configuration of the op event configuration
+func newIndexerConfig(cfg *fpcfg.OpEventConfig) *opstack.IndexerConfig {
+	return &opstack.IndexerConfig{
+		EthRpcs:                cfg.EthRpcUrls(),
+		EthRpcQuorum:           cfg.EthRpcQuorum,
+		ChainId:                cfg.ChainId,
+		StartHeight:            cfg.ScanStartHeight,
+		BlockStep:              cfg.BlockStep,
+		PollInterval:           cfg.PollInterval,
+		HeadMode:               cfg.HeadMode,
+		ConfirmationDepth:      cfg.ConfirmationDepth,
+		L2OutputOracleAddr:     common.HexToAddress(cfg.L2OutputOracleAddr),
+		IndexDisputeGames:      cfg.IndexDisputeGames,
+		DisputeGameFactoryAddr: common.HexToAddress(cfg.DisputeGameFactoryAddr),
+	}
+}
+
+// QueryHandler returns the queries of the indexed outputs, an output is signed once the
+// finality provider voted at or above its L1 block
+func (app *FinalityProviderApp) QueryHandler() *opstack.QueryHandler {
+	return opstack.NewQueryHandler(app.indexer.Store(), func(stateRoot *types.StateRoot) (bool, error) {
+		fpIns := app.getFpIns()
+		if fpIns == nil {
+			return false, nil
+		}
+		return stateRoot.L1BlockNumber <= fpIns.GetLastVotedHeight(), nil
+	}, app.logger)
+}
+
 func (app *FinalityProviderApp) GetConfig() *fpcfg.Config {
 	return app.config
 }
@@ -193,11 +238,19 @@ func (app *FinalityProviderApp) ListAllFinalityProvidersInfo() ([]*proto.Finalit
 
 // GetFinalityProviderInstance returns the bbn-fp instance with the given Babylon public key
 func (app *FinalityProviderApp) GetFinalityProviderInstance() (*FinalityProviderInstance, error) {
-	if app.fpIns == nil {
+	fpIns := app.getFpIns()
+	if fpIns == nil {
 		return nil, fmt.Errorf("finality provider does not exist")
 	}
 
-	return app.fpIns, nil
+	return fpIns, nil
+}
+
+// getFpIns returns the finality provider instance, nil is returned if there is none
+func (app *FinalityProviderApp) getFpIns() *FinalityProviderInstance {
+	app.fpInsMu.RLock()
+	defer app.fpInsMu.RUnlock()
+	return app.fpIns
 }
 
 // StartFinalityProvider starts a finality provider instance with the given EOTS public key
@@ -323,11 +376,11 @@ func (app *FinalityProviderApp) Stop() error {
 		close(app.quit)
 		app.wg.Wait()
 
-		if app.fpIns != nil && app.fpIns.IsRunning() {
-			pkHex := app.fpIns.GetBtcPkHex()
+		if fpIns := app.getFpIns(); fpIns != nil && fpIns.IsRunning() {
+			pkHex := fpIns.GetBtcPkHex()
 			app.logger.Info("stopping finality provider", zap.String("pk", pkHex))
 
-			if err := app.fpIns.Stop(); err != nil {
+			if err := fpIns.Stop(); err != nil {
 				stopErr = fmt.Errorf("failed to close the fp instance: %w", err)
 				return
 			}
@@ -335,6 +388,14 @@ func (app *FinalityProviderApp) Stop() error {
 			app.logger.Info("finality provider is stopped", zap.String("pk", pkHex))
 		}
 
+		// the indexer is started along with the finality provider instance
+		if app.indexer.IsRunning() {
+			if err := app.indexer.Stop(); err != nil {
+				stopErr = fmt.Errorf("failed to stop the indexer: %w", err)
+				return
+			}
+		}
+
 		app.logger.Debug("Stopping EOTS manager")
 		if err := app.eotsManager.Close(); err != nil {
 			stopErr = fmt.Errorf("failed to close the EOTS manager: %w", err)
@@ -487,39 +548,46 @@ func (app *FinalityProviderApp) startFinalityProviderInstance(
 	passphrase string,
 ) error {
 	pkHex := pk.MarshalHex()
+	app.fpInsMu.Lock()
 	if app.fpIns == nil {
 		fpIns, err := NewFinalityProviderInstance(
 			pk, app.config, app.fps, app.pubRandStore, app.cc, app.eotsManager,
-			app.metrics, passphrase, app.criticalErrChan, app.logger, app.opClient,
-			app.sRStore, app.eP,
+			app.metrics, passphrase, app.criticalErrChan, app.logger, app.indexer,
+			app.outputVerifier,
 		)
 		if err != nil {
+			app.fpInsMu.Unlock()
 			return fmt.Errorf("failed to create finality provider instance %s: %w", pkHex, err)
 		}
 
 		app.fpIns = fpIns
 	} else if !pk.Equals(app.fpIns.btcPk) {
+		bondedPkHex := app.fpIns.btcPk.MarshalHex()
+		app.fpInsMu.Unlock()
 		return fmt.Errorf("the finality provider daemon is already bonded with the finality provider %s,"+
-			"please restart the daemon to switch to another instance", app.fpIns.btcPk.MarshalHex())
+			"please restart the daemon to switch to another instance", bondedPkHex)
 	}
+	fpIns := app.fpIns
+	app.fpInsMu.Unlock()
 
-	return app.fpIns.Start()
+	return fpIns.Start()
 }
 
 func (app *FinalityProviderApp) IsFinalityProviderRunning(fpPk *bbntypes.BIP340PubKey) bool {
-	if app.fpIns == nil {
+	fpIns := app.getFpIns()
+	if fpIns == nil {
 		return false
 	}
 
-	if app.fpIns.GetBtcPkHex() != fpPk.MarshalHex() {
+	if fpIns.GetBtcPkHex() != fpPk.MarshalHex() {
 		return false
 	}
 
-	return app.fpIns.IsRunning()
+	return fpIns.IsRunning()
 }
 
 func (app *FinalityProviderApp) removeFinalityProviderInstance() error {
-	fpi := app.fpIns
+	fpi := app.getFpIns()
 	if fpi == nil {
 		return fmt.Errorf("the finality provider instance does not exist")
 	}
@@ -529,7 +597,11 @@ func (app *FinalityProviderApp) removeFinalityProviderInstance() error {
 		}
 	}
 
-	app.fpIns = nil
+	app.fpInsMu.Lock()
+	if app.fpIns == fpi {
+		app.fpIns = nil
+	}
+	app.fpInsMu.Unlock()
 
 	return nil
 }
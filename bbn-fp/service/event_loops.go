@@ -64,7 +64,7 @@ func (app *FinalityProviderApp) monitorStatusUpdate() {
 	for {
 		select {
 		case <-statusUpdateTicker.C:
-			fpi := app.fpIns
+			fpi := app.getFpIns()
 			if fpi == nil {
 				continue
 			}
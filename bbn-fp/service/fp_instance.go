@@ -1,10 +1,11 @@
 package service
 
 import (
+	"context"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"math"
-	"math/big"
 	"strings"
 	"sync"
 	"time"
@@ -14,7 +15,6 @@ import (
 	"github.com/Manta-Network/manta-fp/bbn-fp/store"
 	"github.com/Manta-Network/manta-fp/clientcontroller"
 	"github.com/Manta-Network/manta-fp/eotsmanager"
-	"github.com/Manta-Network/manta-fp/ethereum/node"
 	"github.com/Manta-Network/manta-fp/l2chain/opstack"
 	"github.com/Manta-Network/manta-fp/metrics"
 	"github.com/Manta-Network/manta-fp/types"
@@ -35,14 +35,16 @@ type FinalityProviderInstance struct {
 	pubRandState *pubRandState
 	cfg          *fpcfg.Config
 
-	logger   *zap.Logger
-	em       eotsmanager.EOTSManager
-	cc       clientcontroller.ClientController
-	poller   *OpChainPoller
-	metrics  *metrics.FpMetrics
-	opClient node.EthClient
-	sRStore  *store.OpStateRootStore
-	eP       *opstack.EventProvider
+	logger       *zap.Logger
+	em           eotsmanager.EOTSManager
+	cc           clientcontroller.ClientController
+	indexer      *opstack.Indexer
+	subscription *opstack.Subscription
+	metrics      *metrics.FpMetrics
+	// outputVerifier is nil when no L2 rpc is configured
+	outputVerifier *opstack.OutputVerifier
+	// submissions reports the blocks pending submission for too long to the health checks
+	submissions *metrics.SubmissionTracker
 
 	blockInfoChan chan *types.BlockInfo
 
@@ -70,9 +72,8 @@ func NewFinalityProviderInstance(
 	passphrase string,
 	errChan chan<- *CriticalError,
 	logger *zap.Logger,
-	opClient node.EthClient,
-	sRStore *store.OpStateRootStore,
-	eP *opstack.EventProvider,
+	indexer *opstack.Indexer,
+	outputVerifier *opstack.OutputVerifier,
 ) (*FinalityProviderInstance, error) {
 	var sfp *store.StoredFinalityProvider
 	var err error
@@ -97,7 +98,7 @@ func NewFinalityProviderInstance(
 		return nil, fmt.Errorf("the finality provider instance cannot be initiated with status %s", sfp.Status.String())
 	}
 
-	return newFinalityProviderInstanceFromStore(sfp, cfg, s, prStore, cc, em, metrics, passphrase, errChan, logger, opClient, sRStore, eP)
+	return newFinalityProviderInstanceFromStore(sfp, cfg, s, prStore, cc, em, metrics, passphrase, errChan, logger, indexer, outputVerifier)
 }
 
 // Helper function to create FinalityProviderInstance from store data
@@ -108,13 +109,12 @@ func newFinalityProviderInstanceFromStore(
 	prStore *store.PubRandProofStore,
 	cc clientcontroller.ClientController,
 	em eotsmanager.EOTSManager,
-	metrics *metrics.FpMetrics,
+	fpMetrics *metrics.FpMetrics,
 	passphrase string,
 	errChan chan<- *CriticalError,
 	logger *zap.Logger,
-	opClient node.EthClient,
-	sRStore *store.OpStateRootStore,
-	eP *opstack.EventProvider,
+	indexer *opstack.Indexer,
+	outputVerifier *opstack.OutputVerifier,
 ) (*FinalityProviderInstance, error) {
 	return &FinalityProviderInstance{
 		btcPk:           bbntypes.NewBIP340PubKeyFromBTCPK(sfp.BtcPk),
@@ -128,10 +128,10 @@ func newFinalityProviderInstanceFromStore(
 		passphrase:      passphrase,
 		em:              em,
 		cc:              cc,
-		metrics:         metrics,
-		opClient:        opClient,
-		sRStore:         sRStore,
-		eP:              eP,
+		metrics:         fpMetrics,
+		indexer:         indexer,
+		outputVerifier:  outputVerifier,
+		submissions:     metrics.NewSubmissionTracker(metrics.SubmissionTimeout(cfg.SignatureSubmissionInterval, cfg.SubmissionRetryInterval, cfg.MaxSubmissionRetries)),
 	}, nil
 }
 
@@ -154,18 +154,22 @@ func (fp *FinalityProviderInstance) Start() error {
 	fp.logger.Info("starting the finality provider",
 		zap.String("pk", fp.GetBtcPkHex()), zap.Uint64("height", startHeight))
 
-	fp.sRStore.AddLatestBlock(big.NewInt(int64(startHeight)))
-
-	poller, err := NewOpChainPoller(fp.logger, fp.opClient, startHeight, fp.cfg.OpEventConfig, fp.sRStore, fp.eP, fp.metrics)
-	if err != nil {
-		return fmt.Errorf("failed to new op chain poller: %w", err)
+	// the indexer resumes from its last indexed block, the start height is only used
+	// when nothing is indexed yet
+	if !fp.indexer.IsRunning() {
+		if err := fp.indexer.Start(startHeight); err != nil {
+			return fmt.Errorf("failed to start the indexer with start height %d: %w", startHeight, err)
+		}
 	}
 
-	if err := poller.Start(startHeight); err != nil {
-		return fmt.Errorf("failed to start the poller with start height %d: %w", startHeight, err)
+	// the outputs proposed above the start height are delivered again, even if they were
+	// already indexed
+	subscription, err := fp.indexer.Subscribe("bbn-fp-"+fp.GetBtcPkHex(), startHeight, fp.cfg.OpEventConfig.BufferSize)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to the indexer: %w", err)
 	}
 
-	fp.poller = poller
+	fp.subscription = subscription
 	fp.quit = make(chan struct{})
 
 	fp.wg.Add(2)
@@ -180,8 +184,8 @@ func (fp *FinalityProviderInstance) Stop() error {
 		return fmt.Errorf("the bbn-fp %s has already stopped", fp.GetBtcPkHex())
 	}
 
-	if err := fp.poller.Stop(); err != nil {
-		return fmt.Errorf("failed to stop the poller: %w", err)
+	if fp.subscription != nil {
+		fp.subscription.Close()
 	}
 
 	fp.logger.Info("stopping bbn-fp instance", zap.String("pk", fp.GetBtcPkHex()))
@@ -212,6 +216,7 @@ func (fp *FinalityProviderInstance) finalitySigSubmissionLoop() {
 	for {
 		select {
 		case <-time.After(fp.cfg.SignatureSubmissionInterval):
+			fp.processOutputsDeleted()
 			pollerBlocks := fp.getRandomnessCommitmentBlocksFromChan()
 			if len(pollerBlocks) == 0 {
 				continue
@@ -226,6 +231,7 @@ func (fp *FinalityProviderInstance) finalitySigSubmissionLoop() {
 				// the finality provider does not have voting power
 				// and it will never will at this block
 				fp.metrics.IncrementFpTotalBlocksWithoutVotingPower(fp.GetBtcPkHex())
+				fp.ackBlocks(pollerBlocks)
 				continue
 			}
 
@@ -235,6 +241,7 @@ func (fp *FinalityProviderInstance) finalitySigSubmissionLoop() {
 				zap.Uint64("start_height", pollerBlocks[0].Height),
 				zap.Uint64("end_height", targetHeight),
 			)
+			fp.submissions.Pending()
 			res, err := fp.retrySubmitSigsUntilFinalized(pollerBlocks)
 			if err != nil {
 				fp.metrics.IncrementFpTotalFailedVotes(fp.GetBtcPkHex())
@@ -247,8 +254,12 @@ func (fp *FinalityProviderInstance) finalitySigSubmissionLoop() {
 				// this can happen when a finality signature is not needed
 				// either if the block is already submitted or the signature
 				// is already submitted
+				fp.submissions.Skipped()
+				fp.ackBlocks(pollerBlocks)
 				continue
 			}
+			fp.submissions.Submitted()
+			fp.ackBlocks(pollerBlocks)
 			fp.logger.Info(
 				"successfully submitted the finality signature to the consumer chain",
 				zap.String("consumer_id", string(fp.GetChainID())),
@@ -269,7 +280,7 @@ func (fp *FinalityProviderInstance) getAllBlocksFromChan() []*types.BlockInfo {
 	var pollerBlocks []*types.BlockInfo
 	for {
 		select {
-		case b := <-fp.poller.GetBlockInfoChan():
+		case b := <-fp.subscription.GetBlockInfoChan():
 			// TODO: in cases of catching up, this could issue frequent RPC calls
 			shouldProcess, err := fp.shouldProcessBlock(b)
 			if err != nil {
@@ -280,6 +291,8 @@ func (fp *FinalityProviderInstance) getAllBlocksFromChan() []*types.BlockInfo {
 			}
 			if shouldProcess {
 				pollerBlocks = append(pollerBlocks, b)
+			} else {
+				fp.subscription.Ack(b.Seq)
 			}
 			if len(pollerBlocks) == int(fp.cfg.BatchSubmissionSize) {
 				return pollerBlocks
@@ -346,8 +359,12 @@ func (fp *FinalityProviderInstance) randomnessCommitmentLoop() {
 				fp.reportCriticalErr(err)
 				continue
 			}
+			// only the last block is voted, the blocks before it are handled
+			fp.ackBlocks(pollerBlocks[:len(pollerBlocks)-1])
 			// txRes could be nil if no need to commit more randomness
-			if txRes != nil {
+			if txRes == nil {
+				fp.subscription.Ack(pollerBlocks[len(pollerBlocks)-1].Seq)
+			} else {
 				fp.blockInfoChan <- nextBlock
 				fp.logger.Info(
 					"successfully committed public randomness to the consumer chain",
@@ -381,6 +398,14 @@ func (fp *FinalityProviderInstance) hasVotingPower(b *types.BlockInfo) (bool, er
 	return true, nil
 }
 
+// ackBlocks acknowledges the handled blocks to the subscription, the blocks which are not
+// acknowledged are delivered again on restart
+func (fp *FinalityProviderInstance) ackBlocks(blocks []*types.BlockInfo) {
+	for _, b := range blocks {
+		fp.subscription.Ack(b.Seq)
+	}
+}
+
 func (fp *FinalityProviderInstance) reportCriticalErr(err error) {
 	fp.criticalErrChan <- &CriticalError{
 		err:     err,
@@ -694,6 +719,19 @@ func (fp *FinalityProviderInstance) SubmitBatchFinalitySignatures(blocks []*type
 		return nil, fmt.Errorf("should not submit batch finality signature with too many blocks")
 	}
 
+	blocks, err := fp.skipDeletedOutputs(blocks)
+	if err != nil {
+		return nil, err
+	}
+	// the outputs the L2 node disagrees with are skipped and fail the health check
+	blocks, err = fp.outputVerifier.VerifyBlocks(context.Background(), blocks)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) == 0 {
+		return nil, nil
+	}
+
 	// get public randomness list
 	// #nosec G115 -- performed the conversion check above
 	prList, err := fp.getPubRandList(blocks[0].L2BlockNumber.Uint64(), uint32(len(blocks)))
@@ -738,6 +776,53 @@ func (fp *FinalityProviderInstance) SubmitBatchFinalitySignatures(blocks []*type
 	return res, nil
 }
 
+// skipDeletedOutputs drops the outputs deleted from the L2OutputOracle, they must never be voted
+func (fp *FinalityProviderInstance) skipDeletedOutputs(blocks []*types.BlockInfo) ([]*types.BlockInfo, error) {
+	remaining := make([]*types.BlockInfo, 0, len(blocks))
+	for _, b := range blocks {
+		if !b.IsDisputeGame() {
+			deleted, err := fp.indexer.Store().IsOutputDeleted(b.L2OutputIndex, b.StateRoot.StateRoot)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check the deleted outputs: %w", err)
+			}
+			if deleted {
+				fp.logger.Warn("skip voting the deleted output",
+					zap.String("pk", fp.GetBtcPkHex()),
+					zap.String("l2_output_index", b.L2OutputIndex.String()),
+				)
+				continue
+			}
+		}
+		remaining = append(remaining, b)
+	}
+	return remaining, nil
+}
+
+// processOutputsDeleted records the votes which covered an output deleted from the L2OutputOracle,
+// the votes are made at the L1 heights of the outputs
+func (fp *FinalityProviderInstance) processOutputsDeleted() {
+	for {
+		select {
+		case outputsDeleted := <-fp.subscription.GetOutputsDeletedChan():
+			for _, stateRoot := range outputsDeleted.StateRoots {
+				if stateRoot.L1BlockNumber > fp.GetLastVotedHeight() {
+					continue
+				}
+				fp.metrics.IncrementFpTotalVotedDeletedOutputs(fp.GetBtcPkHex())
+				fp.logger.Warn("the voted output is deleted from the l2 output oracle",
+					zap.String("pk", fp.GetBtcPkHex()),
+					zap.String("l2_output_index", stateRoot.L2OutputIndex.String()),
+					zap.String("state_root", hex.EncodeToString(stateRoot.StateRoot[:])),
+					zap.Uint64("height", stateRoot.L1BlockNumber),
+				)
+			}
+			fp.subscription.Ack(outputsDeleted.Seq)
+		default:
+			return
+		}
+	}
+}
+
 // TestSubmitFinalitySignatureAndExtractPrivKey is exposed for presentation/testing purpose to allow manual sending finality signature
 // this API is the same as SubmitBatchFinalitySignatures except that we don't constraint the voting height and update status
 // Note: this should not be used in the submission loop
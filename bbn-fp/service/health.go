@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Manta-Network/manta-fp/metrics"
+)
+
+// RegisterHealthChecks registers the health of the indexer, the EOTS manager, the
+// Babylon rpc, the finality provider instance and the output verifier on the metrics server
+func (app *FinalityProviderApp) RegisterHealthChecks(s *metrics.Server) {
+	s.AddLivenessCheck("indexer", app.checkIndexer)
+	s.AddReadinessCheck("eots_manager", app.checkEOTSManager)
+	s.AddReadinessCheck("babylon_rpc", app.checkBabylonRpc)
+	s.AddReadinessCheck("finality_provider", app.checkFinalityProvider)
+	if app.outputVerifier != nil {
+		s.AddReadinessCheck("output_verifier", app.outputVerifier.CheckHealth)
+	}
+}
+
+// checkIndexer only checks a started indexer, the indexer is started along with the
+// finality provider instance
+func (app *FinalityProviderApp) checkIndexer(ctx context.Context) (string, error) {
+	if !app.indexer.IsRunning() {
+		return "not started", nil
+	}
+	return app.indexer.CheckHealth(ctx)
+}
+
+func (app *FinalityProviderApp) checkEOTSManager(_ context.Context) (string, error) {
+	pinger, ok := app.eotsManager.(interface{ Ping() error })
+	if !ok {
+		return "local", nil
+	}
+	if err := pinger.Ping(); err != nil {
+		return "", fmt.Errorf("the EOTS manager is unreachable: %w", err)
+	}
+	return "reachable", nil
+}
+
+func (app *FinalityProviderApp) checkBabylonRpc(_ context.Context) (string, error) {
+	block, err := app.cc.QueryCometBestBlock()
+	if err != nil {
+		return "", fmt.Errorf("the babylon rpc is unreachable: %w", err)
+	}
+	return fmt.Sprintf("best block %d", block.Height), nil
+}
+
+// checkFinalityProvider fails until a finality provider instance is running and while
+// its signatures are pending submission for too long
+func (app *FinalityProviderApp) checkFinalityProvider(ctx context.Context) (string, error) {
+	fpIns := app.getFpIns()
+	if fpIns == nil || !fpIns.IsRunning() {
+		return "", errors.New("no finality provider instance is running")
+	}
+	return fpIns.submissions.Check(ctx)
+}
@@ -59,6 +59,9 @@ func (s *Server) StartFinalityProviderServer() error {
 		return fmt.Errorf("failed to get prometheus address: %w", err)
 	}
 	s.metricsServer = metrics.Start(promAddr, s.logger)
+	s.rpcServer.app.QueryHandler().Register(s.metricsServer)
+	s.metricsServer.AddLivenessCheck("db", metrics.DBHealthCheck(s.db))
+	s.rpcServer.app.RegisterHealthChecks(s.metricsServer)
 
 	listenAddr := s.cfg.RPCListener
 	// we create listeners from the RPCListeners defined
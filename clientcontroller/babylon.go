@@ -81,7 +81,7 @@ func NewBabylonController(
 		return nil, fmt.Errorf("failed to create CW client: %w", err)
 	}
 
-	opClient, err := opclient.DialEthClient(context.Background(), opCfg.EthRpc)
+	opClient, err := opclient.DialMultiEthClient(context.Background(), opCfg.EthRpcUrls(), opCfg.EthRpcQuorum, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create op client: %w", err)
 	}
@@ -24,6 +24,7 @@ func NewRootCmd() *cobra.Command {
 		NewInitCmd(),
 		NewKeysCmd(),
 		NewStartCmd(),
+		NewSignRecordsCmd(),
 		version.CommandVersion("eotsd"),
 	)
 
@@ -0,0 +1,115 @@
+package daemon
+
+import (
+	"fmt"
+
+	"github.com/Manta-Network/manta-fp/eotsmanager/config"
+	"github.com/Manta-Network/manta-fp/eotsmanager/store"
+	"github.com/Manta-Network/manta-fp/types"
+
+	sdkflags "github.com/cosmos/cosmos-sdk/client/flags"
+	"github.com/spf13/cobra"
+)
+
+// NewSignRecordsCmd returns the commands moving the sign records between hosts, the
+// daemon must be stopped as they open the database
+func NewSignRecordsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign-records",
+		Short: "Export or import the EOTS sign records in the sign record interchange format.",
+	}
+	cmd.AddCommand(newSignRecordsExportCmd(), newSignRecordsImportCmd())
+	return cmd
+}
+
+func newSignRecordsExportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "export [file]",
+		Short:   "Export the sign records of all the keys and chains to an interchange file.",
+		Long:    "Export the sign records of all the keys and chains to an interchange file. Stop eotsd before exporting so that no signature is missed.",
+		Example: `eotsd sign-records export sign-records.json --home /home/user/.eotsd`,
+		Args:    cobra.ExactArgs(1),
+		RunE:    exportSignRecords,
+	}
+	cmd.Flags().String(sdkflags.FlagHome, config.DefaultEOTSDir, "The path to the eotsd home directory")
+	return cmd
+}
+
+func newSignRecordsImportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import [file]",
+		Short: "Import the sign records of an interchange file.",
+		Long: "Import the sign records of an interchange file. The records are merged with the existing ones, " +
+			"the import is refused if a different message is recorded at an imported height. " +
+			"The keys never sign at or below the imported highest signed height of a chain.",
+		Example: `eotsd sign-records import sign-records.json --home /home/user/.eotsd`,
+		Args:    cobra.ExactArgs(1),
+		RunE:    importSignRecords,
+	}
+	cmd.Flags().String(sdkflags.FlagHome, config.DefaultEOTSDir, "The path to the eotsd home directory")
+	return cmd
+}
+
+func exportSignRecords(cmd *cobra.Command, args []string) error {
+	es, closeDB, err := openEOTSStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	interchange, err := es.ExportSignRecords()
+	if err != nil {
+		return fmt.Errorf("failed to export the sign records: %w", err)
+	}
+	if err := interchange.Save(args[0]); err != nil {
+		return fmt.Errorf("failed to write the interchange file: %w", err)
+	}
+
+	records := 0
+	for _, signer := range interchange.Data {
+		records += len(signer.SignedMessages)
+	}
+	cmd.Printf("exported %d sign records of %d keys to %s\n", records, len(interchange.Data), args[0])
+	return nil
+}
+
+func importSignRecords(cmd *cobra.Command, args []string) error {
+	interchange, err := types.LoadSignInterchange(args[0], types.SigningSchemeEOTS)
+	if err != nil {
+		return fmt.Errorf("failed to load the interchange file: %w", err)
+	}
+
+	es, closeDB, err := openEOTSStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	imported, skipped, err := es.ImportSignRecords(interchange)
+	if err != nil {
+		return fmt.Errorf("failed to import the sign records: %w", err)
+	}
+	cmd.Printf("imported %d sign records, %d already recorded\n", imported, skipped)
+	return nil
+}
+
+func openEOTSStore(cmd *cobra.Command) (*store.EOTSStore, func(), error) {
+	homePath, err := getHomePath(cmd)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load home flag: %w", err)
+	}
+	cfg, err := config.LoadConfig(homePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config at %s: %w", homePath, err)
+	}
+	dbBackend, err := cfg.DatabaseConfig.GetDBBackend()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create db backend: %w", err)
+	}
+	es, err := store.NewEOTSStore(dbBackend)
+	if err != nil {
+		_ = dbBackend.Close()
+		return nil, nil, fmt.Errorf("failed to open the EOTS store: %w", err)
+	}
+	return es, func() { _ = dbBackend.Close() }, nil
+}
@@ -226,6 +226,23 @@ func (lm *LocalEOTSManager) SignEOTS(eotsPk []byte, chainID []byte, msg []byte,
 		return nil, eotstypes.ErrDoubleSign
 	}
 
+	// the records below the imported highest signed height may have been left out of the import
+	signedHeight, found, err := lm.es.GetHighestSignedHeight(eotsPk, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting highest signed height: %w", err)
+	}
+	if found && height <= signedHeight {
+		lm.logger.Error(
+			"sign requested below the highest signed height",
+			zap.String("eots_pk", hex.EncodeToString(eotsPk)),
+			zap.Uint64("height", height),
+			zap.Uint64("highest_signed_height", signedHeight),
+			zap.String("chainID", string(chainID)),
+		)
+
+		return nil, eotstypes.ErrBelowSignedHeight
+	}
+
 	privRand, _, err := lm.getRandomnessPair(eotsPk, chainID, height, passphrase)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get private randomness: %w", err)
@@ -99,6 +99,9 @@ func (s *Server) RunUntilShutdown() error {
 	// actually start listening for requests.
 	s.startGrpcListen(grpcServer, []net.Listener{lis})
 
+	metricsServer.AddLivenessCheck("db", metrics.DBHealthCheck(s.db))
+	metricsServer.AddReadinessCheck("rpc_server", rpcHealthCheck(lis.Addr().String()))
+
 	s.logger.Info("EOTS Manager Daemon is fully active!")
 
 	// Wait for shutdown signal from either a graceful server stop or from
@@ -130,3 +133,16 @@ func (s *Server) startGrpcListen(grpcServer *grpc.Server, listeners []net.Listen
 	// Wait for gRPC servers to be up running.
 	wg.Wait()
 }
+
+// rpcHealthCheck checks that the RPC server accepts connections on the address
+func rpcHealthCheck(addr string) metrics.HealthCheck {
+	return func(ctx context.Context) (string, error) {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return "", fmt.Errorf("the rpc server is not accepting connections: %w", err)
+		}
+		_ = conn.Close()
+		return fmt.Sprintf("listening on %s", addr), nil
+	}
+}
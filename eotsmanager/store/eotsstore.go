@@ -17,6 +17,9 @@ import (
 var (
 	eotsBucketName       = []byte("fpKeyNames")
 	signRecordBucketName = []byte("signRecord")
+	// signedHeightBucketName keeps the highest signed height per chain and key imported
+	// along with the sign records
+	signedHeightBucketName = []byte("highestSignedHeight")
 )
 
 type EOTSStore struct {
@@ -44,6 +47,11 @@ func (s *EOTSStore) initBuckets() error {
 			return err
 		}
 
+		_, err = tx.CreateTopLevelBucket(signedHeightBucketName)
+		if err != nil {
+			return err
+		}
+
 		return nil
 	})
 }
@@ -1,6 +1,7 @@
 package store_test
 
 import (
+	"encoding/hex"
 	"math/rand"
 	"os"
 	"testing"
@@ -8,6 +9,7 @@ import (
 	"github.com/Manta-Network/manta-fp/eotsmanager/config"
 	"github.com/Manta-Network/manta-fp/eotsmanager/store"
 	"github.com/Manta-Network/manta-fp/testutil"
+	"github.com/Manta-Network/manta-fp/types"
 
 	"github.com/babylonlabs-io/babylon/testutil/datagen"
 	"github.com/btcsuite/btcd/btcec/v2/schnorr"
@@ -125,3 +127,65 @@ func FuzzSignStore(f *testing.F) {
 		require.False(t, found)
 	})
 }
+
+// FuzzSignRecordsInterchange tests that the exported sign records are imported on another
+// host and that the conflicting records are refused
+func FuzzSignRecordsInterchange(f *testing.F) {
+	testutil.AddRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		t.Parallel()
+		r := rand.New(rand.NewSource(seed))
+
+		newStore := func() *store.EOTSStore {
+			cfg := config.DefaultDBConfigWithHomePath(t.TempDir())
+			dbBackend, err := cfg.GetDBBackend()
+			require.NoError(t, err)
+			t.Cleanup(func() { dbBackend.Close() })
+			es, err := store.NewEOTSStore(dbBackend)
+			require.NoError(t, err)
+			return es
+		}
+		from, to := newStore(), newStore()
+
+		_, btcPk, err := datagen.GenRandomBTCKeyPair(r)
+		require.NoError(t, err)
+		pk := schnorr.SerializePubKey(btcPk)
+		chainID := []byte(datagen.GenRandomHexStr(r, 10))
+		height := datagen.RandomInt(r, 100) + 1
+		msg := datagen.GenRandomByteArray(r, 32)
+		sig := datagen.GenRandomByteArray(r, 32)
+		require.NoError(t, from.SaveSignRecord(height, chainID, msg, pk, sig))
+
+		interchange, err := from.ExportSignRecords()
+		require.NoError(t, err)
+		require.Len(t, interchange.Data, 1)
+		require.Equal(t, height, interchange.Data[0].HighestSignedHeight)
+
+		imported, skipped, err := to.ImportSignRecords(interchange)
+		require.NoError(t, err)
+		require.Equal(t, 1, imported)
+		require.Equal(t, 0, skipped)
+		record, found, err := to.GetSignRecord(pk, chainID, height)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, msg, record.Msg)
+		require.Equal(t, sig, record.Signature)
+		signedHeight, found, err := to.GetHighestSignedHeight(pk, chainID)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, height, signedHeight)
+
+		// importing the same records again is a no-op
+		imported, skipped, err = to.ImportSignRecords(interchange)
+		require.NoError(t, err)
+		require.Equal(t, 0, imported)
+		require.Equal(t, 1, skipped)
+
+		// a different msg at the same height is refused
+		conflicting, err := from.ExportSignRecords()
+		require.NoError(t, err)
+		conflicting.Data[0].SignedMessages[0].Msg = hex.EncodeToString(datagen.GenRandomByteArray(r, 32))
+		_, _, err = to.ImportSignRecords(conflicting)
+		require.ErrorIs(t, err, types.ErrConflictingSignRecord)
+	})
+}
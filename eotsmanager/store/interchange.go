@@ -0,0 +1,214 @@
+package store
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/Manta-Network/manta-fp/eotsmanager/proto"
+	"github.com/Manta-Network/manta-fp/types"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/lightningnetwork/lnd/kvdb"
+	pm "google.golang.org/protobuf/proto"
+)
+
+// signRecordKeySuffixLen is the length of the pk and the height at the end of a record key,
+// the chain id is the rest of the key
+const signRecordKeySuffixLen = schnorr.PubKeyBytesLen + 8
+
+// ExportSignRecords returns all the sign records in the interchange format, one signer
+// per chain and key
+func (s *EOTSStore) ExportSignRecords() (*types.SignInterchange, error) {
+	var interchange *types.SignInterchange
+	err := s.db.View(func(tx kvdb.RTx) error {
+		interchange = types.NewSignInterchange(types.SigningSchemeEOTS)
+		bucket := tx.ReadBucket(signRecordBucketName)
+		if bucket == nil {
+			return ErrCorruptedEOTSDb
+		}
+		heightBucket := tx.ReadBucket(signedHeightBucketName)
+		if heightBucket == nil {
+			return ErrCorruptedEOTSDb
+		}
+
+		// the signers are listed in the order of their first record
+		signers := make(map[string]*types.InterchangeSigner)
+		getSigner := func(chainID, pk []byte) (*types.InterchangeSigner, error) {
+			signerKey := getSignedHeightKey(chainID, pk)
+			if signer, ok := signers[string(signerKey)]; ok {
+				return signer, nil
+			}
+			if !utf8.Valid(chainID) {
+				return nil, fmt.Errorf("the chain id %x can't be exported", chainID)
+			}
+			signer := &types.InterchangeSigner{
+				PubKey:         hex.EncodeToString(pk),
+				ChainID:        string(chainID),
+				SignedMessages: []*types.InterchangeSignedMessage{},
+			}
+			if v := heightBucket.Get(signerKey); v != nil {
+				signer.HighestSignedHeight = sdk.BigEndianToUint64(v)
+			}
+			signers[string(signerKey)] = signer
+			interchange.Data = append(interchange.Data, signer)
+			return signer, nil
+		}
+
+		err := bucket.ForEach(func(k, v []byte) error {
+			if len(k) < signRecordKeySuffixLen {
+				return ErrCorruptedEOTSDb
+			}
+			chainID, pk, height := splitSignRecordKey(k)
+			record := &proto.SigningRecord{}
+			if err := pm.Unmarshal(v, record); err != nil {
+				return err
+			}
+			signer, err := getSigner(chainID, pk)
+			if err != nil {
+				return err
+			}
+			signer.SignedMessages = append(signer.SignedMessages, &types.InterchangeSignedMessage{
+				Height:    height,
+				Msg:       hex.EncodeToString(record.Msg),
+				Signature: hex.EncodeToString(record.EotsSig),
+				Timestamp: record.Timestamp,
+			})
+			if height > signer.HighestSignedHeight {
+				signer.HighestSignedHeight = height
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// the imported heights of the keys without any record are kept too
+		return heightBucket.ForEach(func(k, v []byte) error {
+			if len(k) < schnorr.PubKeyBytesLen {
+				return ErrCorruptedEOTSDb
+			}
+			_, err := getSigner(k[:len(k)-schnorr.PubKeyBytesLen], k[len(k)-schnorr.PubKeyBytesLen:])
+			return err
+		})
+	}, func() {})
+	if err != nil {
+		return nil, err
+	}
+	return interchange, nil
+}
+
+// ImportSignRecords merges the sign records of the interchange. The import is refused
+// as a whole if a different msg is recorded at the height of an imported record, the
+// highest signed height of every chain and key is raised to the imported one
+func (s *EOTSStore) ImportSignRecords(interchange *types.SignInterchange) (imported int, skipped int, err error) {
+	if interchange.Metadata.SigningScheme != types.SigningSchemeEOTS {
+		return 0, 0, fmt.Errorf("the signing scheme %q can't be imported to eotsd", interchange.Metadata.SigningScheme)
+	}
+	if err := interchange.Validate(); err != nil {
+		return 0, 0, err
+	}
+
+	err = kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		imported, skipped = 0, 0
+		bucket := tx.ReadWriteBucket(signRecordBucketName)
+		if bucket == nil {
+			return ErrCorruptedEOTSDb
+		}
+		heightBucket := tx.ReadWriteBucket(signedHeightBucketName)
+		if heightBucket == nil {
+			return ErrCorruptedEOTSDb
+		}
+
+		for _, signer := range interchange.Data {
+			pk, err := hex.DecodeString(signer.PubKey)
+			if err != nil || len(pk) != schnorr.PubKeyBytesLen {
+				return fmt.Errorf("invalid BIP340 public key %s", signer.PubKey)
+			}
+			chainID := []byte(signer.ChainID)
+
+			for _, signedMsg := range signer.SignedMessages {
+				msg, _ := hex.DecodeString(signedMsg.Msg)
+				sig, _ := hex.DecodeString(signedMsg.Signature)
+				key := getSignRecordKey(chainID, pk, signedMsg.Height)
+
+				if v := bucket.Get(key); v != nil {
+					record := &proto.SigningRecord{}
+					if err := pm.Unmarshal(v, record); err != nil {
+						return err
+					}
+					if !bytes.Equal(record.Msg, msg) {
+						return fmt.Errorf("%w: pk %s chain %s height %d", types.ErrConflictingSignRecord, signer.PubKey, signer.ChainID, signedMsg.Height)
+					}
+					skipped++
+					continue
+				}
+
+				marshalled, err := pm.Marshal(&proto.SigningRecord{
+					Msg:       msg,
+					EotsSig:   sig,
+					Timestamp: signedMsg.Timestamp,
+				})
+				if err != nil {
+					return err
+				}
+				if err := bucket.Put(key, marshalled); err != nil {
+					return err
+				}
+				imported++
+			}
+
+			heightKey := getSignedHeightKey(chainID, pk)
+			if v := heightBucket.Get(heightKey); v != nil && sdk.BigEndianToUint64(v) >= signer.HighestSignedHeight {
+				continue
+			}
+			if err := heightBucket.Put(heightKey, sdk.Uint64ToBigEndian(signer.HighestSignedHeight)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return imported, skipped, nil
+}
+
+// GetHighestSignedHeight returns the highest signed height imported for the chain and key
+func (s *EOTSStore) GetHighestSignedHeight(eotsPk, chainID []byte) (uint64, bool, error) {
+	var (
+		height uint64
+		found  bool
+	)
+	err := s.db.View(func(tx kvdb.RTx) error {
+		height, found = 0, false
+		bucket := tx.ReadBucket(signedHeightBucketName)
+		if bucket == nil {
+			return ErrCorruptedEOTSDb
+		}
+		if v := bucket.Get(getSignedHeightKey(chainID, eotsPk)); v != nil {
+			height, found = sdk.BigEndianToUint64(v), true
+		}
+		return nil
+	}, func() {})
+	if err != nil {
+		return 0, false, err
+	}
+	return height, found, nil
+}
+
+// splitSignRecordKey splits the record key (chainID || pk || height)
+func splitSignRecordKey(key []byte) (chainID, pk []byte, height uint64) {
+	heightStart := len(key) - 8
+	pkStart := heightStart - schnorr.PubKeyBytesLen
+	return key[:pkStart], key[pkStart:heightStart], sdk.BigEndianToUint64(key[heightStart:])
+}
+
+// the highest signed height key is (chainID || pk)
+func getSignedHeightKey(chainID, pk []byte) []byte {
+	key := make([]byte, 0, len(chainID)+len(pk))
+	key = append(key, chainID...)
+	return append(key, pk...)
+}
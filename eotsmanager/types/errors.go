@@ -5,4 +5,7 @@ import "errors"
 var (
 	ErrFinalityProviderAlreadyExisted = errors.New("the finality provider has already existed")
 	ErrDoubleSign                     = errors.New("double sign")
+	// ErrBelowSignedHeight indicates a sign request without a record at or below the highest
+	// signed height imported along with the sign records of the key
+	ErrBelowSignedHeight = errors.New("height is not above the imported highest signed height")
 )
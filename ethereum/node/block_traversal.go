@@ -16,8 +16,27 @@ var (
 	ErrBlockTraversalAheadOfProvider            = errors.New("the BlockTraversal's internal state is ahead of the provider")
 	ErrBlockTraversalAndProviderMismatchedState = errors.New("the BlockTraversal and provider have diverged in state")
 	ErrBlockTraversalCheckBlockFail             = errors.New("the BlockTraversal check block height fail")
+	ErrBlockTraversalReorg                      = errors.New("the BlockTraversal detected a reorg")
 )
 
+// HeadMode selects the L1 head the BlockTraversal traverses up to
+type HeadMode string
+
+const (
+	HeadModeLatest    HeadMode = "latest"
+	HeadModeSafe      HeadMode = "safe"
+	HeadModeFinalized HeadMode = "finalized"
+)
+
+func ParseHeadMode(mode string) (HeadMode, error) {
+	switch HeadMode(mode) {
+	case HeadModeLatest, HeadModeSafe, HeadModeFinalized:
+		return HeadMode(mode), nil
+	default:
+		return "", fmt.Errorf("invalid head mode: %s", mode)
+	}
+}
+
 type BlockTraversal struct {
 	ethClient EthClient
 	chainId   uint
@@ -26,33 +45,55 @@ type BlockTraversal struct {
 	latestBlock        *big.Int
 	lastTraversedBlock *big.Int
 
+	headMode               HeadMode
 	blockConfirmationDepth *big.Int
+	headerTracker          *HeaderTracker
 }
 
-func NewBlockTraversal(ethClient EthClient, fromBlock *big.Int, confDepth *big.Int, chainId uint, logger *zap.Logger) *BlockTraversal {
+// NewBlockTraversal creates the BlockTraversal which traverses up to the head selected by
+// headMode minus confDepth blocks
+func NewBlockTraversal(ethClient EthClient, fromBlock *big.Int, headMode HeadMode, confDepth *big.Int, chainId uint, logger *zap.Logger) *BlockTraversal {
 	return &BlockTraversal{
 		ethClient:              ethClient,
 		lastTraversedBlock:     fromBlock,
+		headMode:               headMode,
 		blockConfirmationDepth: confDepth,
+		headerTracker:          NewHeaderTracker(DefaultHeaderTrackerSize),
 		chainId:                chainId,
 		log:                    logger,
 	}
 }
 
+// LatestBlock returns the height of the head selected by the head mode
 func (f *BlockTraversal) LatestBlock() *big.Int {
 	return f.latestBlock
 }
 
+func (f *BlockTraversal) HeadMode() HeadMode {
+	return f.headMode
+}
+
+func (f *BlockTraversal) headHeader() (*types.Header, error) {
+	switch f.headMode {
+	case HeadModeSafe:
+		return f.ethClient.LatestSafeBlockHeader()
+	case HeadModeFinalized:
+		return f.ethClient.LatestFinalizedBlockHeader()
+	default:
+		return f.ethClient.BlockHeaderByNumber(nil)
+	}
+}
+
 func (f *BlockTraversal) LastTraversedHeader() *big.Int {
 	return f.lastTraversedBlock
 }
 
 func (f *BlockTraversal) NextHeaders(maxSize uint64) ([]types.Header, error) {
-	latestHeader, err := f.ethClient.BlockHeaderByNumber(nil)
+	latestHeader, err := f.headHeader()
 	if err != nil {
-		return nil, fmt.Errorf("unable to query latest block: %w", err)
+		return nil, fmt.Errorf("unable to query %s block: %w", f.headMode, err)
 	} else if latestHeader == nil {
-		return nil, fmt.Errorf("latest header unreported")
+		return nil, fmt.Errorf("%s header unreported", f.headMode)
 	} else {
 		f.latestBlock = latestHeader.Number
 	}
@@ -95,10 +136,42 @@ func (f *BlockTraversal) NextHeaders(maxSize uint64) ([]types.Header, error) {
 		f.log.Error("Err header traversal and provider mismatched state", zap.Uint64("parentNumber = ", headers[0].Number.Uint64()), zap.Uint64("number = ", f.lastTraversedBlock.Uint64()))
 		return nil, ErrBlockTraversalAndProviderMismatchedState
 	}
+	for i := 1; i < numHeaders; i++ {
+		if headers[i].ParentHash != headers[i-1].Hash() {
+			// the provider reorged while the range was queried, retry on the next call
+			f.log.Warn("header traversal queried a non contiguous range", zap.Uint64("number", headers[i].Number.Uint64()))
+			return nil, ErrBlockTraversalAndProviderMismatchedState
+		}
+	}
+	if f.headerTracker.IsReorged(&headers[0]) {
+		f.log.Warn("header traversal detected a reorg", zap.Uint64("number", headers[0].Number.Uint64()), zap.String("parentHash", headers[0].ParentHash.String()))
+		return nil, ErrBlockTraversalReorg
+	}
+	f.headerTracker.Add(headers)
 	f.lastTraversedBlock = headers[numHeaders-1].Number
 	return headers, nil
 }
 
+// SeedHeaders tracks the headers persisted before a restart, so that a reorg of the
+// last traversed blocks is detected when the traversal resumes
+func (f *BlockTraversal) SeedHeaders(headers []TrackedHeader) {
+	f.headerTracker.Seed(headers)
+}
+
+// RollbackReorg rewinds the traversal to the latest traversed header that is still
+// canonical and returns its height, the headers above it are traversed again
+func (f *BlockTraversal) RollbackReorg() (*big.Int, error) {
+	forkPoint, err := f.headerTracker.FindForkPoint(f.ethClient)
+	if err != nil {
+		return nil, err
+	}
+	f.log.Warn("header traversal rolls back to the fork point", zap.Uint64("forkPoint", forkPoint.Uint64()))
+	f.headerTracker.Rewind(forkPoint)
+	f.lastTraversedBlock = forkPoint
+	return forkPoint, nil
+}
+
 func (f *BlockTraversal) ChangeLastTraversedHeaderByDelAfter(dbLatestBlock *big.Int) {
+	f.headerTracker.Rewind(dbLatestBlock)
 	f.lastTraversedBlock = dbLatestBlock
 }
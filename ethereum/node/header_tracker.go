@@ -0,0 +1,114 @@
+package node
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	// DefaultHeaderTrackerSize is the number of recent headers kept to detect
+	// reorgs, a reorg deeper than it cannot be rolled back automatically
+	DefaultHeaderTrackerSize = 256
+)
+
+var (
+	ErrReorgTooDeep = errors.New("the reorg is deeper than the tracked headers")
+)
+
+// HeaderProvider returns the canonical header at the given height
+type HeaderProvider interface {
+	BlockHeaderByNumber(*big.Int) (*types.Header, error)
+}
+
+// TrackedHeader is the hash of a traversed header, the tracked headers are persisted
+// so that a reorg across a restart is detected
+type TrackedHeader struct {
+	Number *big.Int
+	Hash   common.Hash
+}
+
+// HeaderTracker keeps the hashes of the recently traversed headers so that a
+// header whose parent doesn't match the previously traversed one is detected
+type HeaderTracker struct {
+	size    int
+	headers []TrackedHeader
+}
+
+func NewHeaderTracker(size int) *HeaderTracker {
+	return &HeaderTracker{
+		size:    size,
+		headers: make([]TrackedHeader, 0, size),
+	}
+}
+
+// Add tracks the headers, they must be in the ascending order of height and
+// follow the latest tracked header
+func (t *HeaderTracker) Add(headers []types.Header) {
+	for i := range headers {
+		t.headers = append(t.headers, TrackedHeader{
+			Number: new(big.Int).Set(headers[i].Number),
+			Hash:   headers[i].Hash(),
+		})
+	}
+	t.truncate()
+}
+
+// Seed tracks the persisted headers, they must be in the ascending order of height and
+// may be sparse, the fork point is then searched among them only
+func (t *HeaderTracker) Seed(headers []TrackedHeader) {
+	for _, header := range headers {
+		t.headers = append(t.headers, TrackedHeader{
+			Number: new(big.Int).Set(header.Number),
+			Hash:   header.Hash,
+		})
+	}
+	t.truncate()
+}
+
+func (t *HeaderTracker) truncate() {
+	if len(t.headers) > t.size {
+		t.headers = append(t.headers[:0], t.headers[len(t.headers)-t.size:]...)
+	}
+}
+
+// IsReorged returns true if the parent of the header is tracked with a different hash
+func (t *HeaderTracker) IsReorged(header *types.Header) bool {
+	if len(t.headers) == 0 || header.Number.Sign() == 0 {
+		return false
+	}
+	parentNumber := new(big.Int).Sub(header.Number, big.NewInt(1))
+	for i := len(t.headers) - 1; i >= 0; i-- {
+		if t.headers[i].Number.Cmp(parentNumber) == 0 {
+			return t.headers[i].Hash != header.ParentHash
+		}
+	}
+	return false
+}
+
+// FindForkPoint walks back the tracked headers and returns the height of the
+// latest one which is still canonical according to the provider
+func (t *HeaderTracker) FindForkPoint(provider HeaderProvider) (*big.Int, error) {
+	for i := len(t.headers) - 1; i >= 0; i-- {
+		header, err := provider.BlockHeaderByNumber(t.headers[i].Number)
+		if err != nil {
+			return nil, fmt.Errorf("unable to query header %s: %w", t.headers[i].Number.String(), err)
+		}
+		if header != nil && header.Hash() == t.headers[i].Hash {
+			return new(big.Int).Set(t.headers[i].Number), nil
+		}
+	}
+	return nil, ErrReorgTooDeep
+}
+
+// Rewind drops the tracked headers above the given height
+func (t *HeaderTracker) Rewind(number *big.Int) {
+	i := len(t.headers)
+	for i > 0 && t.headers[i-1].Number.Cmp(number) > 0 {
+		i--
+	}
+	t.headers = t.headers[:i]
+}
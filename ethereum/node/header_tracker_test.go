@@ -0,0 +1,99 @@
+package node_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Manta-Network/manta-fp/ethereum/node"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockHeaderProvider struct {
+	headers map[uint64]*types.Header
+}
+
+func (p *mockHeaderProvider) BlockHeaderByNumber(number *big.Int) (*types.Header, error) {
+	return p.headers[number.Uint64()], nil
+}
+
+// makeChain returns the headers of a chain from height `from` to `to`, the extra
+// data differentiates the forks
+func makeChain(parent common.Hash, from, to uint64, fork byte) []types.Header {
+	headers := make([]types.Header, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		header := types.Header{
+			ParentHash: parent,
+			Number:     new(big.Int).SetUint64(i),
+			Extra:      []byte{fork},
+		}
+		parent = header.Hash()
+		headers = append(headers, header)
+	}
+	return headers
+}
+
+func TestHeaderTracker(t *testing.T) {
+	tracker := node.NewHeaderTracker(8)
+
+	chain := makeChain(common.Hash{}, 0, 10, 0)
+	tracker.Add(chain[:6])
+
+	// the next header of the same chain is not reorged
+	require.False(t, tracker.IsReorged(&chain[6]))
+
+	// the chain is reorged from height 4
+	fork := append(append([]types.Header{}, chain[:4]...), makeChain(chain[3].Hash(), 4, 10, 1)...)
+	require.True(t, tracker.IsReorged(&fork[6]))
+
+	provider := &mockHeaderProvider{headers: make(map[uint64]*types.Header)}
+	for i := range fork {
+		provider.headers[fork[i].Number.Uint64()] = &fork[i]
+	}
+	forkPoint, err := tracker.FindForkPoint(provider)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), forkPoint.Uint64())
+
+	// the canonical headers after the fork point are tracked again
+	tracker.Rewind(forkPoint)
+	require.False(t, tracker.IsReorged(&fork[4]))
+	tracker.Add(fork[4:7])
+	require.False(t, tracker.IsReorged(&fork[7]))
+	require.True(t, tracker.IsReorged(&chain[7]))
+
+	// a reorg deeper than the tracked headers cannot be rolled back
+	tracker.Add(fork[7:])
+	deepFork := makeChain(common.Hash{}, 0, 10, 2)
+	for i := range deepFork {
+		provider.headers[deepFork[i].Number.Uint64()] = &deepFork[i]
+	}
+	_, err = tracker.FindForkPoint(provider)
+	require.ErrorIs(t, err, node.ErrReorgTooDeep)
+}
+
+func TestHeaderTrackerSeed(t *testing.T) {
+	chain := makeChain(common.Hash{}, 0, 10, 0)
+
+	// the tracker restored from the persisted headers detects the reorg of the last one
+	tracker := node.NewHeaderTracker(8)
+	tracker.Seed([]node.TrackedHeader{
+		{Number: chain[2].Number, Hash: chain[2].Hash()},
+		{Number: chain[5].Number, Hash: chain[5].Hash()},
+	})
+	require.False(t, tracker.IsReorged(&chain[6]))
+
+	fork := append(append([]types.Header{}, chain[:4]...), makeChain(chain[3].Hash(), 4, 10, 1)...)
+	require.True(t, tracker.IsReorged(&fork[6]))
+
+	// the fork point is searched among the persisted headers only
+	provider := &mockHeaderProvider{headers: make(map[uint64]*types.Header)}
+	for i := range fork {
+		provider.headers[fork[i].Number.Uint64()] = &fork[i]
+	}
+	forkPoint, err := tracker.FindForkPoint(provider)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), forkPoint.Uint64())
+}
@@ -0,0 +1,266 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"go.uber.org/zap"
+)
+
+// MultiContractCaller is a bind.ContractCaller over several RPC endpoints, with a
+// quorum above one the call results must be returned identically by at least quorum
+// endpoints, otherwise the calls fail over between the endpoints
+type MultiContractCaller struct {
+	urls    []string
+	clients []*ethclient.Client
+	quorum  int
+	log     *zap.Logger
+}
+
+// DialMultiContractCaller dials all the endpoints, at least quorum endpoints must be reachable
+func DialMultiContractCaller(ctx context.Context, rpcUrls []string, quorum uint, logger *zap.Logger) (*MultiContractCaller, error) {
+	if len(rpcUrls) == 0 {
+		return nil, errors.New("no rpc url is set")
+	}
+	if int(quorum) > len(rpcUrls) {
+		return nil, fmt.Errorf("the quorum %d is above the number of rpc urls %d", quorum, len(rpcUrls))
+	}
+
+	c := &MultiContractCaller{quorum: int(quorum), log: logger}
+	for _, rpcUrl := range rpcUrls {
+		client, err := DialEthClientWithTimeout(ctx, rpcUrl, false)
+		if err != nil {
+			logger.Warn("failed to dial rpc endpoint", zap.String("url", rpcUrl), zap.Error(err))
+			continue
+		}
+		c.urls = append(c.urls, rpcUrl)
+		c.clients = append(c.clients, client)
+	}
+	if len(c.clients) == 0 || len(c.clients) < c.quorum {
+		c.Close()
+		return nil, fmt.Errorf("only %d of %d rpc endpoints are reachable, the quorum is %d", len(c.clients), len(rpcUrls), quorum)
+	}
+	return c, nil
+}
+
+func (c *MultiContractCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return c.call(func(client *ethclient.Client) ([]byte, error) {
+		return client.CodeAt(ctx, contract, blockNumber)
+	})
+}
+
+func (c *MultiContractCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return c.call(func(client *ethclient.Client) ([]byte, error) {
+		return client.CallContract(ctx, call, blockNumber)
+	})
+}
+
+func (c *MultiContractCaller) call(call func(*ethclient.Client) ([]byte, error)) ([]byte, error) {
+	return clientsQuorumCall(c, call, func(res []byte) common.Hash {
+		return crypto.Keccak256Hash(res)
+	})
+}
+
+// clientsFailover returns the result of the first endpoint that answers
+func clientsFailover[T any](c *MultiContractCaller, call func(*ethclient.Client) (T, error)) (T, error) {
+	var (
+		zero    T
+		lastErr error
+	)
+	for _, client := range c.clients {
+		res, err := call(client)
+		if err == nil {
+			return res, nil
+		}
+		lastErr = err
+	}
+	return zero, lastErr
+}
+
+// clientsQuorumCall returns the result that at least quorum endpoints agree on, the
+// results are compared by their key. Without quorum the call fails over between the endpoints
+func clientsQuorumCall[T any](c *MultiContractCaller, call func(*ethclient.Client) (T, error), key func(T) common.Hash) (T, error) {
+	if c.quorum <= 1 {
+		return clientsFailover(c, call)
+	}
+
+	type result struct {
+		url string
+		res T
+		err error
+	}
+	results := make([]result, len(c.clients))
+	var wg sync.WaitGroup
+	for i, client := range c.clients {
+		wg.Add(1)
+		go func(i int, client *ethclient.Client) {
+			defer wg.Done()
+			res, err := call(client)
+			results[i] = result{url: c.urls[i], res: res, err: err}
+		}(i, client)
+	}
+	wg.Wait()
+
+	var zero T
+	votes := make(map[common.Hash]int)
+	notFound := 0
+	var lastErr error
+	for _, r := range results {
+		if r.err != nil {
+			if errors.Is(r.err, ethereum.NotFound) {
+				notFound++
+			}
+			lastErr = r.err
+			continue
+		}
+		k := key(r.res)
+		votes[k]++
+		if votes[k] >= c.quorum {
+			for _, other := range results {
+				if other.err == nil && key(other.res) != k {
+					c.log.Warn("rpc endpoint disagrees with the quorum", zap.String("url", other.url))
+				}
+			}
+			return r.res, nil
+		}
+	}
+	if notFound >= c.quorum {
+		return zero, ethereum.NotFound
+	}
+	return zero, noQuorumError(len(votes), len(results), c.quorum, lastErr)
+}
+
+func (c *MultiContractCaller) Close() {
+	for _, client := range c.clients {
+		client.Close()
+	}
+}
+
+// MultiContractBackend is a bind.ContractBackend over several RPC endpoints, the contracts
+// are read as by the MultiContractCaller and the receipts must be returned identically by
+// at least quorum endpoints. The transactions are sent to all the endpoints, the other
+// queries fail over between the endpoints as their results differ while the blocks propagate
+type MultiContractBackend struct {
+	*MultiContractCaller
+}
+
+// DialMultiContractBackend dials all the endpoints, at least quorum endpoints must be reachable
+func DialMultiContractBackend(ctx context.Context, rpcUrls []string, quorum uint, logger *zap.Logger) (*MultiContractBackend, error) {
+	caller, err := DialMultiContractCaller(ctx, rpcUrls, quorum, logger)
+	if err != nil {
+		return nil, err
+	}
+	return &MultiContractBackend{MultiContractCaller: caller}, nil
+}
+
+// SendTransaction sends the transaction to all the endpoints, it succeeds if any endpoint
+// accepts it, otherwise the error of the first endpoint is returned
+func (b *MultiContractBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	errs := make([]error, len(b.clients))
+	var wg sync.WaitGroup
+	for i, client := range b.clients {
+		wg.Add(1)
+		go func(i int, client *ethclient.Client) {
+			defer wg.Done()
+			errs[i] = client.SendTransaction(ctx, tx)
+		}(i, client)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err == nil {
+			return nil
+		}
+		b.log.Debug("rpc endpoint rejected the transaction", zap.String("url", b.urls[i]), zap.String("tx_hash", tx.Hash().String()), zap.Error(err))
+	}
+	return errs[0]
+}
+
+func (b *MultiContractBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return clientsQuorumCall(b.MultiContractCaller, func(client *ethclient.Client) (*types.Receipt, error) {
+		return client.TransactionReceipt(ctx, txHash)
+	}, receiptKey)
+}
+
+// receiptKey digests the block and the status of the receipt
+func receiptKey(receipt *types.Receipt) common.Hash {
+	if receipt == nil {
+		return common.Hash{}
+	}
+	return crypto.Keccak256Hash(receipt.TxHash.Bytes(), receipt.BlockHash.Bytes(), []byte{byte(receipt.Status)})
+}
+
+// HeaderByNumber returns the header the quorum agrees on, the latest header fails over
+// between the endpoints as their heads differ while the blocks propagate
+func (b *MultiContractBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	call := func(client *ethclient.Client) (*types.Header, error) {
+		return client.HeaderByNumber(ctx, number)
+	}
+	if number == nil {
+		return clientsFailover(b.MultiContractCaller, call)
+	}
+	return clientsQuorumCall(b.MultiContractCaller, call, headerKey)
+}
+
+func (b *MultiContractBackend) BlockNumber(ctx context.Context) (uint64, error) {
+	return clientsFailover(b.MultiContractCaller, func(client *ethclient.Client) (uint64, error) {
+		return client.BlockNumber(ctx)
+	})
+}
+
+func (b *MultiContractBackend) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return clientsFailover(b.MultiContractCaller, func(client *ethclient.Client) (*big.Int, error) {
+		return client.BalanceAt(ctx, account, blockNumber)
+	})
+}
+
+func (b *MultiContractBackend) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return clientsFailover(b.MultiContractCaller, func(client *ethclient.Client) ([]byte, error) {
+		return client.PendingCodeAt(ctx, account)
+	})
+}
+
+func (b *MultiContractBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return clientsFailover(b.MultiContractCaller, func(client *ethclient.Client) (uint64, error) {
+		return client.PendingNonceAt(ctx, account)
+	})
+}
+
+func (b *MultiContractBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return clientsFailover(b.MultiContractCaller, func(client *ethclient.Client) (*big.Int, error) {
+		return client.SuggestGasPrice(ctx)
+	})
+}
+
+func (b *MultiContractBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return clientsFailover(b.MultiContractCaller, func(client *ethclient.Client) (*big.Int, error) {
+		return client.SuggestGasTipCap(ctx)
+	})
+}
+
+func (b *MultiContractBackend) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	return clientsFailover(b.MultiContractCaller, func(client *ethclient.Client) (uint64, error) {
+		return client.EstimateGas(ctx, call)
+	})
+}
+
+func (b *MultiContractBackend) FilterLogs(ctx context.Context, query ethereum.FilterQuery) ([]types.Log, error) {
+	return clientsFailover(b.MultiContractCaller, func(client *ethclient.Client) ([]types.Log, error) {
+		return client.FilterLogs(ctx, query)
+	})
+}
+
+func (b *MultiContractBackend) SubscribeFilterLogs(ctx context.Context, query ethereum.FilterQuery, ch chan<- types.Log) (ethereum.Subscription, error) {
+	return clientsFailover(b.MultiContractCaller, func(client *ethclient.Client) (ethereum.Subscription, error) {
+		return client.SubscribeFilterLogs(ctx, query, ch)
+	})
+}
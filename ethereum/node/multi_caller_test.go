@@ -0,0 +1,126 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/Manta-Network/manta-fp/ethereum/node"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// txAPI accepts the raw transactions unless sendErr is set, and serves the receipt
+type txAPI struct {
+	sendErr error
+	sent    int
+	receipt *types.Receipt
+}
+
+func (api *txAPI) SendRawTransaction(_ hexutil.Bytes) (common.Hash, error) {
+	if api.sendErr != nil {
+		return common.Hash{}, api.sendErr
+	}
+	api.sent++
+	return common.Hash{}, nil
+}
+
+func (api *txAPI) GetTransactionReceipt(_ common.Hash) (*types.Receipt, error) {
+	return api.receipt, nil
+}
+
+func newTxEndpoint(t *testing.T, api *txAPI) string {
+	server := rpc.NewServer()
+	require.NoError(t, server.RegisterName("eth", api))
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(func() {
+		httpServer.Close()
+		server.Stop()
+	})
+	return httpServer.URL
+}
+
+func newTestReceipt(blockHash common.Hash, status uint64) *types.Receipt {
+	return &types.Receipt{
+		Status:      status,
+		Logs:        []*types.Log{},
+		TxHash:      common.HexToHash("0x01"),
+		BlockHash:   blockHash,
+		BlockNumber: big.NewInt(10),
+	}
+}
+
+func TestMultiContractBackendSendTransaction(t *testing.T) {
+	t.Parallel()
+
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, GasPrice: big.NewInt(1), Gas: 21000, Value: big.NewInt(0)})
+
+	down, up := &txAPI{sendErr: errors.New("endpoint is down")}, &txAPI{}
+	backend, err := node.DialMultiContractBackend(context.Background(), []string{
+		newTxEndpoint(t, down), newTxEndpoint(t, up),
+	}, 0, zap.NewNop())
+	require.NoError(t, err)
+	defer backend.Close()
+	// the transaction is sent once an endpoint accepts it
+	require.NoError(t, backend.SendTransaction(context.Background(), tx))
+	require.Equal(t, 1, up.sent)
+
+	backend, err = node.DialMultiContractBackend(context.Background(), []string{
+		newTxEndpoint(t, &txAPI{sendErr: errors.New("nonce too low")}),
+		newTxEndpoint(t, &txAPI{sendErr: errors.New("endpoint is down")}),
+	}, 0, zap.NewNop())
+	require.NoError(t, err)
+	defer backend.Close()
+	// the error of the first endpoint is returned when no endpoint accepts it
+	err = backend.SendTransaction(context.Background(), tx)
+	require.ErrorContains(t, err, "nonce too low")
+}
+
+func TestMultiContractBackendReceiptQuorum(t *testing.T) {
+	t.Parallel()
+
+	receipt := newTestReceipt(common.HexToHash("0x10"), types.ReceiptStatusSuccessful)
+	// the compromised endpoint reports the transaction mined in another block
+	fakeReceipt := newTestReceipt(common.HexToHash("0x11"), types.ReceiptStatusSuccessful)
+
+	backend, err := node.DialMultiContractBackend(context.Background(), []string{
+		newTxEndpoint(t, &txAPI{receipt: fakeReceipt}),
+		newTxEndpoint(t, &txAPI{receipt: receipt}),
+		newTxEndpoint(t, &txAPI{receipt: receipt}),
+	}, 2, zap.NewNop())
+	require.NoError(t, err)
+	defer backend.Close()
+	res, err := backend.TransactionReceipt(context.Background(), receipt.TxHash)
+	require.NoError(t, err)
+	require.Equal(t, receipt.BlockHash, res.BlockHash)
+
+	backend, err = node.DialMultiContractBackend(context.Background(), []string{
+		newTxEndpoint(t, &txAPI{receipt: fakeReceipt}),
+		newTxEndpoint(t, &txAPI{receipt: receipt}),
+		newTxEndpoint(t, &txAPI{}),
+	}, 2, zap.NewNop())
+	require.NoError(t, err)
+	defer backend.Close()
+	_, err = backend.TransactionReceipt(context.Background(), receipt.TxHash)
+	require.ErrorIs(t, err, node.ErrNoQuorum)
+
+	backend, err = node.DialMultiContractBackend(context.Background(), []string{
+		newTxEndpoint(t, &txAPI{receipt: receipt}),
+		newTxEndpoint(t, &txAPI{}),
+		newTxEndpoint(t, &txAPI{}),
+	}, 2, zap.NewNop())
+	require.NoError(t, err)
+	defer backend.Close()
+	// the transaction is not mined until the quorum returns its receipt
+	_, err = backend.TransactionReceipt(context.Background(), receipt.TxHash)
+	require.ErrorIs(t, err, ethereum.NotFound)
+}
@@ -0,0 +1,445 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"go.uber.org/zap"
+)
+
+const defaultHealthCheckInterval = 15 * time.Second
+
+// ErrNoQuorum is returned when fewer endpoints than the quorum agree on a result
+var ErrNoQuorum = errors.New("rpc endpoints did not reach quorum")
+
+type endpoint struct {
+	url     string
+	client  EthClient
+	healthy atomic.Bool
+}
+
+// MultiEthClient is an EthClient over several RPC endpoints. The calls fail over to the
+// next healthy endpoint when an endpoint fails, and with a quorum above one the block
+// hashes, the logs and the other chain data must be returned identically by at least
+// quorum endpoints, so that a single faulty or compromised endpoint can't feed us a
+// fake chain
+type MultiEthClient struct {
+	mu        sync.Mutex
+	endpoints []*endpoint
+	quorum    int
+	log       *zap.Logger
+
+	wg   sync.WaitGroup
+	quit chan struct{}
+}
+
+// DialMultiEthClient dials all the endpoints, a single endpoint without quorum is dialed
+// as a plain client. The endpoints that can't be dialed are dialed again by the health
+// checks, but at least quorum endpoints must be reachable at start
+func DialMultiEthClient(ctx context.Context, rpcUrls []string, quorum uint, logger *zap.Logger) (EthClient, error) {
+	if len(rpcUrls) == 0 {
+		return nil, errors.New("no rpc url is set")
+	}
+	if int(quorum) > len(rpcUrls) {
+		return nil, fmt.Errorf("the quorum %d is above the number of rpc urls %d", quorum, len(rpcUrls))
+	}
+	if len(rpcUrls) == 1 && quorum <= 1 {
+		return DialEthClient(ctx, rpcUrls[0])
+	}
+
+	endpoints := make([]*endpoint, 0, len(rpcUrls))
+	dialed := 0
+	for _, rpcUrl := range rpcUrls {
+		ep := &endpoint{url: rpcUrl}
+		client, err := DialEthClient(ctx, rpcUrl)
+		if err != nil {
+			logger.Warn("failed to dial rpc endpoint", zap.String("url", rpcUrl), zap.Error(err))
+		} else {
+			ep.client = client
+			ep.healthy.Store(true)
+			dialed++
+		}
+		endpoints = append(endpoints, ep)
+	}
+	if dialed == 0 || dialed < int(quorum) {
+		for _, ep := range endpoints {
+			if ep.client != nil {
+				ep.client.Close()
+			}
+		}
+		return nil, fmt.Errorf("only %d of %d rpc endpoints are reachable, the quorum is %d", dialed, len(rpcUrls), quorum)
+	}
+
+	c := newMultiEthClient(endpoints, quorum, logger)
+	c.wg.Add(1)
+	go c.healthCheckLoop(defaultHealthCheckInterval)
+	return c, nil
+}
+
+// NewMultiEthClient wraps the clients without health checks
+func NewMultiEthClient(clients []EthClient, quorum uint, logger *zap.Logger) (*MultiEthClient, error) {
+	if len(clients) == 0 || int(quorum) > len(clients) {
+		return nil, fmt.Errorf("the quorum %d is invalid for %d clients", quorum, len(clients))
+	}
+	endpoints := make([]*endpoint, 0, len(clients))
+	for i, client := range clients {
+		ep := &endpoint{url: fmt.Sprintf("endpoint-%d", i), client: client}
+		ep.healthy.Store(true)
+		endpoints = append(endpoints, ep)
+	}
+	return newMultiEthClient(endpoints, quorum, logger), nil
+}
+
+func newMultiEthClient(endpoints []*endpoint, quorum uint, logger *zap.Logger) *MultiEthClient {
+	return &MultiEthClient{
+		endpoints: endpoints,
+		quorum:    int(quorum),
+		log:       logger,
+		quit:      make(chan struct{}),
+	}
+}
+
+func (c *MultiEthClient) healthCheckLoop(interval time.Duration) {
+	defer c.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.checkHealth()
+		case <-c.quit:
+			return
+		}
+	}
+}
+
+// checkHealth dials the endpoints again and marks them healthy once they answer
+func (c *MultiEthClient) checkHealth() {
+	for i, ep := range c.snapshot() {
+		if ep.client == nil {
+			client, err := DialEthClient(context.Background(), ep.url)
+			if err != nil {
+				continue
+			}
+			// the client of an endpoint is never changed, the dialed endpoint replaces it
+			ep = &endpoint{url: ep.url, client: client}
+			c.mu.Lock()
+			c.endpoints[i] = ep
+			c.mu.Unlock()
+		}
+		_, err := ep.client.BlockNumber()
+		c.setHealthy(ep, err == nil)
+	}
+}
+
+func (c *MultiEthClient) setHealthy(ep *endpoint, healthy bool) {
+	if ep.healthy.Swap(healthy) != healthy {
+		c.log.Info("rpc endpoint health changed", zap.String("url", ep.url), zap.Bool("healthy", healthy))
+	}
+}
+
+// snapshot returns the endpoints
+func (c *MultiEthClient) snapshot() []*endpoint {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]*endpoint{}, c.endpoints...)
+}
+
+// available returns the dialed endpoints, the healthy ones first
+func (c *MultiEthClient) available() []*endpoint {
+	var healthy, unhealthy []*endpoint
+	for _, ep := range c.snapshot() {
+		switch {
+		case ep.client == nil:
+		case ep.healthy.Load():
+			healthy = append(healthy, ep)
+		default:
+			unhealthy = append(unhealthy, ep)
+		}
+	}
+	return append(healthy, unhealthy...)
+}
+
+// failover returns the result of the first endpoint that answers, trying the healthy
+// endpoints first
+func failover[T any](c *MultiEthClient, call func(EthClient) (T, error)) (T, error) {
+	var (
+		zero    T
+		lastErr = errors.New("no rpc endpoint is available")
+	)
+	for _, ep := range c.available() {
+		res, err := call(ep.client)
+		if err == nil {
+			c.setHealthy(ep, true)
+			return res, nil
+		}
+		// an endpoint lagging behind may not have the data yet
+		if !errors.Is(err, ethereum.NotFound) {
+			c.setHealthy(ep, false)
+		}
+		lastErr = err
+	}
+	return zero, lastErr
+}
+
+type quorumResult[T any] struct {
+	ep  *endpoint
+	res T
+	err error
+}
+
+// callAll calls the healthy endpoints, or all the endpoints when fewer than the
+// quorum are healthy
+func callAll[T any](c *MultiEthClient, call func(EthClient) (T, error)) []quorumResult[T] {
+	endpoints := c.available()
+	healthy := 0
+	for _, ep := range endpoints {
+		if ep.healthy.Load() {
+			healthy++
+		}
+	}
+	if healthy >= c.quorum {
+		endpoints = endpoints[:healthy]
+	}
+
+	results := make([]quorumResult[T], len(endpoints))
+	var wg sync.WaitGroup
+	for i, ep := range endpoints {
+		wg.Add(1)
+		go func(i int, ep *endpoint) {
+			defer wg.Done()
+			res, err := call(ep.client)
+			results[i] = quorumResult[T]{ep: ep, res: res, err: err}
+		}(i, ep)
+	}
+	wg.Wait()
+
+	for _, r := range results {
+		if r.err == nil {
+			c.setHealthy(r.ep, true)
+		} else if !errors.Is(r.err, ethereum.NotFound) {
+			c.setHealthy(r.ep, false)
+		}
+	}
+	return results
+}
+
+// quorumCall returns the result that at least quorum endpoints agree on, the results
+// are compared by their key. Without quorum the call fails over between the endpoints
+func quorumCall[T any](c *MultiEthClient, call func(EthClient) (T, error), key func(T) common.Hash) (T, error) {
+	if c.quorum <= 1 {
+		return failover(c, call)
+	}
+
+	var zero T
+	results := callAll(c, call)
+	votes := make(map[common.Hash]int)
+	notFound := 0
+	var lastErr error
+	for _, r := range results {
+		if r.err != nil {
+			if errors.Is(r.err, ethereum.NotFound) {
+				notFound++
+			}
+			lastErr = r.err
+			continue
+		}
+		k := key(r.res)
+		votes[k]++
+		if votes[k] >= c.quorum {
+			warnDisagreements(c.log, results, k, key)
+			return r.res, nil
+		}
+	}
+	if notFound >= c.quorum {
+		return zero, ethereum.NotFound
+	}
+	return zero, noQuorumError(len(votes), len(results), c.quorum, lastErr)
+}
+
+func warnDisagreements[T any](logger *zap.Logger, results []quorumResult[T], agreed common.Hash, key func(T) common.Hash) {
+	for _, r := range results {
+		if r.err == nil && key(r.res) != agreed {
+			logger.Warn("rpc endpoint disagrees with the quorum", zap.String("url", r.ep.url))
+		}
+	}
+}
+
+func noQuorumError(distinct, answered, quorum int, lastErr error) error {
+	if lastErr != nil {
+		return fmt.Errorf("%w: %d distinct results from %d endpoints, %d required to agree, last error: %v", ErrNoQuorum, distinct, answered, quorum, lastErr)
+	}
+	return fmt.Errorf("%w: %d distinct results from %d endpoints, %d required to agree", ErrNoQuorum, distinct, answered, quorum)
+}
+
+func headerKey(header *types.Header) common.Hash {
+	if header == nil {
+		return common.Hash{}
+	}
+	return header.Hash()
+}
+
+// confirmHeader checks that the quorum agrees on the head reported by one endpoint, the
+// heads of the endpoints differ while the blocks propagate
+func (c *MultiEthClient) confirmHeader(header *types.Header) (*types.Header, error) {
+	if c.quorum <= 1 {
+		return header, nil
+	}
+	return c.BlockHeaderByNumber(header.Number)
+}
+
+func (c *MultiEthClient) BlockHeaderByNumber(number *big.Int) (*types.Header, error) {
+	if number == nil {
+		header, err := failover(c, func(client EthClient) (*types.Header, error) {
+			return client.BlockHeaderByNumber(nil)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return c.confirmHeader(header)
+	}
+	return quorumCall(c, func(client EthClient) (*types.Header, error) {
+		return client.BlockHeaderByNumber(number)
+	}, headerKey)
+}
+
+func (c *MultiEthClient) LatestSafeBlockHeader() (*types.Header, error) {
+	header, err := failover(c, EthClient.LatestSafeBlockHeader)
+	if err != nil {
+		return nil, err
+	}
+	return c.confirmHeader(header)
+}
+
+func (c *MultiEthClient) LatestFinalizedBlockHeader() (*types.Header, error) {
+	header, err := failover(c, EthClient.LatestFinalizedBlockHeader)
+	if err != nil {
+		return nil, err
+	}
+	return c.confirmHeader(header)
+}
+
+func (c *MultiEthClient) BlockHeaderByHash(hash common.Hash) (*types.Header, error) {
+	return quorumCall(c, func(client EthClient) (*types.Header, error) {
+		return client.BlockHeaderByHash(hash)
+	}, headerKey)
+}
+
+// BlockHeadersByRange returns the longest run of headers from the start height that
+// the quorum agrees on, the endpoints may be at different heights
+func (c *MultiEthClient) BlockHeadersByRange(startHeight, endHeight *big.Int, chainId uint) ([]types.Header, error) {
+	call := func(client EthClient) ([]types.Header, error) {
+		return client.BlockHeadersByRange(startHeight, endHeight, chainId)
+	}
+	if c.quorum <= 1 {
+		return failover(c, call)
+	}
+
+	results := callAll(c, call)
+	var (
+		headers []types.Header
+		lastErr error
+	)
+	for i := 0; ; i++ {
+		votes := make(map[common.Hash]int)
+		var agreed *types.Header
+		for _, r := range results {
+			if r.err != nil {
+				lastErr = r.err
+				continue
+			}
+			if i >= len(r.res) {
+				continue
+			}
+			k := r.res[i].Hash()
+			votes[k]++
+			if votes[k] >= c.quorum {
+				agreed = &r.res[i]
+				break
+			}
+		}
+		if agreed == nil {
+			if len(headers) == 0 {
+				return nil, noQuorumError(len(votes), len(results), c.quorum, lastErr)
+			}
+			return headers, nil
+		}
+		headers = append(headers, *agreed)
+	}
+}
+
+func (c *MultiEthClient) BlockNumber() (uint64, error) {
+	return failover(c, EthClient.BlockNumber)
+}
+
+func (c *MultiEthClient) TxByHash(hash common.Hash) (*types.Transaction, error) {
+	return quorumCall(c, func(client EthClient) (*types.Transaction, error) {
+		return client.TxByHash(hash)
+	}, func(tx *types.Transaction) common.Hash {
+		if tx == nil {
+			return common.Hash{}
+		}
+		return tx.Hash()
+	})
+}
+
+func (c *MultiEthClient) StorageHash(address common.Address, blockNumber *big.Int) (common.Hash, error) {
+	return quorumCall(c, func(client EthClient) (common.Hash, error) {
+		return client.StorageHash(address, blockNumber)
+	}, func(hash common.Hash) common.Hash {
+		return hash
+	})
+}
+
+func (c *MultiEthClient) FilterLogs(query ethereum.FilterQuery) (Logs, error) {
+	return quorumCall(c, func(client EthClient) (Logs, error) {
+		return client.FilterLogs(query)
+	}, logsKey)
+}
+
+// logsKey digests the logs and the header they were queried up to
+func logsKey(logs Logs) common.Hash {
+	var data []byte
+	if logs.ToBlockHeader != nil {
+		data = append(data, logs.ToBlockHeader.Hash().Bytes()...)
+	}
+	for _, l := range logs.Logs {
+		data = append(data, l.Address.Bytes()...)
+		for _, topic := range l.Topics {
+			data = append(data, topic.Bytes()...)
+		}
+		data = append(data, crypto.Keccak256(l.Data)...)
+		data = append(data, l.BlockHash.Bytes()...)
+		data = append(data, l.TxHash.Bytes()...)
+		data = append(data, new(big.Int).SetUint64(uint64(l.Index)).Bytes()...)
+		if l.Removed {
+			data = append(data, 1)
+		}
+	}
+	return crypto.Keccak256Hash(data)
+}
+
+func (c *MultiEthClient) Close() {
+	select {
+	case <-c.quit:
+		return
+	default:
+		close(c.quit)
+	}
+	c.wg.Wait()
+	for _, ep := range c.snapshot() {
+		if ep.client != nil {
+			ep.client.Close()
+		}
+	}
+}
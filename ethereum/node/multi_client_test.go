@@ -0,0 +1,153 @@
+package node_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Manta-Network/manta-fp/ethereum/node"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// mockEthClient serves the headers of a chain and the logs, or fails every call with err
+type mockEthClient struct {
+	headers []types.Header
+	logs    []types.Log
+	err     error
+}
+
+func (c *mockEthClient) BlockHeaderByNumber(number *big.Int) (*types.Header, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if number == nil {
+		return &c.headers[len(c.headers)-1], nil
+	}
+	if number.Uint64() >= uint64(len(c.headers)) {
+		return nil, ethereum.NotFound
+	}
+	return &c.headers[number.Uint64()], nil
+}
+
+func (c *mockEthClient) LatestSafeBlockHeader() (*types.Header, error) {
+	return c.BlockHeaderByNumber(nil)
+}
+
+func (c *mockEthClient) LatestFinalizedBlockHeader() (*types.Header, error) {
+	return c.BlockHeaderByNumber(nil)
+}
+
+func (c *mockEthClient) BlockHeaderByHash(hash common.Hash) (*types.Header, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	for i := range c.headers {
+		if c.headers[i].Hash() == hash {
+			return &c.headers[i], nil
+		}
+	}
+	return nil, ethereum.NotFound
+}
+
+func (c *mockEthClient) BlockHeadersByRange(start, end *big.Int, _ uint) ([]types.Header, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	to := end.Uint64() + 1
+	if to > uint64(len(c.headers)) {
+		to = uint64(len(c.headers))
+	}
+	return c.headers[start.Uint64():to], nil
+}
+
+func (c *mockEthClient) BlockNumber() (uint64, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	return uint64(len(c.headers) - 1), nil
+}
+
+func (c *mockEthClient) TxByHash(common.Hash) (*types.Transaction, error) {
+	return nil, ethereum.NotFound
+}
+
+func (c *mockEthClient) StorageHash(common.Address, *big.Int) (common.Hash, error) {
+	return common.Hash{}, c.err
+}
+
+func (c *mockEthClient) FilterLogs(query ethereum.FilterQuery) (node.Logs, error) {
+	if c.err != nil {
+		return node.Logs{}, c.err
+	}
+	header, err := c.BlockHeaderByNumber(query.ToBlock)
+	if err != nil {
+		return node.Logs{}, err
+	}
+	return node.Logs{Logs: c.logs, ToBlockHeader: header}, nil
+}
+
+func (c *mockEthClient) Close() {}
+
+func TestMultiEthClientFailover(t *testing.T) {
+	chain := makeChain(common.Hash{}, 0, 5, 0)
+	client, err := node.NewMultiEthClient([]node.EthClient{
+		&mockEthClient{err: errors.New("connection refused")},
+		&mockEthClient{headers: chain},
+	}, 0, zap.NewNop())
+	require.NoError(t, err)
+
+	header, err := client.BlockHeaderByNumber(big.NewInt(3))
+	require.NoError(t, err)
+	require.Equal(t, chain[3].Hash(), header.Hash())
+	number, err := client.BlockNumber()
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), number)
+
+	_, err = node.NewMultiEthClient([]node.EthClient{&mockEthClient{}}, 2, zap.NewNop())
+	require.Error(t, err)
+}
+
+func TestMultiEthClientQuorum(t *testing.T) {
+	chain := makeChain(common.Hash{}, 0, 5, 0)
+	// the chain of the compromised endpoint is forked from height 3
+	fork := append(append([]types.Header{}, chain[:3]...), makeChain(chain[2].Hash(), 3, 5, 1)...)
+	logs := []types.Log{{Address: common.HexToAddress("0x10"), BlockNumber: 4, Data: []byte{1}}}
+	fakeLogs := []types.Log{{Address: common.HexToAddress("0x10"), BlockNumber: 4, Data: []byte{2}}}
+
+	client, err := node.NewMultiEthClient([]node.EthClient{
+		&mockEthClient{headers: fork, logs: fakeLogs},
+		&mockEthClient{headers: chain, logs: logs},
+		&mockEthClient{headers: chain[:5], logs: logs},
+	}, 2, zap.NewNop())
+	require.NoError(t, err)
+
+	header, err := client.BlockHeaderByNumber(big.NewInt(4))
+	require.NoError(t, err)
+	require.Equal(t, chain[4].Hash(), header.Hash())
+
+	// the head of the compromised endpoint is not confirmed by the quorum
+	_, err = client.BlockHeaderByNumber(nil)
+	require.ErrorIs(t, err, node.ErrNoQuorum)
+
+	// only the headers the quorum agrees on are returned
+	headers, err := client.BlockHeadersByRange(big.NewInt(1), big.NewInt(5), 0)
+	require.NoError(t, err)
+	require.Len(t, headers, 4)
+	require.Equal(t, chain[4].Hash(), headers[3].Hash())
+
+	filtered, err := client.FilterLogs(ethereum.FilterQuery{ToBlock: big.NewInt(4)})
+	require.NoError(t, err)
+	require.Equal(t, logs, filtered.Logs)
+
+	// a height only one endpoint has doesn't reach the quorum
+	_, err = client.FilterLogs(ethereum.FilterQuery{ToBlock: big.NewInt(5)})
+	require.ErrorIs(t, err, node.ErrNoQuorum)
+	_, err = client.BlockHeaderByNumber(big.NewInt(6))
+	require.ErrorIs(t, err, ethereum.NotFound)
+}
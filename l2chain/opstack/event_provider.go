@@ -2,21 +2,33 @@ package opstack
 
 import (
 	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
 	"go.uber.org/zap"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	types2 "github.com/ethereum/go-ethereum/core/types"
 
 	"github.com/Manta-Network/manta-fp/types"
 
+	dgfbindings "github.com/ethereum-optimism/optimism/op-node/bindings"
 	"github.com/ethereum-optimism/optimism/op-proposer/bindings"
 )
 
+// disputeGameABI is the part of the IDisputeGame interface read from the game proxies
+const disputeGameABI = `[{"inputs":[],"name":"l2BlockNumber","outputs":[{"internalType":"uint256","name":"l2BlockNumber_","type":"uint256"}],"stateMutability":"pure","type":"function"}]`
+
 type EventProvider struct {
 	Log          *zap.Logger
 	L2ooFilterer *bindings.L2OutputOracleFilterer
 	L2ooABI      *abi.ABI
+	DgfFilterer  *dgfbindings.DisputeGameFactoryFilterer
+	DgfABI       *abi.ABI
+	GameABI      *abi.ABI
 	EpCtx        context.Context
 }
 
@@ -34,14 +46,49 @@ func NewEventProvider(ctx context.Context, logger *zap.Logger) (*EventProvider,
 		return nil, err
 	}
 
+	disputeGameFactoryAbi, err := dgfbindings.DisputeGameFactoryMetaData.GetAbi()
+	if err != nil {
+		logger.Error("get dispute game factory abi fail", zap.String("err", err.Error()))
+		return nil, err
+	}
+
+	disputeGameFactoryUnpack, err := dgfbindings.NewDisputeGameFactoryFilterer(common.Address{}, nil)
+	if err != nil {
+		logger.Error("new dispute game factory fail", zap.String("err", err.Error()))
+		return nil, err
+	}
+
+	gameAbi, err := abi.JSON(strings.NewReader(disputeGameABI))
+	if err != nil {
+		return nil, err
+	}
+
 	return &EventProvider{
 		Log:          logger,
 		L2ooFilterer: l2OutputOracleUnpack,
 		L2ooABI:      l2OutputOracleAbi,
+		DgfFilterer:  disputeGameFactoryUnpack,
+		DgfABI:       disputeGameFactoryAbi,
+		GameABI:      &gameAbi,
 		EpCtx:        ctx,
 	}, nil
 }
 
+// IsStateRootEvent tells whether the log is an OutputProposed event of the L2OutputOracle
+func (epd *EventProvider) IsStateRootEvent(log types2.Log) bool {
+	return len(log.Topics) > 0 && log.Topics[0] == epd.L2ooABI.Events["OutputProposed"].ID
+}
+
+// IsOutputsDeletedEvent tells whether the log is an OutputsDeleted event of the L2OutputOracle
+func (epd *EventProvider) IsOutputsDeletedEvent(log types2.Log) bool {
+	return len(log.Topics) > 0 && log.Topics[0] == epd.L2ooABI.Events["OutputsDeleted"].ID
+}
+
+// IsDisputeGameCreatedEvent tells whether the log is a DisputeGameCreated event of the DisputeGameFactory
+func (epd *EventProvider) IsDisputeGameCreatedEvent(log types2.Log) bool {
+	return len(log.Topics) > 0 && log.Topics[0] == epd.DgfABI.Events["DisputeGameCreated"].ID
+}
+
 func (epd *EventProvider) ProcessStateRootEvent(log types2.Log) (*types.StateRoot, error) {
 	outputProposed, err := epd.L2ooFilterer.ParseOutputProposed(log)
 	if err != nil {
@@ -59,3 +106,95 @@ func (epd *EventProvider) ProcessStateRootEvent(log types2.Log) (*types.StateRoo
 	}
 	return stateRoot, nil
 }
+
+// ProcessOutputsDeletedEvent returns the index range of the outputs deleted from the L2OutputOracle
+func (epd *EventProvider) ProcessOutputsDeletedEvent(log types2.Log) (*types.OutputsDeleted, error) {
+	outputsDeleted, err := epd.L2ooFilterer.ParseOutputsDeleted(log)
+	if err != nil {
+		epd.Log.Error("parse outputs deleted fail", zap.String("err", err.Error()))
+		return nil, err
+	}
+	epd.Log.Info("outputs deleted event parse success",
+		zap.String("PrevNextOutputIndex", outputsDeleted.PrevNextOutputIndex.String()),
+		zap.String("NewNextOutputIndex", outputsDeleted.NewNextOutputIndex.String()),
+	)
+	return &types.OutputsDeleted{
+		PrevNextOutputIndex: outputsDeleted.PrevNextOutputIndex,
+		NewNextOutputIndex:  outputsDeleted.NewNextOutputIndex,
+		L1BlockNumber:       outputsDeleted.Raw.BlockNumber,
+	}, nil
+}
+
+// ProcessDisputeGameCreatedEvent returns the root claim of the game created by the
+// DisputeGameFactory. The event carries neither the L2 block number nor the index of
+// the game, they are read from the game proxy and the factory at the L1 block of the log
+func (epd *EventProvider) ProcessDisputeGameCreatedEvent(caller bind.ContractCaller, log types2.Log) (*types.StateRoot, error) {
+	gameCreated, err := epd.DgfFilterer.ParseDisputeGameCreated(log)
+	if err != nil {
+		epd.Log.Error("parse dispute game created fail", zap.String("err", err.Error()))
+		return nil, err
+	}
+
+	opts := &bind.CallOpts{
+		BlockNumber: new(big.Int).SetUint64(log.BlockNumber),
+		Context:     epd.EpCtx,
+	}
+
+	game := bind.NewBoundContract(gameCreated.DisputeProxy, *epd.GameABI, caller, nil, nil)
+	var out []interface{}
+	if err := game.Call(opts, &out, "l2BlockNumber"); err != nil {
+		return nil, fmt.Errorf("failed to get the l2 block number of dispute game %s: %w", gameCreated.DisputeProxy.String(), err)
+	}
+	l2BlockNumber := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	gameIndex, err := epd.findGameIndex(caller, log.Address, gameCreated.DisputeProxy, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	epd.Log.Info("dispute game event parse success",
+		zap.String("RootClaim", common.Bytes2Hex(gameCreated.RootClaim[:])),
+		zap.String("DisputeProxy", gameCreated.DisputeProxy.String()),
+		zap.Uint32("GameType", gameCreated.GameType),
+	)
+	stateRoot := &types.StateRoot{
+		StateRoot:        gameCreated.RootClaim,
+		L2BlockNumber:    l2BlockNumber,
+		L2OutputIndex:    gameIndex,
+		L1BlockHash:      gameCreated.Raw.BlockHash,
+		L1BlockNumber:    gameCreated.Raw.BlockNumber,
+		DisputeGameType:  uint64(gameCreated.GameType),
+		DisputeGameProxy: gameCreated.DisputeProxy,
+	}
+	return stateRoot, nil
+}
+
+// findGameIndex takes the index of the game from the game count at the L1 block of the
+// event, the game is the last one unless several games are created in the block, only the
+// games sharing the creation timestamp of the last one are walked back in that case
+func (epd *EventProvider) findGameIndex(caller bind.ContractCaller, factoryAddr, proxy common.Address, opts *bind.CallOpts) (*big.Int, error) {
+	factory, err := dgfbindings.NewDisputeGameFactoryCaller(factoryAddr, caller)
+	if err != nil {
+		return nil, err
+	}
+	gameCount, err := factory.GameCount(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the dispute game count: %w", err)
+	}
+	var blockTimestamp uint64
+	for index := new(big.Int).Sub(gameCount, big.NewInt(1)); index.Sign() >= 0; index.Sub(index, big.NewInt(1)) {
+		game, err := factory.GameAtIndex(opts, index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the dispute game at index %s: %w", index.String(), err)
+		}
+		if game.Proxy == proxy {
+			return index, nil
+		}
+		if blockTimestamp == 0 {
+			blockTimestamp = game.Timestamp
+		} else if game.Timestamp != blockTimestamp {
+			break
+		}
+	}
+	return nil, fmt.Errorf("dispute game %s not found in the factory %s", proxy.String(), factoryAddr.String())
+}
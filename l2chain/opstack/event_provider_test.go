@@ -0,0 +1,124 @@
+package opstack_test
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ctypes "github.com/ethereum/go-ethereum/core/types"
+	"go.uber.org/zap"
+
+	"github.com/Manta-Network/manta-fp/l2chain/opstack"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testFactoryAddr = common.HexToAddress("0xfac7")
+	testRootClaim   = common.HexToHash("0x04")
+)
+
+// gameCaller answers the calls made to the dispute game factory and the game proxies
+type gameCaller struct {
+	ep             *opstack.EventProvider
+	games          []common.Address
+	timestamps     []uint64
+	l2BlockNumbers map[common.Address]*big.Int
+	gameAtIndex    int
+}
+
+func (c *gameCaller) CodeAt(_ context.Context, _ common.Address, _ *big.Int) ([]byte, error) {
+	return []byte{0x1}, nil
+}
+
+func (c *gameCaller) CallContract(_ context.Context, call ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	if *call.To != testFactoryAddr {
+		method := c.ep.GameABI.Methods["l2BlockNumber"]
+		return method.Outputs.Pack(c.l2BlockNumbers[*call.To])
+	}
+
+	method, err := c.ep.DgfABI.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	switch method.Name {
+	case "gameCount":
+		return method.Outputs.Pack(big.NewInt(int64(len(c.games))))
+	case "gameAtIndex":
+		args, err := method.Inputs.Unpack(call.Data[4:])
+		if err != nil {
+			return nil, err
+		}
+		index := args[0].(*big.Int).Int64()
+		c.gameAtIndex++
+		return method.Outputs.Pack(uint32(1), c.timestamps[index], c.games[index])
+	default:
+		return nil, fmt.Errorf("unexpected method %s", method.Name)
+	}
+}
+
+func disputeGameCreatedLog(ep *opstack.EventProvider, proxy common.Address, gameType uint32) ctypes.Log {
+	return ctypes.Log{
+		Address: testFactoryAddr,
+		Topics: []common.Hash{
+			ep.DgfABI.Events["DisputeGameCreated"].ID,
+			common.BytesToHash(proxy.Bytes()),
+			common.BigToHash(big.NewInt(int64(gameType))),
+			testRootClaim,
+		},
+		BlockNumber: 100,
+		BlockHash:   testBlockHash,
+	}
+}
+
+func TestProcessDisputeGameCreatedEvent(t *testing.T) {
+	t.Parallel()
+
+	ep, err := opstack.NewEventProvider(context.Background(), zap.NewNop())
+	require.NoError(t, err)
+
+	games := []common.Address{
+		common.HexToAddress("0x10"),
+		common.HexToAddress("0x11"),
+		common.HexToAddress("0x12"),
+		common.HexToAddress("0x13"),
+	}
+	caller := &gameCaller{
+		ep:    ep,
+		games: games,
+		// the first game is created in an earlier block
+		timestamps: []uint64{900, 1000, 1000, 1000},
+		l2BlockNumbers: map[common.Address]*big.Int{
+			games[2]: big.NewInt(3600),
+		},
+	}
+
+	// the game isn't the last one created in the block
+	log := disputeGameCreatedLog(ep, games[2], 1)
+	require.True(t, ep.IsDisputeGameCreatedEvent(log))
+	require.False(t, ep.IsStateRootEvent(log))
+
+	stateRoot, err := ep.ProcessDisputeGameCreatedEvent(caller, log)
+	require.NoError(t, err)
+	require.True(t, stateRoot.IsDisputeGame())
+	require.Equal(t, [32]byte(testRootClaim), stateRoot.StateRoot)
+	require.Equal(t, big.NewInt(3600), stateRoot.L2BlockNumber)
+	require.Equal(t, big.NewInt(2), stateRoot.L2OutputIndex)
+	require.Equal(t, uint64(1), stateRoot.DisputeGameType)
+	require.Equal(t, games[2], stateRoot.DisputeGameProxy)
+	require.Equal(t, uint64(100), stateRoot.L1BlockNumber)
+	require.Equal(t, testBlockHash, stateRoot.L1BlockHash)
+
+	require.Equal(t, 2, caller.gameAtIndex)
+
+	// a game unknown to the factory is rejected, the games of the earlier blocks aren't walked
+	caller.gameAtIndex = 0
+	unknownGame := common.HexToAddress("0x14")
+	caller.l2BlockNumbers[unknownGame] = big.NewInt(7200)
+	_, err = ep.ProcessDisputeGameCreatedEvent(caller, disputeGameCreatedLog(ep, unknownGame, 1))
+	require.Error(t, err)
+	require.Equal(t, 4, caller.gameAtIndex)
+}
@@ -0,0 +1,606 @@
+package opstack
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ctypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Manta-Network/manta-fp/ethereum/node"
+	"github.com/Manta-Network/manta-fp/types"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+var (
+	errLogsBlockHashMismatch = errors.New("mismatch in FitlerLog#ToBlock block hash")
+)
+
+// minIndexerStallTimeout is the min time without progress before the indexer is reported stuck
+const minIndexerStallTimeout = 5 * time.Minute
+
+// IndexerConfig is the configuration of the indexer, it's filled from the op event
+// configuration of the daemons
+type IndexerConfig struct {
+	EthRpcs            []string
+	EthRpcQuorum       uint
+	ChainId            uint
+	StartHeight        uint64
+	BlockStep          uint64
+	PollInterval       time.Duration
+	HeadMode           string
+	ConfirmationDepth  uint64
+	L2OutputOracleAddr common.Address
+
+	// the dispute game factory is only polled when the dispute games are indexed
+	IndexDisputeGames      bool
+	DisputeGameFactoryAddr common.Address
+}
+
+// LogHandler handles the logs of a contract added to the indexer, the batch is
+// processed again if it returns an error
+type LogHandler func(log ctypes.Log) error
+
+// Indexer traverses the L1 chain, indexes the outputs of the L2OutputOracle, the
+// dispute games and the deleted outputs into the store and rolls them back on reorgs.
+// The indexed events are appended to a journal which the subscribers consume with
+// their own cursor, so that one indexer feeds several consumers, the events consumed by
+// every persisted cursor are pruned once they are out of the reorg window
+type Indexer struct {
+	isStarted *atomic.Bool
+	wg        sync.WaitGroup
+	logger    *zap.Logger
+	cfg       *IndexerConfig
+	sRStore   *OpStateRootStore
+
+	opClient       node.EthClient
+	contracts      []common.Address
+	logHandlers    map[common.Address]LogHandler
+	headers        []ctypes.Header
+	blockTraversal *node.BlockTraversal
+	eventProvider  *EventProvider
+
+	disputeGameCaller      bind.ContractCaller
+	closeDisputeGameCaller func()
+
+	mu            sync.Mutex
+	subscriptions map[string]*Subscription
+
+	// lastIndexedAt and lag are the progress reported by the health check
+	lastIndexedAt *atomic.Time
+	lag           *atomic.Uint64
+	// criticalErr stops the indexing and fails the health check, it's set when the
+	// indexed blocks can't be rolled back to the canonical chain
+	criticalErr *atomic.Value
+
+	metrics Metricer
+	quit    chan struct{}
+}
+
+func NewIndexer(logger *zap.Logger, cfg *IndexerConfig, opClient node.EthClient, sRStore *OpStateRootStore, eventProvider *EventProvider, metrics Metricer) (*Indexer, error) {
+	if metrics == nil {
+		metrics = noopMetricer{}
+	}
+	contracts := []common.Address{cfg.L2OutputOracleAddr}
+
+	var (
+		disputeGameCaller      bind.ContractCaller
+		closeDisputeGameCaller = func() {}
+	)
+	if cfg.IndexDisputeGames {
+		contracts = append(contracts, cfg.DisputeGameFactoryAddr)
+		// the games are read at the L1 block of their event, which the indexer client can't call
+		caller, err := node.DialMultiContractCaller(context.Background(), cfg.EthRpcs, cfg.EthRpcQuorum, logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial eth client for the dispute games: %w", err)
+		}
+		disputeGameCaller = caller
+		closeDisputeGameCaller = caller.Close
+		logger.Info("indexing the dispute games", zap.String("dispute_game_factory", cfg.DisputeGameFactoryAddr.String()))
+	}
+
+	return &Indexer{
+		isStarted:              atomic.NewBool(false),
+		lastIndexedAt:          atomic.NewTime(time.Time{}),
+		lag:                    atomic.NewUint64(0),
+		criticalErr:            &atomic.Value{},
+		logger:                 logger,
+		cfg:                    cfg,
+		sRStore:                sRStore,
+		opClient:               opClient,
+		contracts:              contracts,
+		logHandlers:            make(map[common.Address]LogHandler),
+		eventProvider:          eventProvider,
+		disputeGameCaller:      disputeGameCaller,
+		closeDisputeGameCaller: closeDisputeGameCaller,
+		subscriptions:          make(map[string]*Subscription),
+		metrics:                metrics,
+		quit:                   make(chan struct{}),
+	}, nil
+}
+
+// AddLogHandler polls the logs of the contract along with the outputs and passes them
+// to the handler, it must be called before the indexer is started
+func (ix *Indexer) AddLogHandler(contract common.Address, handler LogHandler) {
+	if _, ok := ix.logHandlers[contract]; !ok {
+		ix.contracts = append(ix.contracts, contract)
+	}
+	ix.logHandlers[contract] = handler
+}
+
+// Store returns the store the indexer persists to
+func (ix *Indexer) Store() *OpStateRootStore {
+	return ix.sRStore
+}
+
+// Start resumes the indexing from the last indexed block, the indexing starts from the
+// given height, or from the configured start height if zero, when nothing is indexed yet
+func (ix *Indexer) Start(startHeight uint64) error {
+	if ix.isStarted.Swap(true) {
+		return fmt.Errorf("the indexer is already started")
+	}
+
+	ix.logger.Info("starting the indexer")
+
+	fromBlock, err := ix.fromBlock(startHeight)
+	if err != nil {
+		ix.isStarted.Store(false)
+		return err
+	}
+	headMode, err := node.ParseHeadMode(ix.cfg.HeadMode)
+	if err != nil {
+		ix.isStarted.Store(false)
+		return err
+	}
+	ix.blockTraversal = node.NewBlockTraversal(ix.opClient, fromBlock, headMode, new(big.Int).SetUint64(ix.cfg.ConfirmationDepth), ix.cfg.ChainId, ix.logger)
+	if err := ix.seedHeaders(fromBlock); err != nil {
+		ix.isStarted.Store(false)
+		return err
+	}
+
+	// the stall is measured from the start until the first batch is indexed
+	ix.lastIndexedAt.Store(time.Now())
+	ix.wg.Add(1)
+	go ix.pollChain()
+
+	if fromBlock != nil {
+		ix.metrics.RecordPollerStartingHeight(fromBlock.Uint64())
+	}
+	ix.metrics.RecordPollerConfirmationDepth(ix.cfg.ConfirmationDepth)
+	ix.logger.Info("the indexer is successfully started")
+
+	return nil
+}
+
+func (ix *Indexer) fromBlock(startHeight uint64) (*big.Int, error) {
+	dbLatestBlock, err := ix.sRStore.GetLatestBlock()
+	if err != nil {
+		return nil, err
+	}
+	if dbLatestBlock != nil {
+		ix.logger.Info("sync detected last indexed block", zap.String("blockNumber", dbLatestBlock.String()))
+		return dbLatestBlock, nil
+	}
+
+	if startHeight == 0 {
+		startHeight = ix.cfg.StartHeight
+	}
+	if startHeight == 0 {
+		ix.logger.Info("no ethereum block indexed state")
+		return nil, nil
+	}
+	ix.logger.Info("no sync indexed state starting from supplied ethereum height", zap.Uint64("height", startHeight))
+	header, err := ix.opClient.BlockHeaderByNumber(new(big.Int).SetUint64(startHeight))
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch starting block header: %w", err)
+	}
+	return header.Number, nil
+}
+
+// seedHeaders tracks the headers traversed before the restart up to the block the
+// indexing resumes from, so that a reorg across the restart is rolled back
+func (ix *Indexer) seedHeaders(fromBlock *big.Int) error {
+	if fromBlock == nil {
+		return nil
+	}
+	headers, err := ix.sRStore.GetTraversedHeaders()
+	if err != nil {
+		return fmt.Errorf("failed to get the traversed headers: %w", err)
+	}
+	seed := make([]node.TrackedHeader, 0, len(headers))
+	for _, header := range headers {
+		if header.Number.Cmp(fromBlock) <= 0 {
+			seed = append(seed, header)
+		}
+	}
+	ix.blockTraversal.SeedHeaders(seed)
+	return nil
+}
+
+func (ix *Indexer) Stop() error {
+	if !ix.isStarted.Swap(false) {
+		return fmt.Errorf("the indexer has already stopped")
+	}
+
+	ix.logger.Info("stopping the indexer")
+
+	close(ix.quit)
+	ix.wg.Wait()
+	ix.closeDisputeGameCaller()
+
+	ix.logger.Info("the indexer is successfully stopped")
+
+	return nil
+}
+
+func (ix *Indexer) IsRunning() bool {
+	return ix.isStarted.Load()
+}
+
+// Subscribe returns a subscription delivering the indexed events to the named consumer.
+// The events above fromL1Height are delivered if it's not zero, otherwise the delivery
+// resumes from the persisted cursor of the consumer, or from the start of the journal
+func (ix *Indexer) Subscribe(name string, fromL1Height uint64, bufferSize uint32) (*Subscription, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if _, ok := ix.subscriptions[name]; ok {
+		return nil, fmt.Errorf("the subscriber %s is already subscribed", name)
+	}
+
+	var cursor uint64
+	if fromL1Height > 0 {
+		seq, err := ix.sRStore.FirstEventSeqAfter(fromL1Height)
+		if err != nil {
+			return nil, err
+		}
+		cursor = seq
+	} else {
+		seq, _, err := ix.sRStore.GetCursor(name)
+		if err != nil {
+			return nil, err
+		}
+		cursor = seq
+	}
+	if err := ix.sRStore.SaveCursor(name, cursor); err != nil {
+		return nil, err
+	}
+
+	sub := newSubscription(name, cursor, bufferSize, ix.cfg.PollInterval, ix.sRStore, ix.logger, func() {
+		ix.mu.Lock()
+		defer ix.mu.Unlock()
+		delete(ix.subscriptions, name)
+	})
+	ix.subscriptions[name] = sub
+	sub.start()
+
+	ix.logger.Info("subscribed to the indexer", zap.String("subscriber", name), zap.Uint64("cursor", cursor))
+	return sub, nil
+}
+
+// notifySubscriptions wakes up the subscriptions once new events are journaled
+func (ix *Indexer) notifySubscriptions() {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for _, sub := range ix.subscriptions {
+		sub.notify()
+	}
+}
+
+// rewindSubscriptions moves the subscriptions past the end of the journal back to it
+func (ix *Indexer) rewindSubscriptions() error {
+	next, err := ix.sRStore.NextEventSeq()
+	if err != nil {
+		return err
+	}
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	for _, sub := range ix.subscriptions {
+		sub.rewind(next)
+	}
+	return nil
+}
+
+func (ix *Indexer) pollChain() {
+	defer ix.wg.Done()
+	for {
+		select {
+		case <-time.After(ix.cfg.PollInterval):
+			if len(ix.headers) > 0 {
+				ix.logger.Info("retrying previous batch")
+			} else {
+				newHeaders, err := ix.blockTraversal.NextHeaders(ix.cfg.BlockStep)
+				if errors.Is(err, node.ErrBlockTraversalReorg) {
+					err := ix.rollbackReorg()
+					if errors.Is(err, node.ErrReorgTooDeep) {
+						// the fork point is below the tracked headers, the indexed outputs can't be
+						// trusted anymore and the indexer stops until the store is reset
+						ix.criticalErr.Store(err)
+						ix.logger.Error("critical: the reorg is too deep to be rolled back, the indexer is stopped",
+							zap.String("last_traversed_block", ix.blockTraversal.LastTraversedHeader().String()),
+							zap.String("err", err.Error()),
+						)
+						return
+					} else if err != nil {
+						ix.logger.Error("failed to roll back the reorg", zap.String("err", err.Error()))
+					}
+					continue
+				} else if err != nil {
+					ix.logger.Error("error querying for headers", zap.String("err", err.Error()))
+					continue
+				} else if len(newHeaders) == 0 {
+					ix.logger.Warn("no new headers. syncer at head?")
+				} else {
+					ix.headers = newHeaders
+				}
+				latestBlock := ix.blockTraversal.LatestBlock()
+				if latestBlock != nil {
+					ix.logger.Info("Latest header", zap.String("latestHeader Number", latestBlock.String()), zap.String("headMode", string(ix.blockTraversal.HeadMode())))
+					ix.metrics.RecordPollerHeadHeight(string(ix.blockTraversal.HeadMode()), latestBlock.Uint64())
+				}
+			}
+			err := ix.processBatch(ix.headers)
+			if err == nil {
+				// the latest block is the last indexed one, the indexer resumes from it after restart
+				// and detects the reorgs of the traversed headers
+				if len(ix.headers) > 0 {
+					err = ix.sRStore.AddTraversedHeaders(ix.headers, node.DefaultHeaderTrackerSize)
+					if err != nil {
+						// the batch is kept and processed again in the next poll, the indexed events
+						// are skipped as duplicates and the health check reports the stall meanwhile
+						ix.logger.Error("Add latest block fail, retrying the batch", zap.String("err", err.Error()))
+						continue
+					}
+					if pruned, err := ix.sRStore.PruneEvents(); err != nil {
+						ix.logger.Error("failed to prune the journal events", zap.String("err", err.Error()))
+					} else if pruned > 0 {
+						ix.logger.Debug("pruned the consumed journal events", zap.Int("size", pruned))
+					}
+				}
+				ix.headers = nil
+				ix.lastIndexedAt.Store(time.Now())
+				ix.recordLag()
+			} else if errors.Is(err, errLogsBlockHashMismatch) {
+				// the batch is reorged after being traversed, it is traversed again so that
+				// the reorg is detected against the previous batch
+				ix.blockTraversal.ChangeLastTraversedHeaderByDelAfter(new(big.Int).Sub(ix.headers[0].Number, big.NewInt(1)))
+				ix.headers = nil
+			}
+			ix.notifySubscriptions()
+		case <-ix.quit:
+			return
+		}
+	}
+}
+
+// recordLag records how many blocks the indexed blocks are behind the head of the head mode
+func (ix *Indexer) recordLag() {
+	latestBlock := ix.blockTraversal.LatestBlock()
+	lastTraversed := ix.blockTraversal.LastTraversedHeader()
+	if latestBlock == nil || lastTraversed == nil || latestBlock.Cmp(lastTraversed) < 0 {
+		return
+	}
+	lag := new(big.Int).Sub(latestBlock, lastTraversed).Uint64()
+	ix.lag.Store(lag)
+	ix.metrics.RecordPollerLag(lag)
+}
+
+// stallTimeout is how long the indexer may make no progress before it's reported stuck
+func (ix *Indexer) stallTimeout() time.Duration {
+	timeout := 30 * ix.cfg.PollInterval
+	if timeout < minIndexerStallTimeout {
+		timeout = minIndexerStallTimeout
+	}
+	return timeout
+}
+
+// CheckHealth fails when the indexer is not running or has not indexed a batch of
+// blocks for a while, the detail reports the lag behind the L1 head
+func (ix *Indexer) CheckHealth(_ context.Context) (string, error) {
+	if !ix.IsRunning() {
+		return "", errors.New("the indexer is not running")
+	}
+	if err, ok := ix.criticalErr.Load().(error); ok {
+		return "", fmt.Errorf("the indexer is stopped: %w", err)
+	}
+	since := time.Since(ix.lastIndexedAt.Load()).Truncate(time.Second)
+	detail := fmt.Sprintf("lag %d blocks, last indexed %s ago", ix.lag.Load(), since)
+	if since > ix.stallTimeout() {
+		return detail, fmt.Errorf("no block indexed for %s", since)
+	}
+	return detail, nil
+}
+
+// rollbackReorg rolls the traversal, the store and the subscriptions back to the fork
+// point, the canonical events after it are journaled again once traversed
+func (ix *Indexer) rollbackReorg() error {
+	forkPoint, err := ix.blockTraversal.RollbackReorg()
+	if err != nil {
+		return err
+	}
+	ix.headers = nil
+
+	deleted, err := ix.sRStore.DeleteStateRootsAfter(forkPoint)
+	if err != nil {
+		return fmt.Errorf("failed to delete the reorged state roots: %w", err)
+	}
+	for _, stateRoot := range deleted {
+		ix.logger.Warn("rolled back the reorged state root",
+			zap.String("stateroot", hex.EncodeToString(stateRoot.StateRoot[:])),
+			zap.String("l2_output_index", stateRoot.L2OutputIndex.String()),
+		)
+	}
+	if err := ix.rewindSubscriptions(); err != nil {
+		return err
+	}
+
+	return ix.sRStore.AddLatestBlock(forkPoint)
+}
+
+func (ix *Indexer) processBatch(headers []ctypes.Header) error {
+	if len(headers) == 0 {
+		return nil
+	}
+	firstHeader, lastHeader := headers[0], headers[len(headers)-1]
+	ix.logger.Info("extracting batch", zap.Int("size", len(headers)), zap.String("startBlock", firstHeader.Number.String()), zap.String("endBlock", lastHeader.Number.String()))
+	headerMap := make(map[common.Hash]*ctypes.Header, len(headers))
+	for i := range headers {
+		header := headers[i]
+		headerMap[header.Hash()] = &header
+	}
+
+	filterQuery := ethereum.FilterQuery{FromBlock: firstHeader.Number, ToBlock: lastHeader.Number, Addresses: ix.contracts}
+	logs, err := ix.opClient.FilterLogs(filterQuery)
+	if err != nil {
+		ix.logger.Error("failed to extract logs", zap.String("err", err.Error()))
+		return err
+	}
+
+	if logs.ToBlockHeader.Number.Cmp(lastHeader.Number) != 0 {
+		return fmt.Errorf("mismatch in FilterLog#ToBlock number")
+	} else if logs.ToBlockHeader.Hash() != lastHeader.Hash() {
+		return errLogsBlockHashMismatch
+	}
+
+	if len(logs.Logs) > 0 {
+		ix.logger.Info("detected logs", zap.Int("size", len(logs.Logs)))
+	}
+
+	// contracts parse, the other events of the contracts are skipped
+	for _, log := range logs.Logs {
+		if len(log.Topics) == 0 {
+			continue
+		}
+		if handler, ok := ix.logHandlers[log.Address]; ok {
+			if err := handler(log); err != nil {
+				return err
+			}
+			continue
+		}
+		if ix.cfg.IndexDisputeGames && log.Address == ix.cfg.DisputeGameFactoryAddr {
+			if err := ix.processDisputeGameEvent(log, headerMap[log.BlockHash]); err != nil {
+				return err
+			}
+			continue
+		}
+		switch {
+		case ix.eventProvider.IsStateRootEvent(log):
+			if err := ix.processStateRootEvent(log, headerMap[log.BlockHash]); err != nil {
+				return err
+			}
+		case ix.eventProvider.IsOutputsDeletedEvent(log):
+			if err := ix.processOutputsDeleted(log, headerMap[log.BlockHash]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// saveBlockHeader saves the header of an L1 block with indexed events for the queries,
+// the headers already saved when the batch is processed again are skipped
+func (ix *Indexer) saveBlockHeader(header *ctypes.Header) error {
+	if header == nil {
+		return nil
+	}
+	err := ix.sRStore.AddBlock(header.Number, header.Hash(), header.ParentHash, header.Time)
+	if err != nil && !errors.Is(err, ErrDuplicateBlock) {
+		ix.logger.Error("failed to store block header", zap.String("err", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// processStateRootEvent saves an output proposed to the L2OutputOracle, the outputs
+// already saved when the batch is processed again are skipped
+func (ix *Indexer) processStateRootEvent(log ctypes.Log, header *ctypes.Header) error {
+	stateRootEvent, err := ix.eventProvider.ProcessStateRootEvent(log)
+	if err != nil {
+		return err
+	}
+	if err := ix.saveBlockHeader(header); err != nil {
+		return err
+	}
+	ix.logger.Info("event list", zap.String("stateroot", hex.EncodeToString(stateRootEvent.StateRoot[:])))
+
+	err = ix.sRStore.SaveStateRoot(stateRootEvent)
+	if errors.Is(err, ErrDuplicateStateRoot) {
+		return nil
+	} else if err != nil {
+		ix.logger.Error("failed to store state root", zap.String("err", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// processOutputsDeleted marks the outputs deleted from the L2OutputOracle in the store, so
+// that they are never signed, and journals the deletion for the subscribers
+func (ix *Indexer) processOutputsDeleted(log ctypes.Log, header *ctypes.Header) error {
+	outputsDeleted, err := ix.eventProvider.ProcessOutputsDeletedEvent(log)
+	if err != nil {
+		return err
+	}
+	if err := ix.saveBlockHeader(header); err != nil {
+		return err
+	}
+	if err := ix.sRStore.MarkOutputsDeleted(outputsDeleted); err != nil {
+		ix.logger.Error("failed to mark the deleted outputs", zap.String("err", err.Error()))
+		return err
+	}
+	ix.logger.Warn("outputs deleted from the l2 output oracle",
+		zap.String("from_l2_output_index", outputsDeleted.NewNextOutputIndex.String()),
+		zap.String("to_l2_output_index", outputsDeleted.PrevNextOutputIndex.String()),
+		zap.Int("indexed_outputs", len(outputsDeleted.StateRoots)),
+		zap.Uint64("l1_block_number", outputsDeleted.L1BlockNumber),
+	)
+	return nil
+}
+
+// processDisputeGameEvent saves the root claim of a created dispute game, it's delivered
+// to the subscribers like an output of the L2OutputOracle
+func (ix *Indexer) processDisputeGameEvent(log ctypes.Log, header *ctypes.Header) error {
+	if !ix.eventProvider.IsDisputeGameCreatedEvent(log) {
+		return nil
+	}
+	stateRootEvent, err := ix.eventProvider.ProcessDisputeGameCreatedEvent(ix.disputeGameCaller, log)
+	if err != nil {
+		return err
+	}
+	if err := ix.saveBlockHeader(header); err != nil {
+		return err
+	}
+	ix.logger.Info("dispute game created",
+		zap.String("stateroot", hex.EncodeToString(stateRootEvent.StateRoot[:])),
+		zap.String("game_index", stateRootEvent.L2OutputIndex.String()),
+		zap.Uint64("game_type", stateRootEvent.DisputeGameType),
+	)
+
+	err = ix.sRStore.SaveDisputeGame(stateRootEvent)
+	if errors.Is(err, ErrDuplicateDisputeGame) {
+		return nil
+	} else if err != nil {
+		ix.logger.Error("failed to store dispute game", zap.String("err", err.Error()))
+		return err
+	}
+	return nil
+}
+
+// blockInfo returns the output of the journal event as delivered to the subscribers
+func blockInfo(event *IndexedEvent) *types.BlockInfo {
+	return &types.BlockInfo{
+		Height:    event.L1BlockNumber,
+		Hash:      event.StateRoot.L1BlockHash.Bytes(),
+		Finalized: false,
+		StateRoot: *event.StateRoot,
+		Seq:       event.Seq,
+	}
+}
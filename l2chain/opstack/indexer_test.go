@@ -0,0 +1,97 @@
+package opstack_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Manta-Network/manta-fp/l2chain/opstack"
+	"github.com/Manta-Network/manta-fp/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func receiveBlockInfo(t *testing.T, sub *opstack.Subscription) *types.BlockInfo {
+	select {
+	case b := <-sub.GetBlockInfoChan():
+		return b
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the indexed output")
+		return nil
+	}
+}
+
+func TestSubscriptionCursor(t *testing.T) {
+	t.Parallel()
+
+	ss := newTestStore(t)
+	for i := int64(0); i < 3; i++ {
+		err := ss.SaveStateRoot(testOutput(10+i, i, byte(i)))
+		require.NoError(t, err)
+	}
+
+	indexer, err := opstack.NewIndexer(zap.NewNop(), &opstack.IndexerConfig{PollInterval: 10 * time.Millisecond}, nil, ss, nil, nil)
+	require.NoError(t, err)
+
+	// a new subscriber consumes the journal from the start
+	sub, err := indexer.Subscribe("first", 0, 10)
+	require.NoError(t, err)
+	var blocks []*types.BlockInfo
+	for i := uint64(0); i < 3; i++ {
+		b := receiveBlockInfo(t, sub)
+		require.Equal(t, 10+i, b.Height)
+		require.Equal(t, big.NewInt(int64(i)), b.L2OutputIndex)
+		require.Equal(t, i, b.Seq)
+		blocks = append(blocks, b)
+	}
+
+	// the cursor is only persisted past the acknowledged events
+	sub.Ack(blocks[0].Seq)
+	sub.Ack(blocks[2].Seq)
+	cursor, _, err := ss.GetCursor("first")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), cursor)
+	_, err = indexer.Subscribe("first", 0, 10)
+	require.Error(t, err)
+
+	// a subscriber with its own cursor starts above the given L1 height
+	other, err := indexer.Subscribe("second", 11, 10)
+	require.NoError(t, err)
+	require.Equal(t, uint64(12), receiveBlockInfo(t, other).Height)
+	other.Close()
+
+	err = ss.MarkOutputsDeleted(&types.OutputsDeleted{
+		PrevNextOutputIndex: big.NewInt(3),
+		NewNextOutputIndex:  big.NewInt(2),
+		L1BlockNumber:       13,
+	})
+	require.NoError(t, err)
+	select {
+	case outputsDeleted := <-sub.GetOutputsDeletedChan():
+		require.Len(t, outputsDeleted.StateRoots, 1)
+		require.Equal(t, uint64(3), outputsDeleted.Seq)
+		sub.Ack(outputsDeleted.Seq)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the deleted outputs")
+	}
+	sub.Close()
+
+	// the subscriber resumes from the first event it didn't acknowledge
+	err = ss.SaveStateRoot(testOutput(14, 2, 9))
+	require.NoError(t, err)
+	sub, err = indexer.Subscribe("first", 0, 10)
+	require.NoError(t, err)
+	b := receiveBlockInfo(t, sub)
+	require.Equal(t, uint64(11), b.Height)
+	sub.Ack(b.Seq)
+	require.Equal(t, uint64(12), receiveBlockInfo(t, sub).Height)
+	sub.Close()
+
+	// the event delivered but not acknowledged is delivered again
+	sub, err = indexer.Subscribe("first", 0, 10)
+	require.NoError(t, err)
+	defer sub.Close()
+	require.Equal(t, uint64(12), receiveBlockInfo(t, sub).Height)
+}
@@ -0,0 +1,23 @@
+package opstack
+
+// Metricer records the progress of the indexer and the output verification, it's
+// implemented by the metrics of both daemons
+type Metricer interface {
+	RecordPollerStartingHeight(height uint64)
+	RecordPollerConfirmationDepth(depth uint64)
+	RecordPollerHeadHeight(headMode string, height uint64)
+	RecordPollerLag(lag uint64)
+	RecordOutputRootMismatch()
+}
+
+type noopMetricer struct{}
+
+func (noopMetricer) RecordPollerStartingHeight(uint64) {}
+
+func (noopMetricer) RecordPollerConfirmationDepth(uint64) {}
+
+func (noopMetricer) RecordPollerHeadHeight(string, uint64) {}
+
+func (noopMetricer) RecordPollerLag(uint64) {}
+
+func (noopMetricer) RecordOutputRootMismatch() {}
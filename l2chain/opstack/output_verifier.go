@@ -0,0 +1,216 @@
+package opstack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+
+	"github.com/Manta-Network/manta-fp/types"
+)
+
+const (
+	defaultOutputVerifierTimeout = 10 * time.Second
+
+	// outputMismatchAlertWindow is how long the health check fails after a mismatching
+	// output root is skipped
+	outputMismatchAlertWindow = time.Hour
+
+	// rpcMethodNotFound is the json-rpc error code of an unsupported method
+	rpcMethodNotFound = -32601
+)
+
+var (
+	// ErrOutputRootMismatch is returned when the proposed output root differs from
+	// the output root computed by the L2 node, such an output must never be signed
+	ErrOutputRootMismatch = errors.New("the proposed output root doesn't match the L2 node")
+
+	// L2ToL1MessagePasserAddr is the predeploy whose storage root is committed in the output root
+	L2ToL1MessagePasserAddr = common.HexToAddress("0x4200000000000000000000000000000000000016")
+
+	// OutputVersionV0 is the version of the output root computed from the L2 block
+	OutputVersionV0 = common.Hash{}
+)
+
+// OutputVerifier checks the proposed output roots against an L2 node. The output root
+// is queried with optimism_outputAtBlock when the node is an op-node, otherwise it's
+// recomputed from the L2 block and the storage root of the message passer
+type OutputVerifier struct {
+	client  *rpc.Client
+	timeout time.Duration
+	metrics Metricer
+	log     *zap.Logger
+
+	// recompute is set once the node doesn't support optimism_outputAtBlock
+	recompute atomic.Bool
+	// lastMismatch is the latest output root which didn't match the L2 node
+	lastMismatch atomic.Pointer[outputMismatch]
+}
+
+type outputMismatch struct {
+	at     time.Time
+	detail string
+}
+
+func NewOutputVerifier(ctx context.Context, l2Rpc string, metrics Metricer, log *zap.Logger) (*OutputVerifier, error) {
+	if metrics == nil {
+		metrics = noopMetricer{}
+	}
+	client, err := rpc.DialContext(ctx, l2Rpc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial l2 rpc: %w", err)
+	}
+	return &OutputVerifier{
+		client:  client,
+		timeout: defaultOutputVerifierTimeout,
+		metrics: metrics,
+		log:     log,
+	}, nil
+}
+
+func (v *OutputVerifier) Close() {
+	v.client.Close()
+}
+
+// Verify returns ErrOutputRootMismatch if the proposed output root differs from
+// the output root of the L2 block computed by the L2 node
+func (v *OutputVerifier) Verify(ctx context.Context, stateRoot *types.StateRoot) error {
+	if stateRoot.L2BlockNumber == nil {
+		return fmt.Errorf("the output %v has no l2 block number", stateRoot.L2OutputIndex)
+	}
+	outputRoot, err := v.OutputRootAtBlock(ctx, stateRoot.L2BlockNumber)
+	if err != nil {
+		return fmt.Errorf("failed to get the output root at l2 block %s: %w", stateRoot.L2BlockNumber.String(), err)
+	}
+	if outputRoot != common.Hash(stateRoot.StateRoot) {
+		v.metrics.RecordOutputRootMismatch()
+		v.lastMismatch.Store(&outputMismatch{
+			at: time.Now(),
+			detail: fmt.Sprintf("output index %v, l2 block %s, proposed %s, computed %s",
+				stateRoot.L2OutputIndex, stateRoot.L2BlockNumber.String(), common.Hash(stateRoot.StateRoot).Hex(), outputRoot.Hex()),
+		})
+		v.log.Error("critical: the proposed output root doesn't match the L2 node, refuse to sign it",
+			zap.Stringer("l2_output_index", stateRoot.L2OutputIndex),
+			zap.Stringer("l2_block_number", stateRoot.L2BlockNumber),
+			zap.String("proposed_output_root", common.Hash(stateRoot.StateRoot).Hex()),
+			zap.String("l2_output_root", outputRoot.Hex()),
+		)
+		return fmt.Errorf("%w: output index %v, l2 block %s, proposed %s, computed %s",
+			ErrOutputRootMismatch, stateRoot.L2OutputIndex, stateRoot.L2BlockNumber.String(),
+			common.Hash(stateRoot.StateRoot).Hex(), outputRoot.Hex())
+	}
+	return nil
+}
+
+// VerifyBlocks returns the blocks whose output root matches the L2 node, all the blocks
+// are returned by a nil verifier. The mismatching outputs are skipped so that the other
+// outputs are still signed, and fail the health check for a while. Any other error is
+// returned so that the blocks are verified again
+func (v *OutputVerifier) VerifyBlocks(ctx context.Context, blocks []*types.BlockInfo) ([]*types.BlockInfo, error) {
+	if v == nil {
+		return blocks, nil
+	}
+	verified := make([]*types.BlockInfo, 0, len(blocks))
+	for _, b := range blocks {
+		if err := v.Verify(ctx, &b.StateRoot); err != nil {
+			if errors.Is(err, ErrOutputRootMismatch) {
+				continue
+			}
+			return nil, err
+		}
+		verified = append(verified, b)
+	}
+	return verified, nil
+}
+
+// CheckHealth fails for a while after an output root mismatching the L2 node is skipped,
+// the mismatch is either a wrong proposal or a faulty L2 node and must be investigated
+func (v *OutputVerifier) CheckHealth(_ context.Context) (string, error) {
+	mismatch := v.lastMismatch.Load()
+	if mismatch == nil {
+		return "no mismatch", nil
+	}
+	since := time.Since(mismatch.at).Truncate(time.Second)
+	if since > outputMismatchAlertWindow {
+		return fmt.Sprintf("last mismatch %s ago", since), nil
+	}
+	return mismatch.detail, fmt.Errorf("critical: an output root mismatching the L2 node was skipped %s ago", since)
+}
+
+// OutputRootAtBlock returns the output root of the L2 block
+func (v *OutputVerifier) OutputRootAtBlock(ctx context.Context, l2BlockNumber *big.Int) (common.Hash, error) {
+	if !v.recompute.Load() {
+		outputRoot, err := v.outputAtBlock(ctx, l2BlockNumber)
+		if err == nil {
+			return outputRoot, nil
+		}
+		var rpcErr rpc.Error
+		if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != rpcMethodNotFound {
+			return common.Hash{}, err
+		}
+		v.log.Info("optimism_outputAtBlock is unsupported by the l2 node, recompute the output roots from the l2 blocks")
+		v.recompute.Store(true)
+	}
+	return v.computeOutputRoot(ctx, l2BlockNumber)
+}
+
+// outputAtBlock queries the output root from an op-node
+func (v *OutputVerifier) outputAtBlock(ctx context.Context, l2BlockNumber *big.Int) (common.Hash, error) {
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	var output struct {
+		OutputRoot common.Hash `json:"outputRoot"`
+	}
+	err := v.client.CallContext(ctx, &output, "optimism_outputAtBlock", hexutil.Uint64(l2BlockNumber.Uint64()))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return output.OutputRoot, nil
+}
+
+// computeOutputRoot recomputes the output root from the block and the storage root of
+// the message passer of an L2 execution node
+func (v *OutputVerifier) computeOutputRoot(ctx context.Context, l2BlockNumber *big.Int) (common.Hash, error) {
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+
+	blockNumber := hexutil.EncodeBig(l2BlockNumber)
+
+	// the hash is read from the node instead of hashing the header so that the
+	// L2 specific header fields don't matter
+	var block *struct {
+		Hash      common.Hash `json:"hash"`
+		StateRoot common.Hash `json:"stateRoot"`
+	}
+	if err := v.client.CallContext(ctx, &block, "eth_getBlockByNumber", blockNumber, false); err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get l2 block: %w", err)
+	}
+	if block == nil {
+		return common.Hash{}, fmt.Errorf("l2 block %s not found", l2BlockNumber.String())
+	}
+
+	var proof struct {
+		StorageHash common.Hash `json:"storageHash"`
+	}
+	if err := v.client.CallContext(ctx, &proof, "eth_getProof", L2ToL1MessagePasserAddr, []string{}, blockNumber); err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get the message passer proof: %w", err)
+	}
+
+	return ComputeOutputRootV0(block.StateRoot, proof.StorageHash, block.Hash), nil
+}
+
+// ComputeOutputRootV0 returns keccak256(version || stateRoot || messagePasserStorageRoot || blockHash)
+func ComputeOutputRootV0(stateRoot, messagePasserStorageRoot, blockHash common.Hash) common.Hash {
+	return crypto.Keccak256Hash(
+		OutputVersionV0[:], stateRoot[:], messagePasserStorageRoot[:], blockHash[:],
+	)
+}
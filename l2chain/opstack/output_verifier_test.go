@@ -0,0 +1,150 @@
+package opstack_test
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"go.uber.org/zap"
+
+	"github.com/Manta-Network/manta-fp/l2chain/opstack"
+	"github.com/Manta-Network/manta-fp/metrics"
+	"github.com/Manta-Network/manta-fp/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testBlockHash   = common.HexToHash("0x01")
+	testStateRoot   = common.HexToHash("0x02")
+	testStorageRoot = common.HexToHash("0x03")
+)
+
+type optimismAPI struct {
+	outputRoot common.Hash
+}
+
+func (api *optimismAPI) OutputAtBlock(_ hexutil.Uint64) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"version":    common.Hash{},
+		"outputRoot": api.outputRoot,
+	}, nil
+}
+
+type ethAPI struct{}
+
+func (api *ethAPI) GetBlockByNumber(_ string, _ bool) (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"hash":      testBlockHash,
+		"stateRoot": testStateRoot,
+	}, nil
+}
+
+func (api *ethAPI) GetProof(address common.Address, _ []string, _ string) (map[string]interface{}, error) {
+	if address != opstack.L2ToL1MessagePasserAddr {
+		return map[string]interface{}{"storageHash": common.Hash{}}, nil
+	}
+	return map[string]interface{}{"storageHash": testStorageRoot}, nil
+}
+
+func newTestVerifier(t *testing.T, apis map[string]interface{}) *opstack.OutputVerifier {
+	server := rpc.NewServer()
+	for namespace, api := range apis {
+		require.NoError(t, server.RegisterName(namespace, api))
+	}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	verifier, err := opstack.NewOutputVerifier(context.Background(), httpServer.URL, metrics.NewFpMetrics(), zap.NewNop())
+	require.NoError(t, err)
+	t.Cleanup(verifier.Close)
+	return verifier
+}
+
+func newTestStateRoot(outputRoot common.Hash) *types.StateRoot {
+	return &types.StateRoot{
+		StateRoot:     outputRoot,
+		L2BlockNumber: big.NewInt(100),
+		L2OutputIndex: big.NewInt(1),
+	}
+}
+
+func TestOutputVerifierOutputAtBlock(t *testing.T) {
+	t.Parallel()
+
+	outputRoot := common.HexToHash("0xaa")
+	verifier := newTestVerifier(t, map[string]interface{}{
+		"optimism": &optimismAPI{outputRoot: outputRoot},
+	})
+
+	require.NoError(t, verifier.Verify(context.Background(), newTestStateRoot(outputRoot)))
+
+	err := verifier.Verify(context.Background(), newTestStateRoot(common.HexToHash("0xbb")))
+	require.ErrorIs(t, err, opstack.ErrOutputRootMismatch)
+}
+
+func TestOutputVerifierSkipsMismatch(t *testing.T) {
+	t.Parallel()
+
+	outputRoot := common.HexToHash("0xaa")
+	verifier := newTestVerifier(t, map[string]interface{}{
+		"optimism": &optimismAPI{outputRoot: outputRoot},
+	})
+	_, err := verifier.CheckHealth(context.Background())
+	require.NoError(t, err)
+
+	// the mismatching output is skipped, the valid outputs of the batch are kept
+	blocks := []*types.BlockInfo{
+		{Height: 1, StateRoot: *newTestStateRoot(outputRoot)},
+		{Height: 2, StateRoot: *newTestStateRoot(common.HexToHash("0xbb"))},
+		{Height: 3, StateRoot: *newTestStateRoot(outputRoot)},
+	}
+	verified, err := verifier.VerifyBlocks(context.Background(), blocks)
+	require.NoError(t, err)
+	require.Equal(t, []*types.BlockInfo{blocks[0], blocks[2]}, verified)
+
+	// the skipped mismatch fails the health check
+	_, err = verifier.CheckHealth(context.Background())
+	require.Error(t, err)
+
+	// all the blocks are returned without a verifier
+	var noVerifier *opstack.OutputVerifier
+	verified, err = noVerifier.VerifyBlocks(context.Background(), blocks)
+	require.NoError(t, err)
+	require.Equal(t, blocks, verified)
+}
+
+func TestOutputVerifierRecomputesOutputRoot(t *testing.T) {
+	t.Parallel()
+
+	// an execution node doesn't serve optimism_outputAtBlock
+	verifier := newTestVerifier(t, map[string]interface{}{
+		"eth": &ethAPI{},
+	})
+
+	outputRoot := opstack.ComputeOutputRootV0(testStateRoot, testStorageRoot, testBlockHash)
+	require.NoError(t, verifier.Verify(context.Background(), newTestStateRoot(outputRoot)))
+
+	err := verifier.Verify(context.Background(), newTestStateRoot(common.HexToHash("0xbb")))
+	require.ErrorIs(t, err, opstack.ErrOutputRootMismatch)
+}
+
+func TestComputeOutputRootV0(t *testing.T) {
+	t.Parallel()
+
+	stateRoot := common.HexToHash("0x1")
+	storageRoot := common.HexToHash("0x2")
+	blockHash := common.HexToHash("0x3")
+
+	var preimage []byte
+	preimage = append(preimage, make([]byte, 32)...)
+	preimage = append(preimage, stateRoot[:]...)
+	preimage = append(preimage, storageRoot[:]...)
+	preimage = append(preimage, blockHash[:]...)
+	require.Equal(t, crypto.Keccak256Hash(preimage), opstack.ComputeOutputRootV0(stateRoot, storageRoot, blockHash))
+}
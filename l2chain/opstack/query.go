@@ -0,0 +1,280 @@
+package opstack
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Manta-Network/manta-fp/types"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// QueryOutputsPath returns the outputs of the L2OutputOracle by output index or L2 block
+	QueryOutputsPath = "/query/outputs"
+	// QueryDisputeGamesPath returns the dispute games by game index
+	QueryDisputeGamesPath = "/query/dispute_games"
+
+	// MaxQueryRange is the max number of indexes queried at once
+	MaxQueryRange = 1000
+
+	defaultQueryTimeout = 10 * time.Second
+)
+
+// SignStatusFunc tells whether the daemon signed the output, the signing records are
+// kept by each daemon
+type SignStatusFunc func(stateRoot *types.StateRoot) (bool, error)
+
+// OutputView is an indexed output or dispute game as returned by the queries
+type OutputView struct {
+	StateRoot            string `json:"state_root"`
+	L2BlockNumber        uint64 `json:"l2_block_number"`
+	L2OutputIndex        uint64 `json:"l2_output_index"`
+	L1BlockNumber        uint64 `json:"l1_block_number"`
+	L1BlockHash          string `json:"l1_block_hash"`
+	L1Timestamp          uint64 `json:"l1_timestamp,omitempty"`
+	DisputeGameProxy     string `json:"dispute_game_proxy,omitempty"`
+	DisputeGameType      uint64 `json:"dispute_game_type,omitempty"`
+	Deleted              bool   `json:"deleted"`
+	DeletedL1BlockNumber uint64 `json:"deleted_l1_block_number,omitempty"`
+	Signed               bool   `json:"signed"`
+}
+
+// OutputsQuery selects the outputs by one of the output index, the L2 block or the
+// output index range [FromIndex, ToIndex], the indexes are game indexes for the dispute games
+type OutputsQuery struct {
+	Index     *uint64
+	L2Block   *uint64
+	FromIndex *uint64
+	ToIndex   *uint64
+}
+
+func (q *OutputsQuery) Values() url.Values {
+	values := url.Values{}
+	for name, v := range map[string]*uint64{
+		"index":      q.Index,
+		"l2_block":   q.L2Block,
+		"from_index": q.FromIndex,
+		"to_index":   q.ToIndex,
+	} {
+		if v != nil {
+			values.Set(name, strconv.FormatUint(*v, 10))
+		}
+	}
+	return values
+}
+
+// QueryHandler serves the queries of the indexed outputs over HTTP, it lets the operators
+// check which outputs were seen and signed without opening the database
+type QueryHandler struct {
+	sRStore    *OpStateRootStore
+	signStatus SignStatusFunc
+	logger     *zap.Logger
+}
+
+func NewQueryHandler(sRStore *OpStateRootStore, signStatus SignStatusFunc, logger *zap.Logger) *QueryHandler {
+	return &QueryHandler{
+		sRStore:    sRStore,
+		signStatus: signStatus,
+		logger:     logger,
+	}
+}
+
+// Register registers the query paths on the mux
+func (h *QueryHandler) Register(mux interface {
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}) {
+	mux.HandleFunc(QueryOutputsPath, h.handleOutputs)
+	mux.HandleFunc(QueryDisputeGamesPath, h.handleDisputeGames)
+}
+
+func (h *QueryHandler) handleOutputs(w http.ResponseWriter, r *http.Request) {
+	query, err := parseOutputsQuery(r.URL.Query())
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var stateRoots []*types.StateRoot
+	switch {
+	case query.L2Block != nil:
+		stateRoots, err = h.sRStore.GetStateRootsByL2Block(*query.L2Block)
+	case query.Index != nil:
+		stateRoots, err = h.sRStore.GetStateRootsByOutputIndex(*query.Index)
+	default:
+		err = h.sRStore.IterateStateRoots(*query.FromIndex, *query.ToIndex, func(stateRoot *types.StateRoot) error {
+			stateRoots = append(stateRoots, stateRoot)
+			return nil
+		})
+	}
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.writeOutputs(w, stateRoots)
+}
+
+func (h *QueryHandler) handleDisputeGames(w http.ResponseWriter, r *http.Request) {
+	query, err := parseOutputsQuery(r.URL.Query())
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if query.L2Block != nil {
+		// the games of an L2 block are returned along with the outputs
+		h.writeError(w, http.StatusBadRequest, fmt.Errorf("query the dispute games of an l2 block with %s", QueryOutputsPath))
+		return
+	}
+
+	fromIndex, toIndex := query.FromIndex, query.ToIndex
+	if query.Index != nil {
+		fromIndex, toIndex = query.Index, query.Index
+	}
+	var stateRoots []*types.StateRoot
+	err = h.sRStore.IterateDisputeGames(*fromIndex, *toIndex, func(stateRoot *types.StateRoot) error {
+		stateRoots = append(stateRoots, stateRoot)
+		return nil
+	})
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	h.writeOutputs(w, stateRoots)
+}
+
+func parseOutputsQuery(values url.Values) (*OutputsQuery, error) {
+	query := &OutputsQuery{}
+	for name, v := range map[string]**uint64{
+		"index":      &query.Index,
+		"l2_block":   &query.L2Block,
+		"from_index": &query.FromIndex,
+		"to_index":   &query.ToIndex,
+	} {
+		if !values.Has(name) {
+			continue
+		}
+		n, err := strconv.ParseUint(values.Get(name), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		*v = &n
+	}
+
+	switch {
+	case query.Index != nil || query.L2Block != nil:
+		if (query.Index != nil && query.L2Block != nil) || query.FromIndex != nil || query.ToIndex != nil {
+			return nil, errors.New("only one of index, l2_block or from_index and to_index can be queried")
+		}
+	case query.FromIndex != nil && query.ToIndex != nil:
+		if *query.FromIndex > *query.ToIndex {
+			return nil, errors.New("from_index is above to_index")
+		}
+		if *query.ToIndex-*query.FromIndex >= MaxQueryRange {
+			return nil, fmt.Errorf("at most %d indexes can be queried at once", MaxQueryRange)
+		}
+	default:
+		return nil, errors.New("one of index, l2_block or from_index and to_index is required")
+	}
+	return query, nil
+}
+
+func (h *QueryHandler) writeOutputs(w http.ResponseWriter, stateRoots []*types.StateRoot) {
+	views := make([]*OutputView, 0, len(stateRoots))
+	for _, stateRoot := range stateRoots {
+		view, err := h.outputView(stateRoot)
+		if err != nil {
+			h.writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		views = append(views, view)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(views); err != nil {
+		h.logger.Error("failed to write the query response", zap.String("err", err.Error()))
+	}
+}
+
+func (h *QueryHandler) outputView(stateRoot *types.StateRoot) (*OutputView, error) {
+	view := &OutputView{
+		StateRoot:            hex.EncodeToString(stateRoot.StateRoot[:]),
+		L1BlockNumber:        stateRoot.L1BlockNumber,
+		L1BlockHash:          stateRoot.L1BlockHash.Hex(),
+		DisputeGameType:      stateRoot.DisputeGameType,
+		Deleted:              stateRoot.Deleted,
+		DeletedL1BlockNumber: stateRoot.DeletedL1BlockNumber,
+	}
+	if stateRoot.L2BlockNumber != nil {
+		view.L2BlockNumber = stateRoot.L2BlockNumber.Uint64()
+	}
+	if stateRoot.L2OutputIndex != nil {
+		view.L2OutputIndex = stateRoot.L2OutputIndex.Uint64()
+	}
+	if stateRoot.IsDisputeGame() {
+		view.DisputeGameProxy = stateRoot.DisputeGameProxy.Hex()
+	}
+
+	block, err := h.sRStore.GetBlock(new(big.Int).SetUint64(stateRoot.L1BlockNumber))
+	if err == nil {
+		view.L1Timestamp = block.Timestamp
+	} else if !errors.Is(err, ErrBlockNotFound) {
+		return nil, err
+	}
+
+	if h.signStatus != nil {
+		signed, err := h.signStatus(stateRoot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get the sign status: %w", err)
+		}
+		view.Signed = signed
+	}
+	return view, nil
+}
+
+func (h *QueryHandler) writeError(w http.ResponseWriter, status int, err error) {
+	if status == http.StatusInternalServerError {
+		h.logger.Error("failed to query the indexed outputs", zap.String("err", err.Error()))
+	}
+	http.Error(w, err.Error(), status)
+}
+
+// QueryOutputs queries the outputs, or the dispute games, indexed by the daemon serving
+// the queries at the address
+func QueryOutputs(ctx context.Context, addr string, disputeGames bool, query *OutputsQuery) ([]*OutputView, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultQueryTimeout)
+	defer cancel()
+
+	path := QueryOutputsPath
+	if disputeGames {
+		path = QueryDisputeGamesPath
+	}
+	u := url.URL{Scheme: "http", Host: addr, Path: path, RawQuery: query.Values().Encode()}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("query failed with status %s: %s", resp.Status, body)
+	}
+	var views []*OutputView
+	if err := json.NewDecoder(resp.Body).Decode(&views); err != nil {
+		return nil, err
+	}
+	return views, nil
+}
@@ -0,0 +1,55 @@
+package opstack_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/Manta-Network/manta-fp/l2chain/opstack"
+	"github.com/Manta-Network/manta-fp/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryOutputs(t *testing.T) {
+	t.Parallel()
+
+	ss := newTestStore(t)
+	for i := int64(0); i < 3; i++ {
+		err := ss.SaveStateRoot(testOutput(10+i, i, byte(i+1)))
+		require.NoError(t, err)
+	}
+
+	mux := http.NewServeMux()
+	opstack.NewQueryHandler(ss, func(stateRoot *types.StateRoot) (bool, error) {
+		return stateRoot.L1BlockNumber <= 11, nil
+	}, zap.NewNop()).Register(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	addr := strings.TrimPrefix(srv.URL, "http://")
+
+	index := uint64(2)
+	outputs, err := opstack.QueryOutputs(context.Background(), addr, false, &opstack.OutputsQuery{Index: &index})
+	require.NoError(t, err)
+	require.Len(t, outputs, 1)
+	require.Equal(t, uint64(12), outputs[0].L1BlockNumber)
+	require.Equal(t, uint64(200), outputs[0].L2BlockNumber)
+	require.False(t, outputs[0].Signed)
+
+	from, to := uint64(0), uint64(5)
+	outputs, err = opstack.QueryOutputs(context.Background(), addr, false, &opstack.OutputsQuery{FromIndex: &from, ToIndex: &to})
+	require.NoError(t, err)
+	require.Len(t, outputs, 3)
+	require.True(t, outputs[1].Signed)
+
+	// the selectors can't be combined
+	l2Block := uint64(100)
+	_, err = opstack.QueryOutputs(context.Background(), addr, false, &opstack.OutputsQuery{Index: &index, L2Block: &l2Block})
+	require.ErrorContains(t, err, "400")
+	_, err = opstack.QueryOutputs(context.Background(), addr, true, &opstack.OutputsQuery{})
+	require.Error(t, err)
+}
@@ -0,0 +1,989 @@
+package opstack
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	ctypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Manta-Network/manta-fp/ethereum/node"
+	"github.com/Manta-Network/manta-fp/types"
+
+	"github.com/btcsuite/btcwallet/walletdb"
+	"github.com/lightningnetwork/lnd/kvdb"
+)
+
+var (
+	ErrCorruptedLatestBlockrDb = errors.New("latest block manager db is corrupted")
+
+	ErrCorruptedBlockHeaderDb = errors.New("block header manager db is corrupted")
+
+	ErrDuplicateBlock = errors.New("block header key name already exists")
+
+	ErrBlockNotFound = errors.New("block header key name not found")
+
+	ErrStateRootNotFound = errors.New("state root record not found")
+
+	ErrDuplicateStateRoot = errors.New("state root for given height and output index already exists")
+
+	ErrDuplicateDisputeGame = errors.New("dispute game for given index already exists")
+
+	ErrCorruptedEventDb = errors.New("indexer event db is corrupted")
+)
+
+var (
+	LatestBlock           = []byte("latestBlock")
+	LatestBlockKey        = []byte("latestBlockKey")
+	BlockHeaderName       = []byte("blockHeader")
+	StateRootBucketName   = []byte("opStateRoot")
+	DisputeGameBucketName = []byte("disputeGame")
+	EventBucketName       = []byte("indexerEvent")
+	CursorBucketName      = []byte("indexerCursor")
+
+	// TraversedHeaderBucketName keeps the hashes of the latest traversed headers keyed by height
+	TraversedHeaderBucketName = []byte("traversedHeader")
+
+	StateRootByL2BlockBucketName     = []byte("opStateRootByL2Block")
+	StateRootByOutputIndexBucketName = []byte("opStateRootByOutputIndex")
+)
+
+// EventType is the kind of an event recorded in the journal of the indexer
+type EventType string
+
+const (
+	EventOutputProposed     EventType = "output_proposed"
+	EventDisputeGameCreated EventType = "dispute_game_created"
+	EventOutputsDeleted     EventType = "outputs_deleted"
+)
+
+// IndexedEvent is an event of the journal, the journal is the ordered list of all the
+// indexed events that the subscribers consume at their own pace
+type IndexedEvent struct {
+	Seq            uint64                `json:"seq"`
+	Type           EventType             `json:"type"`
+	L1BlockNumber  uint64                `json:"l1_block_number"`
+	StateRoot      *types.StateRoot      `json:"state_root,omitempty"`
+	OutputsDeleted *types.OutputsDeleted `json:"outputs_deleted,omitempty"`
+}
+
+type OpStateRootStore struct {
+	db kvdb.Backend
+}
+
+func NewOpStateRootStore(db kvdb.Backend) (*OpStateRootStore, error) {
+	store := &OpStateRootStore{db}
+	if err := store.initBuckets(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *OpStateRootStore) initBuckets() error {
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		_, err := tx.CreateTopLevelBucket(LatestBlock)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.CreateTopLevelBucket(BlockHeaderName)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.CreateTopLevelBucket(StateRootBucketName)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.CreateTopLevelBucket(DisputeGameBucketName)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.CreateTopLevelBucket(EventBucketName)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.CreateTopLevelBucket(CursorBucketName)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.CreateTopLevelBucket(TraversedHeaderBucketName)
+		if err != nil {
+			return err
+		}
+
+		// the indexes are built from the stored records when they are first created, and
+		// built again once the outputs stored by L1 block only are keyed by output index too
+		buildIndexes := tx.ReadWriteBucket(StateRootByL2BlockBucketName) == nil
+		migrated, err := migrateStateRootKeys(tx)
+		if err != nil {
+			return err
+		}
+		if migrated && !buildIndexes {
+			for _, name := range [][]byte{StateRootByL2BlockBucketName, StateRootByOutputIndexBucketName} {
+				if err := tx.DeleteTopLevelBucket(name); err != nil && !errors.Is(err, walletdb.ErrBucketNotFound) {
+					return err
+				}
+			}
+			buildIndexes = true
+		}
+		_, err = tx.CreateTopLevelBucket(StateRootByL2BlockBucketName)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.CreateTopLevelBucket(StateRootByOutputIndexBucketName)
+		if err != nil {
+			return err
+		}
+
+		if buildIndexes {
+			return rebuildIndexes(tx)
+		}
+		return nil
+	})
+}
+
+func (s *OpStateRootStore) AddLatestBlock(blockNumber *big.Int) error {
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		latestBlockBucket := tx.ReadWriteBucket(LatestBlock)
+		if latestBlockBucket == nil {
+			return ErrCorruptedLatestBlockrDb
+		}
+
+		return latestBlockBucket.Put(LatestBlockKey, blockNumber.Bytes())
+	})
+}
+
+// AddTraversedHeaders saves the hashes of the traversed headers and the last of them as the
+// latest block, only the latest keep headers are kept to seed the reorg detection on restart
+func (s *OpStateRootStore) AddTraversedHeaders(headers []ctypes.Header, keep int) error {
+	if len(headers) == 0 {
+		return nil
+	}
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		latestBlockBucket := tx.ReadWriteBucket(LatestBlock)
+		if latestBlockBucket == nil {
+			return ErrCorruptedLatestBlockrDb
+		}
+		bucket := tx.ReadWriteBucket(TraversedHeaderBucketName)
+		if bucket == nil {
+			return ErrCorruptedBlockHeaderDb
+		}
+
+		for i := range headers {
+			if err := bucket.Put(getSeqKey(headers[i].Number.Uint64()), headers[i].Hash().Bytes()); err != nil {
+				return err
+			}
+		}
+
+		var count int
+		if err := bucket.ForEach(func(_, _ []byte) error {
+			count++
+			return nil
+		}); err != nil {
+			return err
+		}
+		var prunedKeys [][]byte
+		c := bucket.ReadCursor()
+		for k, _ := c.First(); k != nil && count > keep; k, _ = c.Next() {
+			prunedKeys = append(prunedKeys, k)
+			count--
+		}
+		for _, k := range prunedKeys {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		return latestBlockBucket.Put(LatestBlockKey, headers[len(headers)-1].Number.Bytes())
+	})
+}
+
+// GetTraversedHeaders returns the saved hashes of the traversed headers in the ascending order of height
+func (s *OpStateRootStore) GetTraversedHeaders() ([]node.TrackedHeader, error) {
+	var headers []node.TrackedHeader
+	err := s.db.View(func(tx kvdb.RTx) error {
+		headers = nil
+		bucket := tx.ReadBucket(TraversedHeaderBucketName)
+		if bucket == nil {
+			return ErrCorruptedBlockHeaderDb
+		}
+		return bucket.ForEach(func(k, v []byte) error {
+			headers = append(headers, node.TrackedHeader{
+				Number: new(big.Int).SetUint64(binary.BigEndian.Uint64(k)),
+				Hash:   common.BytesToHash(v),
+			})
+			return nil
+		})
+	}, func() {})
+	if err != nil {
+		return nil, err
+	}
+	return headers, nil
+}
+
+func (s *OpStateRootStore) GetLatestBlock() (*big.Int, error) {
+	var blockNumber *big.Int
+	err := s.db.View(func(tx kvdb.RTx) error {
+		blockNumberBucket := tx.ReadBucket(LatestBlock)
+		if blockNumberBucket == nil {
+			return ErrCorruptedLatestBlockrDb
+		}
+
+		blockByte := blockNumberBucket.Get(LatestBlockKey)
+		if blockByte == nil {
+			return nil
+		}
+		blockNumber = new(big.Int).SetBytes(blockByte)
+		return nil
+	}, func() {})
+
+	if err != nil {
+		return nil, err
+	}
+	return blockNumber, nil
+}
+
+func (s *OpStateRootStore) AddBlock(
+	blockNumber *big.Int,
+	Hash common.Hash,
+	ParentHash common.Hash,
+	Timestamp uint64,
+) error {
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		blockHeaderBucket := tx.ReadWriteBucket(BlockHeaderName)
+		if blockHeaderBucket == nil {
+			return ErrCorruptedBlockHeaderDb
+		}
+
+		if blockHeaderBucket.Get(blockNumber.Bytes()) != nil {
+			return ErrDuplicateBlock
+		}
+
+		return saveBlock(blockHeaderBucket, blockNumber, Hash, ParentHash, Timestamp)
+	})
+}
+
+func saveBlock(
+	blockHeaderBucket walletdb.ReadWriteBucket,
+	blockNumber *big.Int,
+	Hash common.Hash,
+	ParentHash common.Hash,
+	Timestamp uint64,
+) error {
+	block := &types.Block{
+		Hash:       Hash,
+		ParentHash: ParentHash,
+		Number:     blockNumber,
+		Timestamp:  Timestamp,
+	}
+
+	blockMarshalled, err := json.Marshal(block)
+	if err != nil {
+		return err
+	}
+
+	return blockHeaderBucket.Put(blockNumber.Bytes(), blockMarshalled)
+}
+
+func (s *OpStateRootStore) GetBlock(blockNumber *big.Int) (*types.Block, error) {
+	blockInfo := &types.Block{}
+	err := s.db.View(func(tx kvdb.RTx) error {
+		blockNumberBucket := tx.ReadBucket(BlockHeaderName)
+		if blockNumberBucket == nil {
+			return ErrCorruptedBlockHeaderDb
+		}
+
+		blockByte := blockNumberBucket.Get(blockNumber.Bytes())
+		if blockByte == nil {
+			return ErrBlockNotFound
+		}
+
+		err := json.Unmarshal(blockByte, blockInfo)
+		if err != nil {
+			return err
+		}
+		return nil
+	}, func() {})
+
+	if err != nil {
+		return nil, err
+	}
+	return blockInfo, nil
+}
+
+// SaveStateRoot saves an output proposed to the L2OutputOracle, keyed by its L1 block and
+// its output index since several outputs can be proposed in an L1 block, and appends it
+// to the journal
+func (s *OpStateRootStore) SaveStateRoot(stateRoot *types.StateRoot) error {
+	key := getStateRootKey(stateRoot)
+
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(StateRootBucketName)
+		if bucket == nil {
+			return ErrCorruptedBlockHeaderDb
+		}
+
+		if bucket.Get(key) != nil {
+			return ErrDuplicateStateRoot
+		}
+
+		stateRootMarshalled, err := json.Marshal(stateRoot)
+		if err != nil {
+			return err
+		}
+
+		if err := bucket.Put(key, stateRootMarshalled); err != nil {
+			return err
+		}
+		if err := putIndexes(tx, stateRoot); err != nil {
+			return err
+		}
+		return appendEvent(tx, &IndexedEvent{
+			Type:          EventOutputProposed,
+			L1BlockNumber: stateRoot.L1BlockNumber,
+			StateRoot:     stateRoot,
+		})
+	})
+}
+
+// GetStateRoot returns the output proposed at the L2 output index in the L1 block
+func (s *OpStateRootStore) GetStateRoot(l1BlockNumber, l2OutputIndex *big.Int) (*types.StateRoot, error) {
+	stateRootRes := &types.StateRoot{}
+	err := s.db.View(func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(StateRootBucketName)
+		if bucket == nil {
+			return ErrCorruptedBlockHeaderDb
+		}
+
+		StateRootBytes := bucket.Get(getOutputKey(l1BlockNumber.Uint64(), l2OutputIndex.Uint64()))
+		if StateRootBytes == nil {
+			return ErrStateRootNotFound
+		}
+		err := json.Unmarshal(StateRootBytes, stateRootRes)
+		if err != nil {
+			return err
+		}
+		return nil
+	}, func() {})
+
+	if err != nil {
+		if errors.Is(err, ErrStateRootNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return stateRootRes, nil
+}
+
+// MarkOutputsDeleted marks the outputs of the L2OutputOracle whose index is in
+// [NewNextOutputIndex, PrevNextOutputIndex) as deleted at the L1 block of the event, sets
+// them to the StateRoots of the event and appends it to the journal, the dispute games
+// aren't affected
+func (s *OpStateRootStore) MarkOutputsDeleted(outputsDeleted *types.OutputsDeleted) error {
+	fromIndex, toIndex := outputsDeleted.NewNextOutputIndex, outputsDeleted.PrevNextOutputIndex
+	l1BlockNumber := outputsDeleted.L1BlockNumber
+
+	var marked []*types.StateRoot
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		marked = nil
+		bucket := tx.ReadWriteBucket(StateRootBucketName)
+		if bucket == nil {
+			return ErrCorruptedBlockHeaderDb
+		}
+		outputIndexBucket := tx.ReadBucket(StateRootByOutputIndexBucketName)
+		if outputIndexBucket == nil {
+			return ErrCorruptedBlockHeaderDb
+		}
+
+		var recordKeys [][]byte
+		c := outputIndexBucket.ReadCursor()
+		for k, _ := c.Seek(getOutputIndexKey(fromIndex.Uint64(), 0)); k != nil; k, _ = c.Next() {
+			if toIndex.Cmp(new(big.Int).SetUint64(binary.BigEndian.Uint64(k))) <= 0 {
+				break
+			}
+			// the outputs proposed after the deletion reuse the deleted indexes
+			if binary.BigEndian.Uint64(k[8:]) > l1BlockNumber {
+				continue
+			}
+			recordKeys = append(recordKeys, outputRecordKey(k))
+		}
+
+		for _, k := range recordKeys {
+			sttRoot, err := getIndexedStateRoot(tx, indexKindOutput, k)
+			if err != nil {
+				return err
+			}
+			if sttRoot.Deleted {
+				continue
+			}
+			sttRoot.Deleted = true
+			sttRoot.DeletedL1BlockNumber = l1BlockNumber
+			stateRootMarshalled, err := json.Marshal(sttRoot)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put(k, stateRootMarshalled); err != nil {
+				return err
+			}
+			marked = append(marked, sttRoot)
+		}
+
+		outputsDeleted.StateRoots = marked
+		return appendEvent(tx, &IndexedEvent{
+			Type:           EventOutputsDeleted,
+			L1BlockNumber:  l1BlockNumber,
+			OutputsDeleted: outputsDeleted,
+		})
+	})
+}
+
+// IsOutputDeleted tells whether the output root proposed at the L2 output index was deleted
+// from the L2OutputOracle, the outputs proposed again at a deleted index aren't deleted
+func (s *OpStateRootStore) IsOutputDeleted(l2OutputIndex *big.Int, stateRoot [32]byte) (bool, error) {
+	stateRoots, err := s.GetStateRootsByOutputIndex(l2OutputIndex.Uint64())
+	if err != nil {
+		return false, err
+	}
+	for _, sttRoot := range stateRoots {
+		if sttRoot.Deleted && sttRoot.StateRoot == stateRoot {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SaveDisputeGame saves the root claim of a dispute game, the games are keyed by their
+// index in the DisputeGameFactory since several games can be created in an L1 block
+func (s *OpStateRootStore) SaveDisputeGame(stateRoot *types.StateRoot) error {
+	key := getDisputeGameKey(stateRoot.L2OutputIndex.Uint64())
+
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(DisputeGameBucketName)
+		if bucket == nil {
+			return ErrCorruptedBlockHeaderDb
+		}
+
+		if bucket.Get(key) != nil {
+			return ErrDuplicateDisputeGame
+		}
+
+		stateRootMarshalled, err := json.Marshal(stateRoot)
+		if err != nil {
+			return err
+		}
+
+		if err := bucket.Put(key, stateRootMarshalled); err != nil {
+			return err
+		}
+		if err := putIndexes(tx, stateRoot); err != nil {
+			return err
+		}
+		return appendEvent(tx, &IndexedEvent{
+			Type:          EventDisputeGameCreated,
+			L1BlockNumber: stateRoot.L1BlockNumber,
+			StateRoot:     stateRoot,
+		})
+	})
+}
+
+func (s *OpStateRootStore) GetDisputeGame(gameIndex uint64) (*types.StateRoot, error) {
+	stateRootRes := &types.StateRoot{}
+	err := s.db.View(func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(DisputeGameBucketName)
+		if bucket == nil {
+			return ErrCorruptedBlockHeaderDb
+		}
+
+		stateRootBytes := bucket.Get(getDisputeGameKey(gameIndex))
+		if stateRootBytes == nil {
+			return ErrStateRootNotFound
+		}
+		return json.Unmarshal(stateRootBytes, stateRootRes)
+	}, func() {})
+
+	if err != nil {
+		if errors.Is(err, ErrStateRootNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return stateRootRes, nil
+}
+
+// getOutputKey returns the key of an output, the outputs are ordered by L1 block and by index
+func getOutputKey(l1BlockNumber, l2OutputIndex uint64) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key, l1BlockNumber)
+	binary.BigEndian.PutUint64(key[8:], l2OutputIndex)
+	return key
+}
+
+func getStateRootKey(stateRoot *types.StateRoot) []byte {
+	return getOutputKey(stateRoot.L1BlockNumber, stateRoot.L2OutputIndex.Uint64())
+}
+
+// migrateStateRootKeys keys the outputs stored by their L1 block only by their L1 block
+// and their output index, true is returned if any output is migrated
+func migrateStateRootKeys(tx kvdb.RwTx) (bool, error) {
+	bucket, err := tx.CreateTopLevelBucket(StateRootBucketName)
+	if err != nil {
+		return false, err
+	}
+
+	legacy := make(map[string]*types.StateRoot)
+	err = bucket.ForEach(func(k, v []byte) error {
+		// the legacy keys are the L1 block numbers, at most 8 bytes long
+		if len(k) == 16 {
+			return nil
+		}
+		sttRoot := &types.StateRoot{}
+		if err := json.Unmarshal(v, sttRoot); err != nil {
+			return err
+		}
+		sttRoot.L1BlockNumber = new(big.Int).SetBytes(k).Uint64()
+		legacy[string(k)] = sttRoot
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for k, sttRoot := range legacy {
+		if err := bucket.Delete([]byte(k)); err != nil {
+			return false, err
+		}
+		if sttRoot.L2OutputIndex == nil {
+			continue
+		}
+		stateRootMarshalled, err := json.Marshal(sttRoot)
+		if err != nil {
+			return false, err
+		}
+		if err := bucket.Put(getStateRootKey(sttRoot), stateRootMarshalled); err != nil {
+			return false, err
+		}
+	}
+	return len(legacy) > 0, nil
+}
+
+func getDisputeGameKey(gameIndex uint64) []byte {
+	return getSeqKey(gameIndex)
+}
+
+func getSeqKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
+
+// appendEvent appends the event to the journal with the next sequence number
+func appendEvent(tx kvdb.RwTx, event *IndexedEvent) error {
+	bucket := tx.ReadWriteBucket(EventBucketName)
+	if bucket == nil {
+		return ErrCorruptedEventDb
+	}
+
+	event.Seq = nextSeq(bucket)
+	eventMarshalled, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return bucket.Put(getSeqKey(event.Seq), eventMarshalled)
+}
+
+// nextSeq returns the sequence number following the last event of the journal
+func nextSeq(bucket walletdb.ReadBucket) uint64 {
+	k, _ := bucket.ReadCursor().Last()
+	if k == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(k) + 1
+}
+
+// NextEventSeq returns the sequence number of the next event appended to the journal
+func (s *OpStateRootStore) NextEventSeq() (uint64, error) {
+	var seq uint64
+	err := s.db.View(func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(EventBucketName)
+		if bucket == nil {
+			return ErrCorruptedEventDb
+		}
+		seq = nextSeq(bucket)
+		return nil
+	}, func() {})
+	if err != nil {
+		return 0, err
+	}
+	return seq, nil
+}
+
+// GetEvents returns at most limit events of the journal from the sequence number
+func (s *OpStateRootStore) GetEvents(fromSeq uint64, limit int) ([]*IndexedEvent, error) {
+	var events []*IndexedEvent
+	err := s.db.View(func(tx kvdb.RTx) error {
+		events = nil
+		bucket := tx.ReadBucket(EventBucketName)
+		if bucket == nil {
+			return ErrCorruptedEventDb
+		}
+
+		c := bucket.ReadCursor()
+		for k, v := c.Seek(getSeqKey(fromSeq)); k != nil && len(events) < limit; k, v = c.Next() {
+			event := &IndexedEvent{}
+			if err := json.Unmarshal(v, event); err != nil {
+				return err
+			}
+			events = append(events, event)
+		}
+		return nil
+	}, func() {})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// FirstEventSeqAfter returns the sequence number of the first event of the journal above
+// the L1 block, or the next sequence number if there is none
+func (s *OpStateRootStore) FirstEventSeqAfter(l1BlockNumber uint64) (uint64, error) {
+	var seq uint64
+	err := s.db.View(func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(EventBucketName)
+		if bucket == nil {
+			return ErrCorruptedEventDb
+		}
+
+		seq = nextSeq(bucket)
+		// the events are appended in the order of their L1 blocks
+		c := bucket.ReadCursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			event := &IndexedEvent{}
+			if err := json.Unmarshal(v, event); err != nil {
+				return err
+			}
+			if event.L1BlockNumber <= l1BlockNumber {
+				break
+			}
+			seq = event.Seq
+		}
+		return nil
+	}, func() {})
+	if err != nil {
+		return 0, err
+	}
+	return seq, nil
+}
+
+// PruneEvents deletes the journal events every persisted cursor is past, the events above
+// the lowest traversed header are kept for the rollback of the reorgs and the last event is
+// kept for the sequence numbers. The number of deleted events is returned
+func (s *OpStateRootStore) PruneEvents() (int, error) {
+	var pruned int
+	err := kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		pruned = 0
+		eventBucket := tx.ReadWriteBucket(EventBucketName)
+		if eventBucket == nil {
+			return ErrCorruptedEventDb
+		}
+		cursorBucket := tx.ReadBucket(CursorBucketName)
+		if cursorBucket == nil {
+			return ErrCorruptedEventDb
+		}
+		traversedHeaderBucket := tx.ReadBucket(TraversedHeaderBucketName)
+		if traversedHeaderBucket == nil {
+			return ErrCorruptedBlockHeaderDb
+		}
+
+		// the journal is kept whole until the reorg window and a subscriber are known
+		lowestHeader, _ := traversedHeaderBucket.ReadCursor().First()
+		if lowestHeader == nil {
+			return nil
+		}
+		minCursor, found := uint64(0), false
+		err := cursorBucket.ForEach(func(_, v []byte) error {
+			if seq := binary.BigEndian.Uint64(v); !found || seq < minCursor {
+				minCursor, found = seq, true
+			}
+			return nil
+		})
+		if err != nil || !found {
+			return err
+		}
+		if last := nextSeq(eventBucket); last == 0 {
+			return nil
+		} else if minCursor > last-1 {
+			minCursor = last - 1
+		}
+
+		var eventKeys [][]byte
+		c := eventBucket.ReadCursor()
+		for k, v := c.First(); k != nil && binary.BigEndian.Uint64(k) < minCursor; k, v = c.Next() {
+			event := &IndexedEvent{}
+			if err := json.Unmarshal(v, event); err != nil {
+				return err
+			}
+			if event.L1BlockNumber >= binary.BigEndian.Uint64(lowestHeader) {
+				break
+			}
+			eventKeys = append(eventKeys, k)
+		}
+		for _, k := range eventKeys {
+			if err := eventBucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		pruned = len(eventKeys)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return pruned, nil
+}
+
+// GetCursor returns the next sequence number the subscriber consumes, false is returned
+// if the subscriber has no cursor yet
+func (s *OpStateRootStore) GetCursor(name string) (uint64, bool, error) {
+	var (
+		seq   uint64
+		found bool
+	)
+	err := s.db.View(func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(CursorBucketName)
+		if bucket == nil {
+			return ErrCorruptedEventDb
+		}
+
+		v := bucket.Get([]byte(name))
+		found = v != nil
+		if found {
+			seq = binary.BigEndian.Uint64(v)
+		}
+		return nil
+	}, func() {})
+	if err != nil {
+		return 0, false, err
+	}
+	return seq, found, nil
+}
+
+// SaveCursor saves the next sequence number the subscriber consumes
+func (s *OpStateRootStore) SaveCursor(name string, seq uint64) error {
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(CursorBucketName)
+		if bucket == nil {
+			return ErrCorruptedEventDb
+		}
+		return bucket.Put([]byte(name), getSeqKey(seq))
+	})
+}
+
+// DeleteStateRootsAfter deletes the state roots, the block headers, the traversed headers
+// and the journal events above the given L1 block and restores the outputs deleted above it, the
+// cursors past the end of the journal are moved back to it. It is used to roll back
+// the reorged L1 blocks
+func (s *OpStateRootStore) DeleteStateRootsAfter(l1BlockNumber *big.Int) ([]*types.StateRoot, error) {
+	var deleted []*types.StateRoot
+	err := kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		deleted = nil
+		bucket := tx.ReadWriteBucket(StateRootBucketName)
+		if bucket == nil {
+			return ErrCorruptedBlockHeaderDb
+		}
+		blockHeaderBucket := tx.ReadWriteBucket(BlockHeaderName)
+		if blockHeaderBucket == nil {
+			return ErrCorruptedBlockHeaderDb
+		}
+		disputeGameBucket := tx.ReadWriteBucket(DisputeGameBucketName)
+		if disputeGameBucket == nil {
+			return ErrCorruptedBlockHeaderDb
+		}
+		traversedHeaderBucket := tx.ReadWriteBucket(TraversedHeaderBucketName)
+		if traversedHeaderBucket == nil {
+			return ErrCorruptedBlockHeaderDb
+		}
+
+		var stateRootKeys [][]byte
+		c := bucket.ReadCursor()
+		for k, v := c.Seek(getOutputKey(l1BlockNumber.Uint64()+1, 0)); k != nil; k, v = c.Next() {
+			sttRoot := &types.StateRoot{}
+			if err := json.Unmarshal(v, sttRoot); err != nil {
+				return err
+			}
+			deleted = append(deleted, sttRoot)
+			stateRootKeys = append(stateRootKeys, k)
+		}
+		// the deletions reorged out are journaled with the outputs they deleted
+		restored, err := reorgedDeletions(tx, l1BlockNumber.Uint64())
+		if err != nil {
+			return err
+		}
+		var disputeGameKeys [][]byte
+		err = disputeGameBucket.ForEach(func(k, v []byte) error {
+			sttRoot := &types.StateRoot{}
+			if err := json.Unmarshal(v, sttRoot); err != nil {
+				return err
+			}
+			if sttRoot.L1BlockNumber <= l1BlockNumber.Uint64() {
+				return nil
+			}
+			deleted = append(deleted, sttRoot)
+			disputeGameKeys = append(disputeGameKeys, k)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		var blockKeys [][]byte
+		err = blockHeaderBucket.ForEach(func(k, _ []byte) error {
+			if new(big.Int).SetBytes(k).Cmp(l1BlockNumber) > 0 {
+				blockKeys = append(blockKeys, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range stateRootKeys {
+			if err := bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		for _, k := range restored {
+			sttRoot, err := getIndexedStateRoot(tx, indexKindOutput, k)
+			if errors.Is(err, ErrStateRootNotFound) {
+				// the output is reorged out with the deletion
+				continue
+			} else if err != nil {
+				return err
+			}
+			sttRoot.Deleted = false
+			sttRoot.DeletedL1BlockNumber = 0
+			stateRootMarshalled, err := json.Marshal(sttRoot)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put(k, stateRootMarshalled); err != nil {
+				return err
+			}
+		}
+		for _, k := range disputeGameKeys {
+			if err := disputeGameBucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		for _, k := range blockKeys {
+			if err := blockHeaderBucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		var traversedHeaderKeys [][]byte
+		c = traversedHeaderBucket.ReadCursor()
+		for k, _ := c.Seek(getSeqKey(l1BlockNumber.Uint64() + 1)); k != nil; k, _ = c.Next() {
+			traversedHeaderKeys = append(traversedHeaderKeys, k)
+		}
+		for _, k := range traversedHeaderKeys {
+			if err := traversedHeaderBucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		for _, sttRoot := range deleted {
+			if err := deleteIndexes(tx, sttRoot); err != nil {
+				return err
+			}
+		}
+		return truncateEventsAfter(tx, l1BlockNumber.Uint64())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+// reorgedDeletions returns the keys of the outputs deleted by the OutputsDeleted events
+// journaled above the L1 block
+func reorgedDeletions(tx kvdb.RTx, l1BlockNumber uint64) ([][]byte, error) {
+	eventBucket := tx.ReadBucket(EventBucketName)
+	if eventBucket == nil {
+		return nil, ErrCorruptedEventDb
+	}
+
+	var recordKeys [][]byte
+	c := eventBucket.ReadCursor()
+	for k, v := c.Last(); k != nil; k, v = c.Prev() {
+		event := &IndexedEvent{}
+		if err := json.Unmarshal(v, event); err != nil {
+			return nil, err
+		}
+		if event.L1BlockNumber <= l1BlockNumber {
+			break
+		}
+		if event.Type != EventOutputsDeleted || event.OutputsDeleted == nil {
+			continue
+		}
+		for _, sttRoot := range event.OutputsDeleted.StateRoots {
+			if sttRoot.L2OutputIndex != nil && sttRoot.L1BlockNumber <= l1BlockNumber {
+				recordKeys = append(recordKeys, getStateRootKey(sttRoot))
+			}
+		}
+	}
+	return recordKeys, nil
+}
+
+// truncateEventsAfter deletes the journal events above the L1 block and moves the
+// cursors past the end of the journal back to it
+func truncateEventsAfter(tx kvdb.RwTx, l1BlockNumber uint64) error {
+	eventBucket := tx.ReadWriteBucket(EventBucketName)
+	if eventBucket == nil {
+		return ErrCorruptedEventDb
+	}
+	cursorBucket := tx.ReadWriteBucket(CursorBucketName)
+	if cursorBucket == nil {
+		return ErrCorruptedEventDb
+	}
+
+	var eventKeys [][]byte
+	c := eventBucket.ReadCursor()
+	for k, v := c.Last(); k != nil; k, v = c.Prev() {
+		event := &IndexedEvent{}
+		if err := json.Unmarshal(v, event); err != nil {
+			return err
+		}
+		if event.L1BlockNumber <= l1BlockNumber {
+			break
+		}
+		eventKeys = append(eventKeys, k)
+	}
+	for _, k := range eventKeys {
+		if err := eventBucket.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	next := getSeqKey(nextSeq(eventBucket))
+	var cursorNames [][]byte
+	err := cursorBucket.ForEach(func(k, v []byte) error {
+		if binary.BigEndian.Uint64(v) > binary.BigEndian.Uint64(next) {
+			cursorNames = append(cursorNames, k)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, k := range cursorNames {
+		if err := cursorBucket.Put(k, next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
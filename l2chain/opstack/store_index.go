@@ -0,0 +1,244 @@
+package opstack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+
+	"github.com/Manta-Network/manta-fp/types"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+)
+
+// the kinds of records referenced by the L2 block index
+const (
+	indexKindOutput      byte = 0
+	indexKindDisputeGame byte = 1
+)
+
+// the L2 block index is keyed by l2BlockNumber || kind || record key and the output index
+// index by l2OutputIndex || l1BlockNumber, where the record key is the key of the output
+// or of the dispute game in its own bucket. The values are empty, the records are read
+// from their own bucket
+
+func getL2BlockIndexKey(l2BlockNumber uint64, kind byte, recordKey []byte) []byte {
+	key := make([]byte, 9, 9+len(recordKey))
+	binary.BigEndian.PutUint64(key, l2BlockNumber)
+	key[8] = kind
+	return append(key, recordKey...)
+}
+
+func getOutputIndexKey(l2OutputIndex, l1BlockNumber uint64) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key, l2OutputIndex)
+	binary.BigEndian.PutUint64(key[8:], l1BlockNumber)
+	return key
+}
+
+func putIndexes(tx kvdb.RwTx, stateRoot *types.StateRoot) error {
+	l2BlockBucket := tx.ReadWriteBucket(StateRootByL2BlockBucketName)
+	if l2BlockBucket == nil {
+		return ErrCorruptedBlockHeaderDb
+	}
+	outputIndexBucket := tx.ReadWriteBucket(StateRootByOutputIndexBucketName)
+	if outputIndexBucket == nil {
+		return ErrCorruptedBlockHeaderDb
+	}
+
+	if stateRoot.IsDisputeGame() {
+		return l2BlockBucket.Put(getL2BlockIndexKey(stateRoot.L2BlockNumber.Uint64(), indexKindDisputeGame, getDisputeGameKey(stateRoot.L2OutputIndex.Uint64())), []byte{})
+	}
+	err := l2BlockBucket.Put(getL2BlockIndexKey(stateRoot.L2BlockNumber.Uint64(), indexKindOutput, getStateRootKey(stateRoot)), []byte{})
+	if err != nil {
+		return err
+	}
+	return outputIndexBucket.Put(getOutputIndexKey(stateRoot.L2OutputIndex.Uint64(), stateRoot.L1BlockNumber), []byte{})
+}
+
+func deleteIndexes(tx kvdb.RwTx, stateRoot *types.StateRoot) error {
+	l2BlockBucket := tx.ReadWriteBucket(StateRootByL2BlockBucketName)
+	if l2BlockBucket == nil {
+		return ErrCorruptedBlockHeaderDb
+	}
+	outputIndexBucket := tx.ReadWriteBucket(StateRootByOutputIndexBucketName)
+	if outputIndexBucket == nil {
+		return ErrCorruptedBlockHeaderDb
+	}
+
+	if stateRoot.IsDisputeGame() {
+		return l2BlockBucket.Delete(getL2BlockIndexKey(stateRoot.L2BlockNumber.Uint64(), indexKindDisputeGame, getDisputeGameKey(stateRoot.L2OutputIndex.Uint64())))
+	}
+	err := l2BlockBucket.Delete(getL2BlockIndexKey(stateRoot.L2BlockNumber.Uint64(), indexKindOutput, getStateRootKey(stateRoot)))
+	if err != nil {
+		return err
+	}
+	return outputIndexBucket.Delete(getOutputIndexKey(stateRoot.L2OutputIndex.Uint64(), stateRoot.L1BlockNumber))
+}
+
+// rebuildIndexes indexes the outputs and the dispute games stored before the indexes existed
+func rebuildIndexes(tx kvdb.RwTx) error {
+	bucket := tx.ReadWriteBucket(StateRootBucketName)
+	if bucket == nil {
+		return ErrCorruptedBlockHeaderDb
+	}
+	disputeGameBucket := tx.ReadWriteBucket(DisputeGameBucketName)
+	if disputeGameBucket == nil {
+		return ErrCorruptedBlockHeaderDb
+	}
+
+	var stateRoots []*types.StateRoot
+	err := bucket.ForEach(func(_, v []byte) error {
+		sttRoot := &types.StateRoot{}
+		if err := json.Unmarshal(v, sttRoot); err != nil {
+			return err
+		}
+		stateRoots = append(stateRoots, sttRoot)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	err = disputeGameBucket.ForEach(func(_, v []byte) error {
+		sttRoot := &types.StateRoot{}
+		if err := json.Unmarshal(v, sttRoot); err != nil {
+			return err
+		}
+		stateRoots = append(stateRoots, sttRoot)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, sttRoot := range stateRoots {
+		if sttRoot.L2BlockNumber == nil || sttRoot.L2OutputIndex == nil {
+			continue
+		}
+		if err := putIndexes(tx, sttRoot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getIndexedStateRoot reads the output or the dispute game referenced by an index key
+func getIndexedStateRoot(tx kvdb.RTx, kind byte, recordKey []byte) (*types.StateRoot, error) {
+	bucketName := StateRootBucketName
+	if kind == indexKindDisputeGame {
+		bucketName = DisputeGameBucketName
+	}
+	bucket := tx.ReadBucket(bucketName)
+	if bucket == nil {
+		return nil, ErrCorruptedBlockHeaderDb
+	}
+	v := bucket.Get(recordKey)
+	if v == nil {
+		return nil, ErrStateRootNotFound
+	}
+
+	sttRoot := &types.StateRoot{}
+	if err := json.Unmarshal(v, sttRoot); err != nil {
+		return nil, err
+	}
+	return sttRoot, nil
+}
+
+// outputRecordKey returns the key of the output referenced by an output index index key
+func outputRecordKey(outputIndexKey []byte) []byte {
+	return getOutputKey(binary.BigEndian.Uint64(outputIndexKey[8:]), binary.BigEndian.Uint64(outputIndexKey))
+}
+
+// GetStateRootsByL2Block returns the outputs and the dispute games proposed for the L2 block
+func (s *OpStateRootStore) GetStateRootsByL2Block(l2BlockNumber uint64) ([]*types.StateRoot, error) {
+	var stateRoots []*types.StateRoot
+	err := s.db.View(func(tx kvdb.RTx) error {
+		stateRoots = nil
+		bucket := tx.ReadBucket(StateRootByL2BlockBucketName)
+		if bucket == nil {
+			return ErrCorruptedBlockHeaderDb
+		}
+
+		prefix := make([]byte, 8)
+		binary.BigEndian.PutUint64(prefix, l2BlockNumber)
+		c := bucket.ReadCursor()
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			sttRoot, err := getIndexedStateRoot(tx, k[8], k[9:])
+			if err != nil {
+				return err
+			}
+			stateRoots = append(stateRoots, sttRoot)
+		}
+		return nil
+	}, func() {})
+	if err != nil {
+		return nil, err
+	}
+	return stateRoots, nil
+}
+
+// GetStateRootsByOutputIndex returns the outputs proposed to the L2OutputOracle at the
+// L2 output index in the order they were proposed, including the deleted ones
+func (s *OpStateRootStore) GetStateRootsByOutputIndex(l2OutputIndex uint64) ([]*types.StateRoot, error) {
+	var stateRoots []*types.StateRoot
+	err := s.IterateStateRoots(l2OutputIndex, l2OutputIndex, func(stateRoot *types.StateRoot) error {
+		stateRoots = append(stateRoots, stateRoot)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stateRoots, nil
+}
+
+// IterateStateRoots calls fn with the outputs of the L2OutputOracle whose index is in
+// [fromIndex, toIndex] ordered by index and by proposal, the iteration stops at the
+// first error returned by fn
+func (s *OpStateRootStore) IterateStateRoots(fromIndex, toIndex uint64, fn func(stateRoot *types.StateRoot) error) error {
+	return s.db.View(func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(StateRootByOutputIndexBucketName)
+		if bucket == nil {
+			return ErrCorruptedBlockHeaderDb
+		}
+
+		c := bucket.ReadCursor()
+		for k, _ := c.Seek(getOutputIndexKey(fromIndex, 0)); k != nil; k, _ = c.Next() {
+			if binary.BigEndian.Uint64(k) > toIndex {
+				break
+			}
+			sttRoot, err := getIndexedStateRoot(tx, indexKindOutput, outputRecordKey(k))
+			if err != nil {
+				return err
+			}
+			if err := fn(sttRoot); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, func() {})
+}
+
+// IterateDisputeGames calls fn with the dispute games whose index is in [fromIndex, toIndex]
+// ordered by index, the iteration stops at the first error returned by fn
+func (s *OpStateRootStore) IterateDisputeGames(fromIndex, toIndex uint64, fn func(stateRoot *types.StateRoot) error) error {
+	return s.db.View(func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(DisputeGameBucketName)
+		if bucket == nil {
+			return ErrCorruptedBlockHeaderDb
+		}
+
+		c := bucket.ReadCursor()
+		for k, v := c.Seek(getDisputeGameKey(fromIndex)); k != nil; k, v = c.Next() {
+			if binary.BigEndian.Uint64(k) > toIndex {
+				break
+			}
+			sttRoot := &types.StateRoot{}
+			if err := json.Unmarshal(v, sttRoot); err != nil {
+				return err
+			}
+			if err := fn(sttRoot); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, func() {})
+}
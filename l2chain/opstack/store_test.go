@@ -0,0 +1,454 @@
+package opstack_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ctypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Manta-Network/manta-fp/l2chain/opstack"
+	"github.com/Manta-Network/manta-fp/types"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T) *opstack.OpStateRootStore {
+	dbBackend, err := kvdb.GetBoltBackend(&kvdb.BoltBackendConfig{
+		DBPath:     t.TempDir(),
+		DBFileName: "test.db",
+		DBTimeout:  kvdb.DefaultDBTimeout,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { dbBackend.Close() })
+
+	ss, err := opstack.NewOpStateRootStore(dbBackend)
+	require.NoError(t, err)
+	return ss
+}
+
+func testOutput(l1BlockNumber, l2OutputIndex int64, stateRoot byte) *types.StateRoot {
+	return &types.StateRoot{
+		StateRoot:     [32]byte{stateRoot},
+		L2BlockNumber: big.NewInt(100 * l2OutputIndex),
+		L2OutputIndex: big.NewInt(l2OutputIndex),
+		L1BlockNumber: uint64(l1BlockNumber),
+	}
+}
+
+func TestDisputeGameRollback(t *testing.T) {
+	t.Parallel()
+
+	ss := newTestStore(t)
+
+	// an output and a game proposed in the same L1 block don't collide
+	err := ss.SaveStateRoot(testOutput(10, 0, 1))
+	require.NoError(t, err)
+	for i, l1BlockNumber := range []uint64{10, 11} {
+		err = ss.SaveDisputeGame(&types.StateRoot{
+			StateRoot:        [32]byte{2},
+			L2BlockNumber:    big.NewInt(100),
+			L2OutputIndex:    big.NewInt(int64(i)),
+			L1BlockNumber:    l1BlockNumber,
+			DisputeGameType:  1,
+			DisputeGameProxy: common.HexToAddress("0x10"),
+		})
+		require.NoError(t, err)
+	}
+
+	err = ss.SaveDisputeGame(&types.StateRoot{L2OutputIndex: big.NewInt(0)})
+	require.ErrorIs(t, err, opstack.ErrDuplicateDisputeGame)
+
+	game, err := ss.GetDisputeGame(0)
+	require.NoError(t, err)
+	require.True(t, game.IsDisputeGame())
+	require.Equal(t, [32]byte{2}, game.StateRoot)
+
+	// only the game proposed after the fork point is rolled back
+	deleted, err := ss.DeleteStateRootsAfter(big.NewInt(10))
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	require.Equal(t, big.NewInt(1), deleted[0].L2OutputIndex)
+
+	game, err = ss.GetDisputeGame(1)
+	require.NoError(t, err)
+	require.Nil(t, game)
+	stateRoot, err := ss.GetStateRoot(big.NewInt(10), big.NewInt(0))
+	require.NoError(t, err)
+	require.NotNil(t, stateRoot)
+	game, err = ss.GetDisputeGame(0)
+	require.NoError(t, err)
+	require.NotNil(t, game)
+}
+
+func TestMarkOutputsDeleted(t *testing.T) {
+	t.Parallel()
+
+	ss := newTestStore(t)
+
+	for i := int64(0); i < 3; i++ {
+		err := ss.SaveStateRoot(testOutput(10+i, i, byte(i)))
+		require.NoError(t, err)
+	}
+
+	// the outputs 1 and 2 are deleted at L1 block 20
+	outputsDeleted := &types.OutputsDeleted{
+		PrevNextOutputIndex: big.NewInt(3),
+		NewNextOutputIndex:  big.NewInt(1),
+		L1BlockNumber:       20,
+	}
+	err := ss.MarkOutputsDeleted(outputsDeleted)
+	require.NoError(t, err)
+	require.Len(t, outputsDeleted.StateRoots, 2)
+	for _, stateRoot := range outputsDeleted.StateRoots {
+		require.True(t, stateRoot.Deleted)
+		require.Equal(t, uint64(20), stateRoot.DeletedL1BlockNumber)
+	}
+
+	deleted, err := ss.IsOutputDeleted(big.NewInt(0), [32]byte{0})
+	require.NoError(t, err)
+	require.False(t, deleted)
+	deleted, err = ss.IsOutputDeleted(big.NewInt(1), [32]byte{1})
+	require.NoError(t, err)
+	require.True(t, deleted)
+
+	// the output proposed again at a deleted index isn't deleted
+	err = ss.SaveStateRoot(testOutput(21, 1, 9))
+	require.NoError(t, err)
+	deleted, err = ss.IsOutputDeleted(big.NewInt(1), [32]byte{9})
+	require.NoError(t, err)
+	require.False(t, deleted)
+
+	stateRoot, err := ss.GetStateRoot(big.NewInt(12), big.NewInt(2))
+	require.NoError(t, err)
+	require.True(t, stateRoot.Deleted)
+
+	// the deletion is restored once it's reorged out
+	_, err = ss.DeleteStateRootsAfter(big.NewInt(15))
+	require.NoError(t, err)
+	deleted, err = ss.IsOutputDeleted(big.NewInt(1), [32]byte{1})
+	require.NoError(t, err)
+	require.False(t, deleted)
+	stateRoot, err = ss.GetStateRoot(big.NewInt(12), big.NewInt(2))
+	require.NoError(t, err)
+	require.False(t, stateRoot.Deleted)
+}
+
+func TestEventJournal(t *testing.T) {
+	t.Parallel()
+
+	ss := newTestStore(t)
+
+	for i := int64(0); i < 3; i++ {
+		err := ss.SaveStateRoot(testOutput(10+i, i, byte(i)))
+		require.NoError(t, err)
+	}
+	err := ss.MarkOutputsDeleted(&types.OutputsDeleted{
+		PrevNextOutputIndex: big.NewInt(3),
+		NewNextOutputIndex:  big.NewInt(2),
+		L1BlockNumber:       13,
+	})
+	require.NoError(t, err)
+
+	events, err := ss.GetEvents(0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	for i, event := range events {
+		require.Equal(t, uint64(i), event.Seq)
+	}
+	require.Equal(t, opstack.EventOutputProposed, events[0].Type)
+	require.Equal(t, opstack.EventOutputsDeleted, events[3].Type)
+	require.Len(t, events[3].OutputsDeleted.StateRoots, 1)
+
+	events, err = ss.GetEvents(1, 2)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, uint64(1), events[0].Seq)
+
+	seq, err := ss.FirstEventSeqAfter(11)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), seq)
+
+	// the cursors past the reorged events are moved back to the end of the journal
+	require.NoError(t, ss.SaveCursor("behind", 1))
+	require.NoError(t, ss.SaveCursor("ahead", 4))
+	_, found, err := ss.GetCursor("unknown")
+	require.NoError(t, err)
+	require.False(t, found)
+
+	_, err = ss.DeleteStateRootsAfter(big.NewInt(11))
+	require.NoError(t, err)
+
+	next, err := ss.NextEventSeq()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), next)
+	cursor, found, err := ss.GetCursor("behind")
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, uint64(1), cursor)
+	cursor, _, err = ss.GetCursor("ahead")
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), cursor)
+
+	// the canonical events are journaled again after the fork point
+	err = ss.SaveStateRoot(testOutput(12, 2, 7))
+	require.NoError(t, err)
+	events, err = ss.GetEvents(2, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, [32]byte{7}, events[0].StateRoot.StateRoot)
+}
+
+func TestPruneEvents(t *testing.T) {
+	t.Parallel()
+
+	ss := newTestStore(t)
+	for i := int64(0); i < 5; i++ {
+		require.NoError(t, ss.SaveStateRoot(testOutput(10+i, i, byte(i))))
+	}
+
+	// nothing is pruned until the reorg window and a subscriber are known
+	pruned, err := ss.PruneEvents()
+	require.NoError(t, err)
+	require.Zero(t, pruned)
+	require.NoError(t, ss.AddTraversedHeaders([]ctypes.Header{{Number: big.NewInt(13)}, {Number: big.NewInt(14)}}, 10))
+	pruned, err = ss.PruneEvents()
+	require.NoError(t, err)
+	require.Zero(t, pruned)
+
+	// the events below the lowest cursor are pruned
+	require.NoError(t, ss.SaveCursor("behind", 1))
+	require.NoError(t, ss.SaveCursor("ahead", 5))
+	pruned, err = ss.PruneEvents()
+	require.NoError(t, err)
+	require.Equal(t, 1, pruned)
+	events, err := ss.GetEvents(0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+	require.Equal(t, uint64(1), events[0].Seq)
+
+	// the events in the reorg window are kept
+	require.NoError(t, ss.SaveCursor("behind", 5))
+	pruned, err = ss.PruneEvents()
+	require.NoError(t, err)
+	require.Equal(t, 2, pruned)
+	events, err = ss.GetEvents(0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Equal(t, uint64(13), events[0].L1BlockNumber)
+
+	// the last event is kept so that the sequence numbers keep increasing
+	require.NoError(t, ss.AddTraversedHeaders([]ctypes.Header{{Number: big.NewInt(20)}}, 1))
+	pruned, err = ss.PruneEvents()
+	require.NoError(t, err)
+	require.Equal(t, 1, pruned)
+	next, err := ss.NextEventSeq()
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), next)
+}
+
+func TestStateRootIndexes(t *testing.T) {
+	t.Parallel()
+
+	ss := newTestStore(t)
+
+	// output 1 is proposed twice, the first proposal is deleted
+	for i, output := range []*types.StateRoot{
+		testOutput(10, 0, 1),
+		testOutput(11, 1, 2),
+		testOutput(13, 1, 3),
+		testOutput(14, 2, 4),
+	} {
+		err := ss.SaveStateRoot(output)
+		require.NoError(t, err)
+		if i == 1 {
+			err = ss.MarkOutputsDeleted(&types.OutputsDeleted{
+				PrevNextOutputIndex: big.NewInt(2),
+				NewNextOutputIndex:  big.NewInt(1),
+				L1BlockNumber:       12,
+			})
+			require.NoError(t, err)
+		}
+	}
+	err := ss.SaveDisputeGame(&types.StateRoot{
+		StateRoot:        [32]byte{5},
+		L2BlockNumber:    big.NewInt(100),
+		L2OutputIndex:    big.NewInt(0),
+		L1BlockNumber:    14,
+		DisputeGameType:  1,
+		DisputeGameProxy: common.HexToAddress("0x10"),
+	})
+	require.NoError(t, err)
+
+	outputs, err := ss.GetStateRootsByOutputIndex(1)
+	require.NoError(t, err)
+	require.Len(t, outputs, 2)
+	require.Equal(t, uint64(11), outputs[0].L1BlockNumber)
+	require.True(t, outputs[0].Deleted)
+	require.Equal(t, uint64(13), outputs[1].L1BlockNumber)
+	require.False(t, outputs[1].Deleted)
+
+	// the outputs and the games of an L2 block are both returned
+	outputs, err = ss.GetStateRootsByL2Block(100)
+	require.NoError(t, err)
+	require.Len(t, outputs, 3)
+	require.False(t, outputs[0].IsDisputeGame())
+	require.True(t, outputs[2].IsDisputeGame())
+
+	var indexes []int64
+	err = ss.IterateStateRoots(1, 2, func(stateRoot *types.StateRoot) error {
+		indexes = append(indexes, stateRoot.L2OutputIndex.Int64())
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{1, 1, 2}, indexes)
+
+	// the rolled back outputs are removed from the indexes
+	_, err = ss.DeleteStateRootsAfter(big.NewInt(13))
+	require.NoError(t, err)
+	outputs, err = ss.GetStateRootsByOutputIndex(2)
+	require.NoError(t, err)
+	require.Empty(t, outputs)
+	outputs, err = ss.GetStateRootsByL2Block(100)
+	require.NoError(t, err)
+	require.Len(t, outputs, 2)
+
+	err = ss.AddBlock(big.NewInt(13), common.Hash{1}, common.Hash{2}, 1700000000)
+	require.NoError(t, err)
+	block, err := ss.GetBlock(big.NewInt(13))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1700000000), block.Timestamp)
+	_, err = ss.GetBlock(big.NewInt(14))
+	require.ErrorIs(t, err, opstack.ErrBlockNotFound)
+}
+
+func TestOutputsProposedInOneBlock(t *testing.T) {
+	t.Parallel()
+
+	ss := newTestStore(t)
+
+	// both outputs proposed in the L1 block are kept, the replayed one is a duplicate
+	for i := int64(0); i < 2; i++ {
+		err := ss.SaveStateRoot(testOutput(10, i, byte(i+1)))
+		require.NoError(t, err)
+	}
+	err := ss.SaveStateRoot(testOutput(10, 1, 2))
+	require.ErrorIs(t, err, opstack.ErrDuplicateStateRoot)
+
+	for i := int64(0); i < 2; i++ {
+		stateRoot, err := ss.GetStateRoot(big.NewInt(10), big.NewInt(i))
+		require.NoError(t, err)
+		require.Equal(t, [32]byte{byte(i + 1)}, stateRoot.StateRoot)
+	}
+	outputs, err := ss.GetStateRootsByOutputIndex(1)
+	require.NoError(t, err)
+	require.Len(t, outputs, 1)
+	require.Equal(t, uint64(10), outputs[0].L1BlockNumber)
+
+	// the second output is deleted only
+	err = ss.MarkOutputsDeleted(&types.OutputsDeleted{
+		PrevNextOutputIndex: big.NewInt(2),
+		NewNextOutputIndex:  big.NewInt(1),
+		L1BlockNumber:       11,
+	})
+	require.NoError(t, err)
+	deleted, err := ss.IsOutputDeleted(big.NewInt(0), [32]byte{1})
+	require.NoError(t, err)
+	require.False(t, deleted)
+	deleted, err = ss.IsOutputDeleted(big.NewInt(1), [32]byte{2})
+	require.NoError(t, err)
+	require.True(t, deleted)
+
+	rolledBack, err := ss.DeleteStateRootsAfter(big.NewInt(9))
+	require.NoError(t, err)
+	require.Len(t, rolledBack, 2)
+}
+
+func TestMigrateStateRootKeys(t *testing.T) {
+	t.Parallel()
+
+	dbBackend, err := kvdb.GetBoltBackend(&kvdb.BoltBackendConfig{
+		DBPath:     t.TempDir(),
+		DBFileName: "test.db",
+		DBTimeout:  kvdb.DefaultDBTimeout,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { dbBackend.Close() })
+
+	// the outputs used to be keyed by their L1 block only, and so were the indexes
+	err = kvdb.Batch(dbBackend, func(tx kvdb.RwTx) error {
+		bucket, err := tx.CreateTopLevelBucket(opstack.StateRootBucketName)
+		if err != nil {
+			return err
+		}
+		l2BlockBucket, err := tx.CreateTopLevelBucket(opstack.StateRootByL2BlockBucketName)
+		if err != nil {
+			return err
+		}
+		if err := l2BlockBucket.Put(make([]byte, 17), []byte{}); err != nil {
+			return err
+		}
+		for i := int64(0); i < 2; i++ {
+			output := testOutput(10+i, i, byte(i+1))
+			output.L1BlockNumber = 0
+			v, err := json.Marshal(output)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put(big.NewInt(10+i).Bytes(), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	ss, err := opstack.NewOpStateRootStore(dbBackend)
+	require.NoError(t, err)
+	stateRoot, err := ss.GetStateRoot(big.NewInt(11), big.NewInt(1))
+	require.NoError(t, err)
+	require.Equal(t, [32]byte{2}, stateRoot.StateRoot)
+	require.Equal(t, uint64(11), stateRoot.L1BlockNumber)
+	outputs, err := ss.GetStateRootsByL2Block(100)
+	require.NoError(t, err)
+	require.Len(t, outputs, 1)
+	require.Equal(t, uint64(11), outputs[0].L1BlockNumber)
+}
+
+func TestTraversedHeaders(t *testing.T) {
+	t.Parallel()
+	ss := newTestStore(t)
+
+	var headers []ctypes.Header
+	parent := common.Hash{}
+	for i := int64(1); i <= 10; i++ {
+		header := ctypes.Header{ParentHash: parent, Number: big.NewInt(i)}
+		parent = header.Hash()
+		headers = append(headers, header)
+	}
+
+	// only the latest headers are kept and the last one is the latest block
+	require.NoError(t, ss.AddTraversedHeaders(headers[:6], 4))
+	require.NoError(t, ss.AddTraversedHeaders(headers[6:], 4))
+	latestBlock, err := ss.GetLatestBlock()
+	require.NoError(t, err)
+	require.Equal(t, int64(10), latestBlock.Int64())
+
+	tracked, err := ss.GetTraversedHeaders()
+	require.NoError(t, err)
+	require.Len(t, tracked, 4)
+	for i, header := range tracked {
+		require.Equal(t, headers[6+i].Number, header.Number)
+		require.Equal(t, headers[6+i].Hash(), header.Hash)
+	}
+
+	// the rolled back headers are deleted
+	_, err = ss.DeleteStateRootsAfter(big.NewInt(8))
+	require.NoError(t, err)
+	tracked, err = ss.GetTraversedHeaders()
+	require.NoError(t, err)
+	require.Len(t, tracked, 2)
+	require.Equal(t, int64(8), tracked[1].Number.Int64())
+}
@@ -0,0 +1,238 @@
+package opstack
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Manta-Network/manta-fp/types"
+
+	"go.uber.org/zap"
+)
+
+// subscriptionBatchSize is the number of journal events read at once
+const subscriptionBatchSize = 100
+
+// Subscription delivers the journal events to a consumer of the indexer. The cursor of
+// the consumer is persisted once the consumer acknowledges the delivered events, so that
+// the consumer resumes after the last event it handled on restart
+type Subscription struct {
+	name    string
+	sRStore *OpStateRootStore
+	logger  *zap.Logger
+
+	mu      sync.Mutex
+	cursor  uint64
+	rewound bool
+	// acked is the persisted cursor, the events delivered from it are kept in order in
+	// unacked until they are acknowledged
+	acked   uint64
+	unacked []uint64
+	ackSeqs map[uint64]struct{}
+
+	pollInterval       time.Duration
+	blockInfoChan      chan *types.BlockInfo
+	outputsDeletedChan chan *types.OutputsDeleted
+	notifyChan         chan struct{}
+	onClose            func()
+
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+	quit      chan struct{}
+}
+
+func newSubscription(
+	name string,
+	cursor uint64,
+	bufferSize uint32,
+	pollInterval time.Duration,
+	sRStore *OpStateRootStore,
+	logger *zap.Logger,
+	onClose func(),
+) *Subscription {
+	return &Subscription{
+		name:               name,
+		sRStore:            sRStore,
+		logger:             logger.With(zap.String("subscriber", name)),
+		cursor:             cursor,
+		acked:              cursor,
+		ackSeqs:            make(map[uint64]struct{}),
+		pollInterval:       pollInterval,
+		blockInfoChan:      make(chan *types.BlockInfo, bufferSize),
+		outputsDeletedChan: make(chan *types.OutputsDeleted, bufferSize),
+		notifyChan:         make(chan struct{}, 1),
+		onClose:            onClose,
+		quit:               make(chan struct{}),
+	}
+}
+
+// GetBlockInfoChan returns the outputs and the dispute games in the order they are indexed
+func (s *Subscription) GetBlockInfoChan() <-chan *types.BlockInfo {
+	return s.blockInfoChan
+}
+
+// GetOutputsDeletedChan returns the outputs deleted from the L2OutputOracle
+func (s *Subscription) GetOutputsDeletedChan() <-chan *types.OutputsDeleted {
+	return s.outputsDeletedChan
+}
+
+// Ack acknowledges that the consumer handled the event of the sequence number, the cursor
+// is persisted past every event delivered before it once they are all acknowledged
+func (s *Subscription) Ack(seq uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delivered := false
+	for _, unacked := range s.unacked {
+		if unacked == seq {
+			delivered = true
+			break
+		}
+	}
+	if !delivered {
+		// the event was rewound by a reorg or already acknowledged
+		return
+	}
+	s.ackSeqs[seq] = struct{}{}
+
+	acked := s.acked
+	for len(s.unacked) > 0 {
+		if _, ok := s.ackSeqs[s.unacked[0]]; !ok {
+			break
+		}
+		delete(s.ackSeqs, s.unacked[0])
+		acked = s.unacked[0] + 1
+		s.unacked = s.unacked[1:]
+	}
+	if acked == s.acked {
+		return
+	}
+	s.acked = acked
+	if err := s.sRStore.SaveCursor(s.name, acked); err != nil {
+		s.logger.Error("failed to save the cursor", zap.String("err", err.Error()))
+	}
+}
+
+// Close stops the delivery and unsubscribes from the indexer, the cursor is kept
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		close(s.quit)
+		s.wg.Wait()
+		s.onClose()
+	})
+}
+
+func (s *Subscription) start() {
+	s.wg.Add(1)
+	go s.deliverLoop()
+}
+
+func (s *Subscription) notify() {
+	select {
+	case s.notifyChan <- struct{}{}:
+	default:
+	}
+}
+
+// rewind moves the cursor back to the end of the journal truncated by a reorg
+func (s *Subscription) rewind(next uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cursor > next {
+		s.cursor = next
+	}
+	// the events reorged out are never acknowledged
+	for len(s.unacked) > 0 && s.unacked[len(s.unacked)-1] >= next {
+		delete(s.ackSeqs, s.unacked[len(s.unacked)-1])
+		s.unacked = s.unacked[:len(s.unacked)-1]
+	}
+	if s.acked > next {
+		s.acked = next
+		if err := s.sRStore.SaveCursor(s.name, next); err != nil {
+			s.logger.Error("failed to save the cursor", zap.String("err", err.Error()))
+		}
+	}
+	s.rewound = true
+}
+
+func (s *Subscription) deliverLoop() {
+	defer s.wg.Done()
+deliver:
+	for {
+		s.mu.Lock()
+		cursor := s.cursor
+		s.rewound = false
+		s.mu.Unlock()
+
+		events, err := s.sRStore.GetEvents(cursor, subscriptionBatchSize)
+		if err != nil {
+			s.logger.Error("failed to read the indexed events", zap.String("err", err.Error()))
+		}
+		for _, event := range events {
+			if !s.track(event.Seq) {
+				continue deliver
+			}
+			if !s.deliver(event) {
+				return
+			}
+			if !s.advance(event.Seq + 1) {
+				// the journal was truncated while delivering, read it again from the cursor
+				continue deliver
+			}
+		}
+		if len(events) == subscriptionBatchSize {
+			continue
+		}
+
+		select {
+		case <-s.notifyChan:
+		case <-time.After(s.pollInterval):
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// deliver sends the event to the consumer, false is returned if the subscription is closed
+func (s *Subscription) deliver(event *IndexedEvent) bool {
+	switch event.Type {
+	case EventOutputProposed, EventDisputeGameCreated:
+		select {
+		case s.blockInfoChan <- blockInfo(event):
+		case <-s.quit:
+			return false
+		}
+	case EventOutputsDeleted:
+		event.OutputsDeleted.Seq = event.Seq
+		select {
+		case s.outputsDeletedChan <- event.OutputsDeleted:
+		case <-s.quit:
+			return false
+		}
+	}
+	return true
+}
+
+// track records the event as delivered before it's sent, so that the consumer can
+// acknowledge it as soon as it's received, false is returned if the subscription was
+// rewound meanwhile
+func (s *Subscription) track(seq uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rewound {
+		return false
+	}
+	s.unacked = append(s.unacked, seq)
+	return true
+}
+
+// advance moves the delivery cursor past the delivered event unless the subscription was
+// rewound meanwhile, the persisted cursor is only moved by Ack
+func (s *Subscription) advance(cursor uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rewound {
+		return false
+	}
+	s.cursor = cursor
+	return true
+}
@@ -17,6 +17,11 @@ type FpMetrics struct {
 	babylonTipHeight     prometheus.Gauge
 	lastPolledHeight     prometheus.Gauge
 	pollerStartingHeight prometheus.Gauge
+	pollerHeadHeight     *prometheus.GaugeVec
+	pollerConfDepth      prometheus.Gauge
+	pollerLag            prometheus.Gauge
+	// output verifier metrics
+	outputRootMismatches prometheus.Counter
 	// single finality provider metrics
 	fpStatus                        *prometheus.GaugeVec
 	fpSecondsSinceLastVote          *prometheus.GaugeVec
@@ -29,6 +34,7 @@ type FpMetrics struct {
 	fpTotalCommittedRandomness      *prometheus.GaugeVec
 	fpTotalFailedVotes              *prometheus.CounterVec
 	fpTotalFailedRandomness         *prometheus.CounterVec
+	fpTotalVotedDeletedOutputs      *prometheus.CounterVec
 	// time keeper
 	mu                     sync.Mutex
 	previousVoteByFp       map[string]*time.Time
@@ -65,6 +71,22 @@ func NewFpMetrics() *FpMetrics {
 				Name: "poller_starting_height",
 				Help: "The initial block height when the poller started operation",
 			}),
+			pollerHeadHeight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "poller_head_height",
+				Help: "The height of the L1 head the poller polls up to, labeled by the chosen head mode",
+			}, []string{"head_mode"}),
+			pollerConfDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+				Name: "poller_confirmation_depth",
+				Help: "The number of blocks below the head that the poller does not poll yet",
+			}),
+			pollerLag: prometheus.NewGauge(prometheus.GaugeOpts{
+				Name: "poller_lag_blocks",
+				Help: "The number of blocks the last polled block is behind the L1 head",
+			}),
+			outputRootMismatches: prometheus.NewCounter(prometheus.CounterOpts{
+				Name: "output_root_mismatches_total",
+				Help: "The total number of proposed output roots that don't match the L2 node",
+			}),
 			fpSecondsSinceLastVote: prometheus.NewGaugeVec(
 				prometheus.GaugeOpts{
 					Name: "fp_seconds_since_last_vote",
@@ -135,6 +157,13 @@ func NewFpMetrics() *FpMetrics {
 				},
 				[]string{"fp_btc_pk_hex"},
 			),
+			fpTotalVotedDeletedOutputs: prometheus.NewCounterVec(
+				prometheus.CounterOpts{
+					Name: "fp_total_voted_deleted_outputs",
+					Help: "The total number of outputs voted by a finality provider and deleted from the L2OutputOracle afterwards.",
+				},
+				[]string{"fp_btc_pk_hex"},
+			),
 			mu: sync.Mutex{},
 		}
 
@@ -144,6 +173,10 @@ func NewFpMetrics() *FpMetrics {
 		prometheus.MustRegister(fpMetricsInstance.babylonTipHeight)
 		prometheus.MustRegister(fpMetricsInstance.lastPolledHeight)
 		prometheus.MustRegister(fpMetricsInstance.pollerStartingHeight)
+		prometheus.MustRegister(fpMetricsInstance.pollerHeadHeight)
+		prometheus.MustRegister(fpMetricsInstance.pollerConfDepth)
+		prometheus.MustRegister(fpMetricsInstance.pollerLag)
+		prometheus.MustRegister(fpMetricsInstance.outputRootMismatches)
 		prometheus.MustRegister(fpMetricsInstance.fpSecondsSinceLastVote)
 		prometheus.MustRegister(fpMetricsInstance.fpSecondsSinceLastRandomness)
 		prometheus.MustRegister(fpMetricsInstance.fpLastVotedHeight)
@@ -154,6 +187,7 @@ func NewFpMetrics() *FpMetrics {
 		prometheus.MustRegister(fpMetricsInstance.fpLastCommittedRandomnessHeight)
 		prometheus.MustRegister(fpMetricsInstance.fpTotalFailedVotes)
 		prometheus.MustRegister(fpMetricsInstance.fpTotalFailedRandomness)
+		prometheus.MustRegister(fpMetricsInstance.fpTotalVotedDeletedOutputs)
 	})
 	return fpMetricsInstance
 }
@@ -188,6 +222,26 @@ func (fm *FpMetrics) RecordPollerStartingHeight(height uint64) {
 	fm.pollerStartingHeight.Set(float64(height))
 }
 
+// RecordPollerHeadHeight records the height of the L1 head the poller polls up to
+func (fm *FpMetrics) RecordPollerHeadHeight(headMode string, height uint64) {
+	fm.pollerHeadHeight.WithLabelValues(headMode).Set(float64(height))
+}
+
+// RecordPollerConfirmationDepth records the number of blocks below the head that the poller does not poll yet
+func (fm *FpMetrics) RecordPollerConfirmationDepth(depth uint64) {
+	fm.pollerConfDepth.Set(float64(depth))
+}
+
+// RecordPollerLag records the number of blocks the last polled block is behind the L1 head
+func (fm *FpMetrics) RecordPollerLag(lag uint64) {
+	fm.pollerLag.Set(float64(lag))
+}
+
+// RecordOutputRootMismatch records a proposed output root that doesn't match the L2 node
+func (fm *FpMetrics) RecordOutputRootMismatch() {
+	fm.outputRootMismatches.Inc()
+}
+
 // RecordFpSecondsSinceLastVote records the seconds since the last finality sig vote by a finality provider
 func (fm *FpMetrics) RecordFpSecondsSinceLastVote(fpBtcPkHex string, seconds float64) {
 	fm.fpSecondsSinceLastVote.WithLabelValues(fpBtcPkHex).Set(seconds)
@@ -243,6 +297,11 @@ func (fm *FpMetrics) IncrementFpTotalFailedRandomness(fpBtcPkHex string) {
 	fm.fpTotalFailedRandomness.WithLabelValues(fpBtcPkHex).Inc()
 }
 
+// IncrementFpTotalVotedDeletedOutputs increments the total number of voted outputs deleted from the L2OutputOracle
+func (fm *FpMetrics) IncrementFpTotalVotedDeletedOutputs(fpBtcPkHex string) {
+	fm.fpTotalVotedDeletedOutputs.WithLabelValues(fpBtcPkHex).Inc()
+}
+
 // RecordFpVoteTime records the time of a finality sig vote by a finality provider
 func (fm *FpMetrics) RecordFpVoteTime(fpBtcPkHex string) {
 	fm.mu.Lock()
@@ -0,0 +1,218 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+	"go.uber.org/zap"
+)
+
+const (
+	// HealthzPath reports whether the daemon is alive, it fails when a component is stuck
+	HealthzPath = "/healthz"
+	// ReadyzPath reports whether the daemon is ready to sign, it fails when a component
+	// is stuck or a dependency is unreachable
+	ReadyzPath = "/readyz"
+
+	defaultHealthCheckTimeout = 5 * time.Second
+	minSubmissionTimeout      = 5 * time.Minute
+)
+
+// HealthCheck returns the detail of a component, the component is unhealthy if an
+// error is returned
+type HealthCheck func(ctx context.Context) (string, error)
+
+// ComponentHealth is the health of a single component in the health report
+type ComponentHealth struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Detail  string `json:"detail,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// HealthReport is the response of the health endpoints, the daemon is healthy only if
+// all the components are healthy
+type HealthReport struct {
+	Healthy    bool              `json:"healthy"`
+	Components []ComponentHealth `json:"components"`
+}
+
+type namedCheck struct {
+	name  string
+	check HealthCheck
+}
+
+type healthChecks struct {
+	mu        sync.RWMutex
+	liveness  []namedCheck
+	readiness []namedCheck
+	timeout   time.Duration
+	logger    *zap.Logger
+}
+
+// AddLivenessCheck adds a check to both /healthz and /readyz, the liveness checks should
+// only fail when the daemon is stuck and needs a restart
+func (s *Server) AddLivenessCheck(name string, check HealthCheck) {
+	s.health.mu.Lock()
+	defer s.health.mu.Unlock()
+	s.health.liveness = append(s.health.liveness, namedCheck{name: name, check: check})
+}
+
+// AddReadinessCheck adds a check to /readyz only, the readiness checks fail while a
+// dependency of the daemon is unavailable
+func (s *Server) AddReadinessCheck(name string, check HealthCheck) {
+	s.health.mu.Lock()
+	defer s.health.mu.Unlock()
+	s.health.readiness = append(s.health.readiness, namedCheck{name: name, check: check})
+}
+
+func (h *healthChecks) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	checks := append([]namedCheck{}, h.liveness...)
+	h.mu.RUnlock()
+	h.writeReport(w, h.run(r.Context(), checks))
+}
+
+func (h *healthChecks) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	h.mu.RLock()
+	checks := append(append([]namedCheck{}, h.liveness...), h.readiness...)
+	h.mu.RUnlock()
+	h.writeReport(w, h.run(r.Context(), checks))
+}
+
+// run runs the checks concurrently, a check that doesn't return within the timeout is unhealthy
+func (h *healthChecks) run(ctx context.Context, checks []namedCheck) *HealthReport {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	report := &HealthReport{Healthy: true, Components: make([]ComponentHealth, len(checks))}
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c namedCheck) {
+			defer wg.Done()
+			report.Components[i] = runCheck(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, component := range report.Components {
+		if !component.Healthy {
+			report.Healthy = false
+		}
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, c namedCheck) ComponentHealth {
+	type result struct {
+		detail string
+		err    error
+	}
+	resChan := make(chan result, 1)
+	go func() {
+		detail, err := c.check(ctx)
+		resChan <- result{detail: detail, err: err}
+	}()
+
+	component := ComponentHealth{Name: c.name}
+	select {
+	case res := <-resChan:
+		component.Healthy = res.err == nil
+		component.Detail = res.detail
+		if res.err != nil {
+			component.Error = res.err.Error()
+		}
+	case <-ctx.Done():
+		component.Error = fmt.Sprintf("the check timed out: %v", ctx.Err())
+	}
+	return component
+}
+
+func (h *healthChecks) writeReport(w http.ResponseWriter, report *HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	if !report.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		h.logger.Error("failed to write the health report", zap.String("err", err.Error()))
+	}
+}
+
+// DBHealthCheck checks that the database is open
+func DBHealthCheck(db kvdb.Backend) HealthCheck {
+	return func(_ context.Context) (string, error) {
+		if err := db.View(func(tx kvdb.RTx) error { return nil }, func() {}); err != nil {
+			return "", fmt.Errorf("the database is not available: %w", err)
+		}
+		return "open", nil
+	}
+}
+
+// SubmissionTracker reports a stuck submission loop, the blocks received for signing
+// must be submitted within the timeout
+type SubmissionTracker struct {
+	timeout time.Duration
+
+	mu             sync.Mutex
+	pendingSince   time.Time
+	lastSubmission time.Time
+}
+
+func NewSubmissionTracker(timeout time.Duration) *SubmissionTracker {
+	return &SubmissionTracker{timeout: timeout}
+}
+
+// Pending records that blocks are waiting to be submitted, the earliest pending time is kept
+func (t *SubmissionTracker) Pending() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.pendingSince.IsZero() {
+		t.pendingSince = time.Now()
+	}
+}
+
+// Submitted records that all the pending blocks are submitted
+func (t *SubmissionTracker) Submitted() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pendingSince = time.Time{}
+	t.lastSubmission = time.Now()
+}
+
+// Skipped records that the pending blocks no longer need a submission
+func (t *SubmissionTracker) Skipped() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pendingSince = time.Time{}
+}
+
+// Check fails when blocks are pending for longer than the timeout
+func (t *SubmissionTracker) Check(_ context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	detail := "no submission yet"
+	if !t.lastSubmission.IsZero() {
+		detail = fmt.Sprintf("last submission %s ago", time.Since(t.lastSubmission).Truncate(time.Second))
+	}
+	if !t.pendingSince.IsZero() && time.Since(t.pendingSince) > t.timeout {
+		return detail, fmt.Errorf("blocks are pending submission for %s", time.Since(t.pendingSince).Truncate(time.Second))
+	}
+	return detail, nil
+}
+
+// SubmissionTimeout is how long blocks may be pending before the submission loop is
+// reported stuck, it covers a full cycle of retries twice with a min of minSubmissionTimeout
+func SubmissionTimeout(submissionInterval, retryInterval time.Duration, maxRetries uint32) time.Duration {
+	timeout := 2 * (submissionInterval + retryInterval*time.Duration(maxRetries+1))
+	if timeout < minSubmissionTimeout {
+		timeout = minSubmissionTimeout
+	}
+	return timeout
+}
@@ -0,0 +1,41 @@
+package metrics_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Manta-Network/manta-fp/metrics"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmissionTracker(t *testing.T) {
+	tracker := metrics.NewSubmissionTracker(50 * time.Millisecond)
+	detail, err := tracker.Check(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "no submission yet", detail)
+
+	// the blocks pending for longer than the timeout fail the check until submitted
+	tracker.Pending()
+	time.Sleep(100 * time.Millisecond)
+	tracker.Pending()
+	_, err = tracker.Check(context.Background())
+	require.Error(t, err)
+
+	tracker.Submitted()
+	detail, err = tracker.Check(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, detail, "last submission")
+
+	tracker.Pending()
+	time.Sleep(100 * time.Millisecond)
+	tracker.Skipped()
+	_, err = tracker.Check(context.Background())
+	require.NoError(t, err)
+}
+
+func TestSubmissionTimeout(t *testing.T) {
+	require.Equal(t, 5*time.Minute, metrics.SubmissionTimeout(time.Second, time.Second, 20))
+	require.Equal(t, 2*(time.Minute+10*time.Minute), metrics.SubmissionTimeout(time.Minute, time.Minute, 9))
+}
@@ -12,12 +12,17 @@ import (
 // Server represents the metrics server.
 type Server struct {
 	httpServer *http.Server
+	mux        *http.ServeMux
+	health     *healthChecks
 	logger     *zap.Logger
 }
 
 func Start(addr string, logger *zap.Logger) *Server {
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", promhttp.Handler())
+	health := &healthChecks{timeout: defaultHealthCheckTimeout, logger: logger}
+	mux.HandleFunc(HealthzPath, health.handleHealthz)
+	mux.HandleFunc(ReadyzPath, health.handleReadyz)
 
 	// Create the HTTP server with the custom ServeMux as the handler
 	server := &http.Server{
@@ -32,6 +37,8 @@ func Start(addr string, logger *zap.Logger) *Server {
 	// Store the logger in the server struct
 	s := &Server{
 		httpServer: server,
+		mux:        mux,
+		health:     health,
 		logger:     logger,
 	}
 
@@ -46,6 +53,12 @@ func Start(addr string, logger *zap.Logger) *Server {
 	return s
 }
 
+// HandleFunc serves the handler along with the metrics, it lets the daemons expose
+// their queries on the metrics address
+func (s *Server) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	s.mux.HandleFunc(pattern, handler)
+}
+
 // Stop gracefully shuts down the metrics server.
 func (s *Server) Stop(ctx context.Context) {
 	s.logger.Info("Stopping metrics server")
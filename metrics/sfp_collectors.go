@@ -0,0 +1,275 @@
+package metrics
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/Manta-Network/manta-fp/types"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DAPathEth is the DA path label of the signatures submitted to the finality signature
+// inbox on ethereum, the other paths are labeled by the DA backend
+const DAPathEth = "eth"
+
+type SfpMetrics struct {
+	// single operator metrics
+	operatorStatus        *prometheus.GaugeVec
+	operatorPaused        *prometheus.GaugeVec
+	walletBalance         *prometheus.GaugeVec
+	lastSignedOutputIndex *prometheus.GaugeVec
+	lastSignedL2Block     *prometheus.GaugeVec
+	signatures            *prometheus.CounterVec
+	daSubmitLatency       *prometheus.HistogramVec
+	daSubmitFailures      *prometheus.CounterVec
+	operatorStake         *prometheus.GaugeVec
+	zeroStakeSkips        *prometheus.CounterVec
+	// poller metrics
+	pollerStartingHeight *prometheus.GaugeVec
+	pollerHeadHeight     *prometheus.GaugeVec
+	pollerConfDepth      *prometheus.GaugeVec
+	pollerLag            *prometheus.GaugeVec
+	// output verifier metrics
+	outputRootMismatches *prometheus.CounterVec
+	// tx manager metrics
+	txAttempts      *prometheus.CounterVec
+	txResubmissions *prometheus.CounterVec
+	txLastAttempt   *prometheus.GaugeVec
+	txGasTipCap     *prometheus.GaugeVec
+	txGasFeeCap     *prometheus.GaugeVec
+	txFeeCapReached *prometheus.CounterVec
+}
+
+// Declare a package-level variable for sync.Once to ensure metrics are registered only once
+var sfpMetricsRegisterOnce sync.Once
+
+// Declare a variable to hold the instance of SfpMetrics
+var sfpMetricsInstance *SfpMetrics
+
+// NewSfpMetrics initializes and registers the symbiotic-fp metrics, using sync.Once to ensure it's done only once
+func NewSfpMetrics() *SfpMetrics {
+	sfpMetricsRegisterOnce.Do(func() {
+		sfpMetricsInstance = &SfpMetrics{
+			operatorStatus: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "sfp_operator_status",
+				Help: "Current status of a symbiotic operator, 0 active, 1 paused and 2 unregistered",
+			}, []string{"operator_address"}),
+			operatorPaused: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "sfp_operator_paused",
+				Help: "Whether the signing of a symbiotic operator is suspended, 1 while the operator is paused or unregistered",
+			}, []string{"operator_address"}),
+			walletBalance: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "sfp_wallet_balance_wei",
+				Help: "The balance of the operator wallet paying for the submissions to ethereum",
+			}, []string{"operator_address"}),
+			lastSignedOutputIndex: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "sfp_last_signed_output_index",
+				Help: "The last L2 output index whose signature was submitted by a symbiotic operator",
+			}, []string{"operator_address"}),
+			lastSignedL2Block: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "sfp_last_signed_l2_block",
+				Help: "The last L2 block whose output signature was submitted by a symbiotic operator",
+			}, []string{"operator_address"}),
+			signatures: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "sfp_signatures_total",
+				Help: "The total number of signatures submitted by a symbiotic operator, labeled by the DA path",
+			}, []string{"operator_address", "da_path"}),
+			daSubmitLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Name:    "sfp_da_submit_latency_seconds",
+				Help:    "The latency of the signature submissions, labeled by the DA path",
+				Buckets: prometheus.ExponentialBuckets(0.25, 2, 10),
+			}, []string{"operator_address", "da_path"}),
+			daSubmitFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "sfp_da_submit_failures_total",
+				Help: "The total number of failed signature submissions, labeled by the DA path",
+			}, []string{"operator_address", "da_path"}),
+			operatorStake: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "sfp_operator_stake",
+				Help: "The active stake delegated to a symbiotic operator through its vault at the latest signed epoch",
+			}, []string{"operator_address"}),
+			zeroStakeSkips: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "sfp_zero_stake_skips_total",
+				Help: "The total number of outputs not signed as the symbiotic operator has no active stake at their epoch",
+			}, []string{"operator_address"}),
+			pollerStartingHeight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "sfp_poller_starting_height",
+				Help: "The initial L1 block height when the poller started operation",
+			}, []string{"operator_address"}),
+			pollerHeadHeight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "sfp_poller_head_height",
+				Help: "The height of the L1 head the poller polls up to, labeled by the chosen head mode",
+			}, []string{"operator_address", "head_mode"}),
+			pollerConfDepth: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "sfp_poller_confirmation_depth",
+				Help: "The number of blocks below the head that the poller does not poll yet",
+			}, []string{"operator_address"}),
+			pollerLag: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "sfp_poller_lag_blocks",
+				Help: "The number of blocks the last polled block is behind the L1 head",
+			}, []string{"operator_address"}),
+			outputRootMismatches: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "sfp_output_root_mismatches_total",
+				Help: "The total number of proposed output roots that don't match the L2 node",
+			}, []string{"operator_address"}),
+			txAttempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "sfp_tx_attempts_total",
+				Help: "The total number of transaction submission attempts including the resubmissions",
+			}, []string{"operator_address"}),
+			txResubmissions: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "sfp_tx_resubmissions_total",
+				Help: "The total number of transaction resubmissions with bumped fees",
+			}, []string{"operator_address"}),
+			txLastAttempt: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "sfp_tx_last_attempt",
+				Help: "The attempt number of the latest transaction submission, 1 for the first submission",
+			}, []string{"operator_address"}),
+			txGasTipCap: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "sfp_tx_gas_tip_cap_wei",
+				Help: "The gas tip cap of the latest transaction submission, the gas price for legacy transactions",
+			}, []string{"operator_address"}),
+			txGasFeeCap: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+				Name: "sfp_tx_gas_fee_cap_wei",
+				Help: "The gas fee cap of the latest transaction submission, the gas price for legacy transactions",
+			}, []string{"operator_address"}),
+			txFeeCapReached: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Name: "sfp_tx_fee_cap_reached_total",
+				Help: "The total number of transaction submissions whose fees are capped by the max fee cap",
+			}, []string{"operator_address"}),
+		}
+
+		// Register the metrics with Prometheus
+		prometheus.MustRegister(sfpMetricsInstance.operatorStatus)
+		prometheus.MustRegister(sfpMetricsInstance.operatorPaused)
+		prometheus.MustRegister(sfpMetricsInstance.walletBalance)
+		prometheus.MustRegister(sfpMetricsInstance.lastSignedOutputIndex)
+		prometheus.MustRegister(sfpMetricsInstance.lastSignedL2Block)
+		prometheus.MustRegister(sfpMetricsInstance.signatures)
+		prometheus.MustRegister(sfpMetricsInstance.daSubmitLatency)
+		prometheus.MustRegister(sfpMetricsInstance.daSubmitFailures)
+		prometheus.MustRegister(sfpMetricsInstance.operatorStake)
+		prometheus.MustRegister(sfpMetricsInstance.zeroStakeSkips)
+		prometheus.MustRegister(sfpMetricsInstance.pollerStartingHeight)
+		prometheus.MustRegister(sfpMetricsInstance.pollerHeadHeight)
+		prometheus.MustRegister(sfpMetricsInstance.pollerConfDepth)
+		prometheus.MustRegister(sfpMetricsInstance.pollerLag)
+		prometheus.MustRegister(sfpMetricsInstance.outputRootMismatches)
+		prometheus.MustRegister(sfpMetricsInstance.txAttempts)
+		prometheus.MustRegister(sfpMetricsInstance.txResubmissions)
+		prometheus.MustRegister(sfpMetricsInstance.txLastAttempt)
+		prometheus.MustRegister(sfpMetricsInstance.txGasTipCap)
+		prometheus.MustRegister(sfpMetricsInstance.txGasFeeCap)
+		prometheus.MustRegister(sfpMetricsInstance.txFeeCapReached)
+	})
+	return sfpMetricsInstance
+}
+
+// RecordOperatorStatus records the status of a symbiotic operator, the signing is
+// suspended unless the operator is active
+func (sm *SfpMetrics) RecordOperatorStatus(operatorAddr string, status types.OperatorStatus) {
+	sm.operatorStatus.WithLabelValues(operatorAddr).Set(float64(status))
+	paused := 0.0
+	if status != types.OperatorStatusActive {
+		paused = 1
+	}
+	sm.operatorPaused.WithLabelValues(operatorAddr).Set(paused)
+}
+
+// RecordWalletBalance records the balance of the operator wallet in wei
+func (sm *SfpMetrics) RecordWalletBalance(operatorAddr string, balance *big.Int) {
+	b, _ := new(big.Float).SetInt(balance).Float64()
+	sm.walletBalance.WithLabelValues(operatorAddr).Set(b)
+}
+
+// RecordSignatures records the signatures submitted through the DA path, the dispute
+// games are only counted as they have their own index
+func (sm *SfpMetrics) RecordSignatures(operatorAddr string, daPath string, signRequests []types.SignRequest) {
+	sm.signatures.WithLabelValues(operatorAddr, daPath).Add(float64(len(signRequests)))
+	for _, signRequest := range signRequests {
+		if signRequest.IsDisputeGame() {
+			continue
+		}
+		if signRequest.L2OutputIndex != nil {
+			sm.lastSignedOutputIndex.WithLabelValues(operatorAddr).Set(float64(signRequest.L2OutputIndex.Uint64()))
+		}
+		if signRequest.L2BlockNumber != nil {
+			sm.lastSignedL2Block.WithLabelValues(operatorAddr).Set(float64(signRequest.L2BlockNumber.Uint64()))
+		}
+	}
+}
+
+// RecordDASubmit records the latency of a signature submission through the DA path and whether it failed
+func (sm *SfpMetrics) RecordDASubmit(operatorAddr string, daPath string, latency time.Duration, err error) {
+	sm.daSubmitLatency.WithLabelValues(operatorAddr, daPath).Observe(latency.Seconds())
+	if err != nil {
+		sm.daSubmitFailures.WithLabelValues(operatorAddr, daPath).Inc()
+	}
+}
+
+// RecordOperatorStake records the active stake delegated to the operator at the latest epoch
+func (sm *SfpMetrics) RecordOperatorStake(operatorAddr string, stake *big.Int) {
+	s, _ := new(big.Float).SetInt(stake).Float64()
+	sm.operatorStake.WithLabelValues(operatorAddr).Set(s)
+}
+
+// RecordZeroStakeSkip records an output skipped as the operator has no active stake at its epoch
+func (sm *SfpMetrics) RecordZeroStakeSkip(operatorAddr string) {
+	sm.zeroStakeSkips.WithLabelValues(operatorAddr).Inc()
+}
+
+// SfpOperatorMetrics records the poller, the output verifier and the tx manager metrics
+// of a single symbiotic operator, it implements the metricers of the indexer and of the
+// tx manager
+type SfpOperatorMetrics struct {
+	sm           *SfpMetrics
+	operatorAddr string
+}
+
+// ForOperator returns the metrics labeled by the operator address
+func (sm *SfpMetrics) ForOperator(operatorAddr string) *SfpOperatorMetrics {
+	return &SfpOperatorMetrics{sm: sm, operatorAddr: operatorAddr}
+}
+
+// RecordPollerStartingHeight records the initial L1 block height when the poller started operation
+func (om *SfpOperatorMetrics) RecordPollerStartingHeight(height uint64) {
+	om.sm.pollerStartingHeight.WithLabelValues(om.operatorAddr).Set(float64(height))
+}
+
+// RecordPollerHeadHeight records the height of the L1 head the poller polls up to
+func (om *SfpOperatorMetrics) RecordPollerHeadHeight(headMode string, height uint64) {
+	om.sm.pollerHeadHeight.WithLabelValues(om.operatorAddr, headMode).Set(float64(height))
+}
+
+// RecordPollerConfirmationDepth records the number of blocks below the head that the poller does not poll yet
+func (om *SfpOperatorMetrics) RecordPollerConfirmationDepth(depth uint64) {
+	om.sm.pollerConfDepth.WithLabelValues(om.operatorAddr).Set(float64(depth))
+}
+
+// RecordPollerLag records the number of blocks the last polled block is behind the L1 head
+func (om *SfpOperatorMetrics) RecordPollerLag(lag uint64) {
+	om.sm.pollerLag.WithLabelValues(om.operatorAddr).Set(float64(lag))
+}
+
+// RecordOutputRootMismatch records a proposed output root that doesn't match the L2 node
+func (om *SfpOperatorMetrics) RecordOutputRootMismatch() {
+	om.sm.outputRootMismatches.WithLabelValues(om.operatorAddr).Inc()
+}
+
+// RecordTxAttempt records the fees of a transaction submission attempt
+func (om *SfpOperatorMetrics) RecordTxAttempt(attempt uint64, gasTipCap, gasFeeCap *big.Int) {
+	om.sm.txAttempts.WithLabelValues(om.operatorAddr).Inc()
+	if attempt > 1 {
+		om.sm.txResubmissions.WithLabelValues(om.operatorAddr).Inc()
+	}
+	om.sm.txLastAttempt.WithLabelValues(om.operatorAddr).Set(float64(attempt))
+	tipCap, _ := new(big.Float).SetInt(gasTipCap).Float64()
+	feeCap, _ := new(big.Float).SetInt(gasFeeCap).Float64()
+	om.sm.txGasTipCap.WithLabelValues(om.operatorAddr).Set(tipCap)
+	om.sm.txGasFeeCap.WithLabelValues(om.operatorAddr).Set(feeCap)
+}
+
+// RecordTxFeeCapReached records a transaction submission whose fees are capped by the max fee cap
+func (om *SfpOperatorMetrics) RecordTxFeeCapReached() {
+	om.sm.txFeeCapReached.WithLabelValues(om.operatorAddr).Inc()
+}
@@ -0,0 +1,88 @@
+package celestia
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/rollkit/go-da"
+
+	"github.com/Manta-Network/manta-fp/types"
+)
+
+const (
+	// BlobVersionLegacy is the plain JSON array of sign requests submitted before
+	// the blobs were versioned, it's only decoded
+	BlobVersionLegacy uint8 = 0
+	// BlobVersion1 is the JSON envelope carrying a batch of sign requests
+	BlobVersion1 uint8 = 1
+
+	// blobIDHeightLen is the length of the little endian celestia height
+	// which prefixes the commitment in a blob id
+	blobIDHeightLen = 8
+	blobIDLen       = blobIDHeightLen + 32
+)
+
+var (
+	ErrUnsupportedBlobVersion = errors.New("unsupported blob version")
+	ErrEmptyBlob              = errors.New("blob doesn't contain any sign request")
+)
+
+// Blob is the versioned envelope of the finality signatures published to celestia
+type Blob struct {
+	Version      uint8               `json:"version"`
+	SignRequests []types.SignRequest `json:"sign_requests"`
+}
+
+// BlobRef locates a blob published to celestia
+type BlobRef struct {
+	ID         []byte
+	Height     uint64
+	Commitment []byte
+}
+
+// EncodeBlob batches the sign requests into a single blob of the latest version
+func EncodeBlob(signRequests []types.SignRequest) ([]byte, error) {
+	if len(signRequests) == 0 {
+		return nil, ErrEmptyBlob
+	}
+	return json.Marshal(&Blob{
+		Version:      BlobVersion1,
+		SignRequests: signRequests,
+	})
+}
+
+// DecodeBlob decodes a blob of any known version
+func DecodeBlob(data []byte) (*Blob, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var signRequests []types.SignRequest
+		if err := json.Unmarshal(data, &signRequests); err != nil {
+			return nil, fmt.Errorf("failed to decode legacy blob: %w", err)
+		}
+		return &Blob{Version: BlobVersionLegacy, SignRequests: signRequests}, nil
+	}
+
+	blob := &Blob{}
+	if err := json.Unmarshal(data, blob); err != nil {
+		return nil, fmt.Errorf("failed to decode blob: %w", err)
+	}
+	if blob.Version != BlobVersion1 {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedBlobVersion, blob.Version)
+	}
+	return blob, nil
+}
+
+// ParseBlobID splits a celestia blob id into the inclusion height and the commitment
+func ParseBlobID(id da.ID) (*BlobRef, error) {
+	if len(id) != blobIDLen {
+		return nil, fmt.Errorf("invalid blob id length: %d", len(id))
+	}
+	return &BlobRef{
+		ID:         id,
+		Height:     binary.LittleEndian.Uint64(id[:blobIDHeightLen]),
+		Commitment: id[blobIDHeightLen:],
+	}, nil
+}
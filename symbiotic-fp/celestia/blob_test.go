@@ -0,0 +1,84 @@
+package celestia_test
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/celestia"
+	"github.com/Manta-Network/manta-fp/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testSignRequests() []types.SignRequest {
+	return []types.SignRequest{
+		{
+			StateRoot:     "0x01",
+			Signature:     []byte{1},
+			SignAddress:   "0x0000000000000000000000000000000000000001",
+			L2BlockNumber: big.NewInt(100),
+			L2OutputIndex: big.NewInt(1),
+		},
+		{
+			StateRoot:     "0x02",
+			Signature:     []byte{2},
+			SignAddress:   "0x0000000000000000000000000000000000000001",
+			L2BlockNumber: big.NewInt(200),
+			L2OutputIndex: big.NewInt(2),
+		},
+	}
+}
+
+func TestBlobRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	signRequests := testSignRequests()
+	data, err := celestia.EncodeBlob(signRequests)
+	require.NoError(t, err)
+
+	blob, err := celestia.DecodeBlob(data)
+	require.NoError(t, err)
+	require.Equal(t, celestia.BlobVersion1, blob.Version)
+	require.Equal(t, signRequests, blob.SignRequests)
+
+	_, err = celestia.EncodeBlob(nil)
+	require.ErrorIs(t, err, celestia.ErrEmptyBlob)
+}
+
+func TestDecodeLegacyBlob(t *testing.T) {
+	t.Parallel()
+
+	signRequests := testSignRequests()
+	data, err := json.Marshal(signRequests)
+	require.NoError(t, err)
+
+	blob, err := celestia.DecodeBlob(data)
+	require.NoError(t, err)
+	require.Equal(t, celestia.BlobVersionLegacy, blob.Version)
+	require.Equal(t, signRequests, blob.SignRequests)
+}
+
+func TestDecodeUnsupportedBlobVersion(t *testing.T) {
+	t.Parallel()
+
+	_, err := celestia.DecodeBlob([]byte(`{"version":2,"sign_requests":[]}`))
+	require.ErrorIs(t, err, celestia.ErrUnsupportedBlobVersion)
+}
+
+func TestParseBlobID(t *testing.T) {
+	t.Parallel()
+
+	id := make([]byte, 40)
+	binary.LittleEndian.PutUint64(id, 12345)
+	id[8] = 0xff
+
+	ref, err := celestia.ParseBlobID(id)
+	require.NoError(t, err)
+	require.Equal(t, uint64(12345), ref.Height)
+	require.Equal(t, id[8:], ref.Commitment)
+
+	_, err = celestia.ParseBlobID(id[:39])
+	require.Error(t, err)
+}
@@ -1,11 +1,16 @@
 package celestia
 
 import (
+	"bytes"
+	"context"
 	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
 	"time"
 
 	"github.com/Manta-Network/manta-fp/symbiotic-fp/config"
+	"github.com/Manta-Network/manta-fp/types"
 
 	"github.com/celestiaorg/go-square/blob"
 	"github.com/celestiaorg/go-square/inclusion"
@@ -16,6 +21,8 @@ import (
 )
 
 type DAClient struct {
+	// Backend is the name of the configured DA backend
+	Backend    string
 	Client     da.DA
 	Namespace  da.Namespace
 	GetTimeout time.Duration
@@ -30,20 +37,96 @@ func NewDAClient(cfg config.CelestiaConfig, authToken string) (*DAClient, error)
 		return nil, errors.New("wrong namespace length")
 	}
 	var client da.DA
-	if cfg.DaRpc != "" {
-		client, err = proxy.NewClient(cfg.DaRpc, authToken)
+	backend := cfg.Backend
+	switch backend {
+	case config.DABackendFile:
+		client, err = NewFileDA(cfg.FilePath)
 		if err != nil {
 			return nil, err
 		}
+	case config.DABackendMemory:
+		client = NewMemoryDA()
+	case "", config.DABackendCelestia:
+		backend = config.DABackendCelestia
+		// the signatures are only submitted to eth without a celestia node
+		if cfg.DaRpc != "" {
+			client, err = proxy.NewClient(cfg.DaRpc, authToken)
+			if err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported da backend %q", cfg.Backend)
 	}
 
 	return &DAClient{
+		Backend:    backend,
 		Client:     client,
 		Namespace:  append(make([]byte, 19), nsBytes...),
 		GetTimeout: cfg.Timeout,
 	}, nil
 }
 
+// SubmitBatch publishes the sign requests as a single versioned blob and validates
+// the inclusion proof, the returned reference locates the blob on celestia
+func (c *DAClient) SubmitBatch(ctx context.Context, signRequests []types.SignRequest) (*BlobRef, error) {
+	data, err := EncodeBlob(signRequests)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := CreateCommitment(data, c.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create commitment: %w", err)
+	}
+
+	ctx2, cancel := context.WithTimeout(ctx, c.GetTimeout)
+	ids, err := c.Client.Submit(ctx2, [][]byte{data}, -1, c.Namespace)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit blob: %w", err)
+	}
+	if len(ids) != 1 {
+		return nil, fmt.Errorf("unexpected number of blob ids: %d", len(ids))
+	}
+	ref, err := ParseBlobID(ids[0])
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(commit, ref.Commitment) {
+		return nil, fmt.Errorf("unexpected blob id %s for commitment %s", hex.EncodeToString(ref.ID), hex.EncodeToString(commit))
+	}
+
+	ctx2, cancel = context.WithTimeout(ctx, c.GetTimeout)
+	proofs, err := c.Client.GetProofs(ctx2, ids, c.Namespace)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proof: %w", err)
+	}
+	if len(proofs) != 1 {
+		return nil, fmt.Errorf("unexpected number of proofs: %d", len(proofs))
+	}
+
+	ctx2, cancel = context.WithTimeout(ctx, c.GetTimeout)
+	valids, err := c.Client.Validate(ctx2, ids, proofs, c.Namespace)
+	cancel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate proof: %w", err)
+	}
+	if len(valids) != 1 || !valids[0] {
+		return nil, fmt.Errorf("invalid blob proof: %v", valids)
+	}
+
+	return ref, nil
+}
+
+// Close releases the resources of the backend
+func (c *DAClient) Close() error {
+	if closer, ok := c.Client.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 func CreateCommitment(data da.Blob, ns da.Namespace) ([]byte, error) {
 	ins, err := namespace.From(ns)
 	if err != nil {
@@ -0,0 +1,284 @@
+package celestia
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rollkit/go-da"
+)
+
+// localDAMaxBlobSize is the max blob size of the local DA, it's the same as celestia's
+const localDAMaxBlobSize = 64 * 64 * 482
+
+// localRecord is a blob submitted to the local DA, the file backend appends
+// every record as a JSON line
+type localRecord struct {
+	Height    uint64    `json:"height"`
+	Timestamp time.Time `json:"timestamp"`
+	Namespace []byte    `json:"namespace"`
+	ID        []byte    `json:"id"`
+	Blob      []byte    `json:"blob"`
+}
+
+// LocalDA is a DA layer kept in memory and optionally persisted to an append-only
+// file. The ids and commitments are computed like celestia's so that the blobs are
+// submitted and verified the same way, the proofs are the hash of the blob and its
+// location. It lets devnets and tests run the signing pipeline without a celestia node
+type LocalDA struct {
+	mu      sync.Mutex
+	height  uint64
+	records map[uint64][]*localRecord
+	file    *os.File
+}
+
+var _ da.DA = &LocalDA{}
+
+// NewMemoryDA returns a local DA which only keeps the blobs in memory
+func NewMemoryDA() *LocalDA {
+	return &LocalDA{
+		records: make(map[uint64][]*localRecord),
+	}
+}
+
+// NewFileDA returns a local DA persisted to the file, the blobs already in the
+// file are loaded and an incomplete trailing record is discarded
+func NewFileDA(path string) (*LocalDA, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	d := NewMemoryDA()
+	if err := d.load(file); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to load the local da file %s: %w", path, err)
+	}
+	if _, err := file.Seek(0, io.SeekEnd); err != nil {
+		file.Close()
+		return nil, err
+	}
+	d.file = file
+	return d, nil
+}
+
+func (d *LocalDA) load(file *os.File) error {
+	reader := bufio.NewReader(file)
+	var offset int64
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(line) > 0 {
+				// the record was partially written, drop it
+				return file.Truncate(offset)
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		record := &localRecord{}
+		if err := json.Unmarshal(line, record); err != nil {
+			return fmt.Errorf("corrupted record at offset %d: %w", offset, err)
+		}
+		d.addRecord(record)
+		offset += int64(len(line))
+	}
+}
+
+func (d *LocalDA) addRecord(record *localRecord) {
+	d.records[record.Height] = append(d.records[record.Height], record)
+	if record.Height > d.height {
+		d.height = record.Height
+	}
+}
+
+// Close closes the file of the file backend
+func (d *LocalDA) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.file == nil {
+		return nil
+	}
+	err := d.file.Close()
+	d.file = nil
+	return err
+}
+
+func (d *LocalDA) MaxBlobSize(_ context.Context) (uint64, error) {
+	return localDAMaxBlobSize, nil
+}
+
+func (d *LocalDA) Get(_ context.Context, ids []da.ID, ns da.Namespace) ([]da.Blob, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	blobs := make([]da.Blob, len(ids))
+	for i, id := range ids {
+		record := d.findRecord(id, ns)
+		if record == nil {
+			return nil, &da.ErrBlobNotFound{}
+		}
+		blobs[i] = record.Blob
+	}
+	return blobs, nil
+}
+
+func (d *LocalDA) GetIDs(_ context.Context, height uint64, ns da.Namespace) (*da.GetIDsResult, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if height > d.height {
+		return nil, &da.ErrFutureHeight{}
+	}
+
+	var (
+		ids       []da.ID
+		timestamp time.Time
+	)
+	for _, record := range d.records[height] {
+		if bytes.Equal(record.Namespace, ns) {
+			ids = append(ids, record.ID)
+			timestamp = record.Timestamp
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return &da.GetIDsResult{IDs: ids, Timestamp: timestamp}, nil
+}
+
+func (d *LocalDA) GetProofs(_ context.Context, ids []da.ID, ns da.Namespace) ([]da.Proof, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	proofs := make([]da.Proof, len(ids))
+	for i, id := range ids {
+		record := d.findRecord(id, ns)
+		if record == nil {
+			return nil, &da.ErrBlobNotFound{}
+		}
+		proofs[i] = localProof(record)
+	}
+	return proofs, nil
+}
+
+func (d *LocalDA) Commit(_ context.Context, blobs []da.Blob, ns da.Namespace) ([]da.Commitment, error) {
+	commits := make([]da.Commitment, len(blobs))
+	for i, blob := range blobs {
+		commit, err := CreateCommitment(blob, ns)
+		if err != nil {
+			return nil, err
+		}
+		commits[i] = commit
+	}
+	return commits, nil
+}
+
+func (d *LocalDA) Submit(ctx context.Context, blobs []da.Blob, gasPrice float64, ns da.Namespace) ([]da.ID, error) {
+	return d.SubmitWithOptions(ctx, blobs, gasPrice, ns, nil)
+}
+
+// SubmitWithOptions includes all the blobs at the next height, the options are ignored
+func (d *LocalDA) SubmitWithOptions(ctx context.Context, blobs []da.Blob, _ float64, ns da.Namespace, _ []byte) ([]da.ID, error) {
+	commits, err := d.Commit(ctx, blobs, ns)
+	if err != nil {
+		return nil, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	height := d.height + 1
+	timestamp := time.Now()
+	records := make([]*localRecord, len(blobs))
+	var lines bytes.Buffer
+	for i, blob := range blobs {
+		if uint64(len(blob)) > localDAMaxBlobSize {
+			return nil, &da.ErrBlobSizeOverLimit{}
+		}
+		id := make([]byte, blobIDHeightLen, blobIDLen)
+		binary.LittleEndian.PutUint64(id, height)
+		records[i] = &localRecord{
+			Height:    height,
+			Timestamp: timestamp,
+			Namespace: ns,
+			ID:        append(id, commits[i]...),
+			Blob:      blob,
+		}
+		line, err := json.Marshal(records[i])
+		if err != nil {
+			return nil, err
+		}
+		lines.Write(line)
+		lines.WriteByte('\n')
+	}
+
+	if d.file != nil {
+		if _, err := d.file.Write(lines.Bytes()); err != nil {
+			return nil, fmt.Errorf("failed to write the local da file: %w", err)
+		}
+		if err := d.file.Sync(); err != nil {
+			return nil, fmt.Errorf("failed to sync the local da file: %w", err)
+		}
+	}
+
+	ids := make([]da.ID, len(records))
+	for i, record := range records {
+		d.addRecord(record)
+		ids[i] = record.ID
+	}
+	return ids, nil
+}
+
+func (d *LocalDA) Validate(_ context.Context, ids []da.ID, proofs []da.Proof, ns da.Namespace) ([]bool, error) {
+	if len(ids) != len(proofs) {
+		return nil, errors.New("number of IDs doesn't equal to number of proofs")
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	results := make([]bool, len(ids))
+	for i, id := range ids {
+		record := d.findRecord(id, ns)
+		results[i] = record != nil && bytes.Equal(localProof(record), proofs[i])
+	}
+	return results, nil
+}
+
+func (d *LocalDA) findRecord(id da.ID, ns da.Namespace) *localRecord {
+	if len(id) != blobIDLen {
+		return nil
+	}
+	for _, record := range d.records[binary.LittleEndian.Uint64(id[:blobIDHeightLen])] {
+		if bytes.Equal(record.ID, id) && bytes.Equal(record.Namespace, ns) {
+			return record
+		}
+	}
+	return nil
+}
+
+// localProof is the hash of the namespace, the id and the blob
+func localProof(record *localRecord) da.Proof {
+	h := sha256.New()
+	h.Write(record.Namespace)
+	h.Write(record.ID)
+	h.Write(record.Blob)
+	return h.Sum(nil)
+}
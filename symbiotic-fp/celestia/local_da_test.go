@@ -0,0 +1,110 @@
+package celestia_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rollkit/go-da"
+
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/celestia"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/config"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryDASubmitBatch(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.DefaultCelestiaConfig()
+	cfg.Backend = config.DABackendMemory
+	daClient, err := celestia.NewDAClient(cfg, "")
+	require.NoError(t, err)
+
+	signRequests := testSignRequests()
+	ref, err := daClient.SubmitBatch(context.Background(), signRequests)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), ref.Height)
+
+	blobs, err := daClient.Client.Get(context.Background(), []da.ID{ref.ID}, daClient.Namespace)
+	require.NoError(t, err)
+	blob, err := celestia.DecodeBlob(blobs[0])
+	require.NoError(t, err)
+	require.Equal(t, signRequests, blob.SignRequests)
+
+	// the blobs are only visible in their namespace
+	otherNamespace := make([]byte, 29)
+	otherNamespace[28] = 1
+	res, err := daClient.Client.GetIDs(context.Background(), 1, otherNamespace)
+	require.NoError(t, err)
+	require.Nil(t, res)
+
+	_, err = daClient.Client.GetIDs(context.Background(), 2, daClient.Namespace)
+	require.Error(t, err)
+}
+
+func TestFileDAPersistsBlobs(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	cfg := config.DefaultCelestiaConfigWithHomePath(t.TempDir())
+	cfg.Backend = config.DABackendFile
+	require.NoError(t, cfg.Validate())
+
+	daClient, err := celestia.NewDAClient(cfg, "")
+	require.NoError(t, err)
+	ref1, err := daClient.SubmitBatch(ctx, testSignRequests()[:1])
+	require.NoError(t, err)
+	ref2, err := daClient.SubmitBatch(ctx, testSignRequests()[1:])
+	require.NoError(t, err)
+	require.NoError(t, daClient.Close())
+
+	// a partially written record is discarded on reopen
+	file, err := os.OpenFile(cfg.FilePath, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"height":3,`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	reopened, err := celestia.NewDAClient(cfg, "")
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	for _, ref := range []*celestia.BlobRef{ref1, ref2} {
+		res, err := reopened.Client.GetIDs(ctx, ref.Height, reopened.Namespace)
+		require.NoError(t, err)
+		require.Equal(t, []da.ID{ref.ID}, res.IDs)
+
+		proofs, err := reopened.Client.GetProofs(ctx, res.IDs, reopened.Namespace)
+		require.NoError(t, err)
+		valids, err := reopened.Client.Validate(ctx, res.IDs, proofs, reopened.Namespace)
+		require.NoError(t, err)
+		require.Equal(t, []bool{true}, valids)
+	}
+
+	ref3, err := reopened.SubmitBatch(ctx, testSignRequests())
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), ref3.Height)
+
+	verifier, err := celestia.NewVerifier(reopened, &mockOperatorRegistry{}, testSignDomain)
+	require.NoError(t, err)
+	report, err := verifier.Verify(ctx, 1, 3)
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Blobs)
+	require.Empty(t, report.InvalidBlobs)
+}
+
+func TestFileDARequiresPath(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.DefaultCelestiaConfig()
+	cfg.Backend = config.DABackendFile
+	require.Error(t, cfg.Validate())
+
+	cfg.FilePath = filepath.Join(t.TempDir(), "da", "blobs.jsonl")
+	require.NoError(t, cfg.Validate())
+
+	cfg.Backend = "unknown"
+	require.Error(t, cfg.Validate())
+}
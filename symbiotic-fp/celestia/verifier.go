@@ -0,0 +1,240 @@
+package celestia
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rollkit/go-da"
+
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/store"
+	"github.com/Manta-Network/manta-fp/types"
+)
+
+var (
+	ErrDAClientNotConfigured = errors.New("celestia da rpc is not configured")
+)
+
+// OperatorRegistry tells whether an address is a registered operator
+type OperatorRegistry interface {
+	IsOperator(ctx context.Context, operator common.Address) (bool, error)
+}
+
+// SignatureReport is a single finality signature read back from celestia
+type SignatureReport struct {
+	Version       uint8          `json:"version"`
+	Signer        common.Address `json:"signer"`
+	SignAddress   string         `json:"sign_address"`
+	StateRoot     string         `json:"state_root"`
+	L2BlockNumber *big.Int       `json:"l2_block_number"`
+	DAHeight      uint64         `json:"da_height"`
+	BlobID        string         `json:"blob_id"`
+	Registered    bool           `json:"registered"`
+	Error         string         `json:"error,omitempty"`
+}
+
+// OutputReport lists the signatures published for a single L2 output or dispute game,
+// the operators are the registered signers whose signature is valid
+type OutputReport struct {
+	L2OutputIndex    uint64             `json:"l2_output_index"`
+	DisputeGameProxy string             `json:"dispute_game_proxy,omitempty"`
+	Operators        []common.Address   `json:"operators"`
+	Signatures       []*SignatureReport `json:"signatures"`
+}
+
+// Report is the result of verifying the blobs of a range of celestia heights
+type Report struct {
+	StartHeight  uint64          `json:"start_height"`
+	EndHeight    uint64          `json:"end_height"`
+	Blobs        int             `json:"blobs"`
+	InvalidBlobs []string        `json:"invalid_blobs"`
+	Outputs      []*OutputReport `json:"outputs"`
+}
+
+// Verifier reads the finality signatures back from the celestia namespace and
+// checks that they are signed by registered operators in the sign domain
+type Verifier struct {
+	client   *DAClient
+	registry OperatorRegistry
+	domain   *types.SignDomain
+
+	operators map[common.Address]bool
+}
+
+func NewVerifier(client *DAClient, registry OperatorRegistry, domain *types.SignDomain) (*Verifier, error) {
+	if client == nil || client.Client == nil {
+		return nil, ErrDAClientNotConfigured
+	}
+	return &Verifier{
+		client:    client,
+		registry:  registry,
+		domain:    domain,
+		operators: make(map[common.Address]bool),
+	}, nil
+}
+
+// Verify fetches the blobs of the heights in [startHeight, endHeight], the blobs with an
+// invalid inclusion proof or an unknown format are listed in the report and skipped
+func (v *Verifier) Verify(ctx context.Context, startHeight, endHeight uint64) (*Report, error) {
+	if startHeight > endHeight {
+		return nil, fmt.Errorf("invalid height range [%d, %d]", startHeight, endHeight)
+	}
+
+	report := &Report{
+		StartHeight:  startHeight,
+		EndHeight:    endHeight,
+		InvalidBlobs: []string{},
+	}
+	// the outputs are keyed by record index so that the games and the outputs don't collide
+	outputs := make(map[uint64]*OutputReport)
+
+	for height := startHeight; height <= endHeight; height++ {
+		ids, blobs, valids, err := v.getBlobs(ctx, height)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get blobs at height %d: %w", height, err)
+		}
+
+		for i := range blobs {
+			report.Blobs++
+			blobID := hex.EncodeToString(ids[i])
+			if !valids[i] {
+				report.InvalidBlobs = append(report.InvalidBlobs, blobID)
+				continue
+			}
+			blob, err := DecodeBlob(blobs[i])
+			if err != nil {
+				report.InvalidBlobs = append(report.InvalidBlobs, blobID)
+				continue
+			}
+
+			for _, signRequest := range blob.SignRequests {
+				if signRequest.L2OutputIndex == nil {
+					continue
+				}
+				signature, err := v.verifySignRequest(ctx, &signRequest)
+				if err != nil {
+					return nil, err
+				}
+				signature.DAHeight = height
+				signature.BlobID = blobID
+
+				outputIndex := signRequest.L2OutputIndex.Uint64()
+				recordIndex := store.RecordIndex(outputIndex, signRequest.IsDisputeGame())
+				output, ok := outputs[recordIndex]
+				if !ok {
+					output = &OutputReport{
+						L2OutputIndex:    outputIndex,
+						DisputeGameProxy: signRequest.DisputeGameProxy,
+						Operators:        []common.Address{},
+					}
+					outputs[recordIndex] = output
+				}
+				output.Signatures = append(output.Signatures, signature)
+				if signature.Registered && signature.Error == "" && !containsAddress(output.Operators, signature.Signer) {
+					output.Operators = append(output.Operators, signature.Signer)
+				}
+			}
+		}
+	}
+
+	recordIndexes := make([]uint64, 0, len(outputs))
+	for recordIndex := range outputs {
+		recordIndexes = append(recordIndexes, recordIndex)
+	}
+	// the outputs are sorted before the dispute games
+	sort.Slice(recordIndexes, func(i, j int) bool { return recordIndexes[i] < recordIndexes[j] })
+	report.Outputs = make([]*OutputReport, 0, len(outputs))
+	for _, recordIndex := range recordIndexes {
+		report.Outputs = append(report.Outputs, outputs[recordIndex])
+	}
+
+	return report, nil
+}
+
+// getBlobs returns the blobs at the height together with the result of validating their inclusion proofs
+func (v *Verifier) getBlobs(ctx context.Context, height uint64) ([]da.ID, []da.Blob, []bool, error) {
+	ctx2, cancel := context.WithTimeout(ctx, v.client.GetTimeout)
+	res, err := v.client.Client.GetIDs(ctx2, height, v.client.Namespace)
+	cancel()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get blob ids: %w", err)
+	}
+	if res == nil || len(res.IDs) == 0 {
+		return nil, nil, nil, nil
+	}
+
+	ctx2, cancel = context.WithTimeout(ctx, v.client.GetTimeout)
+	blobs, err := v.client.Client.Get(ctx2, res.IDs, v.client.Namespace)
+	cancel()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get blobs: %w", err)
+	}
+	if len(blobs) != len(res.IDs) {
+		return nil, nil, nil, fmt.Errorf("unexpected number of blobs: %d", len(blobs))
+	}
+
+	ctx2, cancel = context.WithTimeout(ctx, v.client.GetTimeout)
+	proofs, err := v.client.Client.GetProofs(ctx2, res.IDs, v.client.Namespace)
+	cancel()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get proofs: %w", err)
+	}
+
+	ctx2, cancel = context.WithTimeout(ctx, v.client.GetTimeout)
+	valids, err := v.client.Client.Validate(ctx2, res.IDs, proofs, v.client.Namespace)
+	cancel()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to validate proofs: %w", err)
+	}
+	if len(valids) != len(res.IDs) {
+		return nil, nil, nil, fmt.Errorf("unexpected number of proof results: %d", len(valids))
+	}
+
+	return res.IDs, blobs, valids, nil
+}
+
+// verifySignRequest recovers the signer of the state root and looks it up in the registry,
+// only a failure to query the registry is returned as an error
+func (v *Verifier) verifySignRequest(ctx context.Context, signRequest *types.SignRequest) (*SignatureReport, error) {
+	report := &SignatureReport{
+		Version:       signRequest.Version,
+		SignAddress:   signRequest.SignAddress,
+		StateRoot:     signRequest.StateRoot,
+		L2BlockNumber: signRequest.L2BlockNumber,
+	}
+
+	signer, err := signRequest.RecoverSigner(v.domain)
+	if err != nil {
+		report.Error = err.Error()
+		return report, nil
+	}
+	report.Signer = signer
+	if !common.IsHexAddress(signRequest.SignAddress) || common.HexToAddress(signRequest.SignAddress) != signer {
+		report.Error = types.ErrSignAddressMismatch.Error()
+	}
+
+	registered, ok := v.operators[signer]
+	if !ok {
+		registered, err = v.registry.IsOperator(ctx, signer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query operator %s: %w", signer.String(), err)
+		}
+		v.operators[signer] = registered
+	}
+	report.Registered = registered
+
+	return report, nil
+}
+
+func containsAddress(addresses []common.Address, address common.Address) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package celestia_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	datest "github.com/rollkit/go-da/test"
+
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/celestia"
+	"github.com/Manta-Network/manta-fp/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockOperatorRegistry struct {
+	operators map[common.Address]bool
+}
+
+func (r *mockOperatorRegistry) IsOperator(_ context.Context, operator common.Address) (bool, error) {
+	return r.operators[operator], nil
+}
+
+var testSignDomain = &types.SignDomain{
+	ChainID:                    big.NewInt(1),
+	MantaStakingMiddlewareAddr: common.HexToAddress("0x1000"),
+}
+
+func newSignRequest(t *testing.T, key *ecdsa.PrivateKey, outputIndex int64) types.SignRequest {
+	stateRoot := &types.StateRoot{
+		StateRoot:     crypto.Keccak256Hash(big.NewInt(outputIndex).Bytes()),
+		L2BlockNumber: big.NewInt(outputIndex * 100),
+		L2OutputIndex: big.NewInt(outputIndex),
+		L1BlockHash:   crypto.Keccak256Hash([]byte("l1"), big.NewInt(outputIndex).Bytes()),
+	}
+	hash, err := types.StateRootSigningHash(testSignDomain, stateRoot)
+	require.NoError(t, err)
+	signature, err := crypto.Sign(hash[:], key)
+	require.NoError(t, err)
+	return types.SignRequest{
+		Version:       types.SignRequestVersionTyped,
+		StateRoot:     hex.EncodeToString(stateRoot.StateRoot[:]),
+		Signature:     signature,
+		SignAddress:   crypto.PubkeyToAddress(key.PublicKey).String(),
+		L2BlockNumber: stateRoot.L2BlockNumber,
+		L2OutputIndex: stateRoot.L2OutputIndex,
+		L1BlockHash:   stateRoot.L1BlockHash.Hex(),
+	}
+}
+
+// newLegacySignRequest signs the raw state root as the sign requests before the typed data
+func newLegacySignRequest(t *testing.T, key *ecdsa.PrivateKey, outputIndex int64) types.SignRequest {
+	stateRoot := crypto.Keccak256Hash(big.NewInt(outputIndex).Bytes())
+	signature, err := crypto.Sign(stateRoot[:], key)
+	require.NoError(t, err)
+	return types.SignRequest{
+		StateRoot:     hex.EncodeToString(stateRoot[:]),
+		Signature:     signature,
+		SignAddress:   crypto.PubkeyToAddress(key.PublicKey).String(),
+		L2BlockNumber: big.NewInt(outputIndex * 100),
+		L2OutputIndex: big.NewInt(outputIndex),
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dummy := datest.NewDummyDA()
+	daClient := &celestia.DAClient{
+		Client:     dummy,
+		Namespace:  make([]byte, 29),
+		GetTimeout: time.Second,
+	}
+
+	operatorKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	otherOperatorKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	unknownKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	operator := crypto.PubkeyToAddress(operatorKey.PublicKey)
+	otherOperator := crypto.PubkeyToAddress(otherOperatorKey.PublicKey)
+
+	registry := &mockOperatorRegistry{operators: map[common.Address]bool{
+		operator:      true,
+		otherOperator: true,
+	}}
+
+	// height 1: a versioned blob of the operator and a legacy blob of the other operator
+	blob1, err := celestia.EncodeBlob([]types.SignRequest{
+		newSignRequest(t, operatorKey, 1),
+		newSignRequest(t, operatorKey, 2),
+	})
+	require.NoError(t, err)
+	blob2, err := json.Marshal([]types.SignRequest{newLegacySignRequest(t, otherOperatorKey, 1)})
+	require.NoError(t, err)
+	_, err = dummy.Submit(ctx, [][]byte{blob1, blob2}, -1, daClient.Namespace)
+	require.NoError(t, err)
+
+	// height 2: an unregistered signer, a forged sign address and a foreign blob
+	forged := newSignRequest(t, unknownKey, 2)
+	forged.SignAddress = otherOperator.String()
+	blob3, err := celestia.EncodeBlob([]types.SignRequest{
+		newSignRequest(t, unknownKey, 1),
+		forged,
+	})
+	require.NoError(t, err)
+	_, err = dummy.Submit(ctx, [][]byte{blob3, []byte("not a sign request")}, -1, daClient.Namespace)
+	require.NoError(t, err)
+
+	verifier, err := celestia.NewVerifier(daClient, registry, testSignDomain)
+	require.NoError(t, err)
+
+	report, err := verifier.Verify(ctx, 1, 2)
+	require.NoError(t, err)
+	require.Equal(t, 4, report.Blobs)
+	require.Len(t, report.InvalidBlobs, 1)
+	require.Len(t, report.Outputs, 2)
+
+	require.Equal(t, uint64(1), report.Outputs[0].L2OutputIndex)
+	require.ElementsMatch(t, []common.Address{operator, otherOperator}, report.Outputs[0].Operators)
+	require.Len(t, report.Outputs[0].Signatures, 3)
+
+	require.Equal(t, uint64(2), report.Outputs[1].L2OutputIndex)
+	require.Equal(t, []common.Address{operator}, report.Outputs[1].Operators)
+	require.Len(t, report.Outputs[1].Signatures, 2)
+	forgedReport := report.Outputs[1].Signatures[1]
+	require.Equal(t, uint64(2), forgedReport.DAHeight)
+	require.False(t, forgedReport.Registered)
+	require.NotEmpty(t, forgedReport.Error)
+
+	_, err = verifier.Verify(ctx, 2, 1)
+	require.Error(t, err)
+}
+
+func TestNewVerifierWithoutClient(t *testing.T) {
+	t.Parallel()
+
+	_, err := celestia.NewVerifier(&celestia.DAClient{}, &mockOperatorRegistry{}, testSignDomain)
+	require.ErrorIs(t, err, celestia.ErrDAClientNotConfigured)
+}
+
+func TestVerifySignRequest(t *testing.T) {
+	t.Parallel()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	signRequest := newSignRequest(t, key, 1)
+	require.NoError(t, types.VerifySignRequest(&signRequest, testSignDomain))
+
+	// the typed signature doesn't verify in another domain or for another l1 block
+	otherDomain := &types.SignDomain{
+		ChainID:                    big.NewInt(2),
+		MantaStakingMiddlewareAddr: testSignDomain.MantaStakingMiddlewareAddr,
+	}
+	require.ErrorIs(t, types.VerifySignRequest(&signRequest, otherDomain), types.ErrSignAddressMismatch)
+	reorged := signRequest
+	reorged.L1BlockHash = common.Hash{1}.Hex()
+	require.ErrorIs(t, types.VerifySignRequest(&reorged, testSignDomain), types.ErrSignAddressMismatch)
+
+	legacy := newLegacySignRequest(t, key, 1)
+	require.NoError(t, types.VerifySignRequest(&legacy, otherDomain))
+
+	unsupported := signRequest
+	unsupported.Version = 2
+	require.Error(t, types.VerifySignRequest(&unsupported, testSignDomain))
+}
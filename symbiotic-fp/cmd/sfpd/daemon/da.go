@@ -0,0 +1,122 @@
+package daemon
+
+import (
+	"fmt"
+	"math/big"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Manta-Network/manta-fp/log"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/celestia"
+	fpcfg "github.com/Manta-Network/manta-fp/symbiotic-fp/config"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/mantastaking"
+	"github.com/Manta-Network/manta-fp/types"
+	"github.com/Manta-Network/manta-fp/util"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	startHeightFlag = "start-height"
+	endHeightFlag   = "end-height"
+)
+
+// CommandDA returns the data availability command group of sfpd
+func CommandDA() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "da",
+		Short: "Inspect the finality signatures published to celestia.",
+	}
+	cmd.AddCommand(commandDAVerify())
+	return cmd
+}
+
+func commandDAVerify() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "verify",
+		Short:   "Read back the finality signatures of a celestia height range and report which registered operators signed every output.",
+		Example: `sfpd da verify --start-height 100 --end-height 200 --auth-token <token> --home /home/user/.sfpd`,
+		Args:    cobra.NoArgs,
+		RunE:    runDAVerifyCmd,
+	}
+	cmd.Flags().Uint64(startHeightFlag, 0, "The first celestia height to verify")
+	cmd.Flags().Uint64(endHeightFlag, 0, "The last celestia height to verify")
+	cmd.Flags().String(AuthTokenFlag, "", "The auth token of celestia node")
+
+	if err := cmd.MarkFlagRequired(startHeightFlag); err != nil {
+		panic(err)
+	}
+	if err := cmd.MarkFlagRequired(endHeightFlag); err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+func runDAVerifyCmd(cmd *cobra.Command, _ []string) error {
+	home, err := cmd.Flags().GetString(HomeFlag)
+	if err != nil {
+		return fmt.Errorf("failed to read flag %s: %w", HomeFlag, err)
+	}
+	homePath, err := filepath.Abs(home)
+	if err != nil {
+		return err
+	}
+	homePath = util.CleanAndExpandPath(homePath)
+
+	startHeight, err := cmd.Flags().GetUint64(startHeightFlag)
+	if err != nil {
+		return fmt.Errorf("failed to read flag %s: %w", startHeightFlag, err)
+	}
+	endHeight, err := cmd.Flags().GetUint64(endHeightFlag)
+	if err != nil {
+		return fmt.Errorf("failed to read flag %s: %w", endHeightFlag, err)
+	}
+	authToken, err := cmd.Flags().GetString(AuthTokenFlag)
+	if err != nil {
+		return fmt.Errorf("failed to read flag %s: %w", AuthTokenFlag, err)
+	}
+
+	cfg, err := fpcfg.LoadConfig(homePath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	daClient, err := celestia.NewDAClient(*cfg.CelestiaConfig, authToken)
+	if err != nil {
+		return fmt.Errorf("failed to new celestia da client: %w", err)
+	}
+	defer daClient.Close()
+
+	logger, err := log.NewRootLoggerWithFile(fpcfg.LogFile(homePath), cfg.LogLevel)
+	if err != nil {
+		return fmt.Errorf("failed to initialize the logger: %w", err)
+	}
+	ethClient, err := mantastaking.NewEthBackend(cmd.Context(), cfg.OpEventConfig, logger)
+	if err != nil {
+		return fmt.Errorf("failed to dial eth client: %w", err)
+	}
+	defer ethClient.Close()
+
+	registry, err := mantastaking.NewOperatorRegistry(
+		common.HexToAddress(cfg.OpEventConfig.MantaStakingMiddlewareAddress), ethClient,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to new operator registry: %w", err)
+	}
+
+	domain := &types.SignDomain{
+		ChainID:                    new(big.Int).SetUint64(uint64(cfg.OpEventConfig.ChainId)),
+		MantaStakingMiddlewareAddr: common.HexToAddress(cfg.OpEventConfig.MantaStakingMiddlewareAddress),
+	}
+	verifier, err := celestia.NewVerifier(daClient, registry, domain)
+	if err != nil {
+		return err
+	}
+	report, err := verifier.Verify(cmd.Context(), startHeight, endHeight)
+	if err != nil {
+		return err
+	}
+	printRespJSON(report)
+	return nil
+}
@@ -0,0 +1,329 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Manta-Network/manta-fp/log"
+	fpcfg "github.com/Manta-Network/manta-fp/symbiotic-fp/config"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/mantastaking"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/signer"
+	"github.com/Manta-Network/manta-fp/util"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	operatorFlag                    = "operator"
+	unregisterTokenUnlockWindowFlag = "unregister-token-unlock-window"
+	requiredOperatorStakeFlag       = "required-operator-stake"
+	minOperatorCommissionFlag       = "min-operator-commission"
+	maxOperatorCommissionFlag       = "max-operator-commission"
+)
+
+// txResponse is printed after an operator transaction is confirmed
+type txResponse struct {
+	TxHash      string `json:"tx_hash"`
+	BlockNumber string `json:"block_number"`
+	GasUsed     uint64 `json:"gas_used"`
+	Success     bool   `json:"success"`
+}
+
+// CommandOperator returns the operator command group of sfpd. The transactions
+// are signed by the operator signer configured in sfpd.conf
+func CommandOperator() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "operator",
+		Short: "Manage the operator in the manta staking middleware.",
+	}
+	cmd.AddCommand(
+		commandPauseOperator(), commandUnpauseOperator(), commandUnregisterOperator(),
+		commandSetRewardAddress(), commandUpdateOperatorSettings(), commandClaimUnlockedToken(),
+		commandOperatorInfo(), commandOperatorSettings(), commandVaultSettings(),
+	)
+	return cmd
+}
+
+func commandPauseOperator() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "pause [operator-address]",
+		Short:   "Pause an operator, only callable by the middleware admin.",
+		Example: `sfpd operator pause 0x... --home /home/user/.sfpd`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			operator, err := parseAddress(args[0])
+			if err != nil {
+				return err
+			}
+			return runOperatorTx(cmd, func(ctx context.Context, oc *mantastaking.OperatorClient) (*types.Receipt, error) {
+				return oc.PauseOperator(ctx, operator)
+			})
+		},
+	}
+	return cmd
+}
+
+func commandUnpauseOperator() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "unpause [operator-address]",
+		Short:   "Unpause an operator, only callable by the middleware admin.",
+		Example: `sfpd operator unpause 0x... --home /home/user/.sfpd`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			operator, err := parseAddress(args[0])
+			if err != nil {
+				return err
+			}
+			return runOperatorTx(cmd, func(ctx context.Context, oc *mantastaking.OperatorClient) (*types.Receipt, error) {
+				return oc.UnpauseOperator(ctx, operator)
+			})
+		},
+	}
+	return cmd
+}
+
+func commandUnregisterOperator() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "unregister",
+		Short:   "Unregister the operator, the staked token is unlocked after the unlock window.",
+		Example: `sfpd operator unregister --home /home/user/.sfpd`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runOperatorTx(cmd, func(ctx context.Context, oc *mantastaking.OperatorClient) (*types.Receipt, error) {
+				return oc.UnregisterOperator(ctx)
+			})
+		},
+	}
+	return cmd
+}
+
+func commandSetRewardAddress() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "set-reward-address [reward-address]",
+		Short:   "Set the address receiving the rewards of the operator.",
+		Example: `sfpd operator set-reward-address 0x... --home /home/user/.sfpd`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rewardAddress, err := parseAddress(args[0])
+			if err != nil {
+				return err
+			}
+			return runOperatorTx(cmd, func(ctx context.Context, oc *mantastaking.OperatorClient) (*types.Receipt, error) {
+				return oc.SetRewardAddress(ctx, rewardAddress)
+			})
+		},
+	}
+	return cmd
+}
+
+func commandUpdateOperatorSettings() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "update-settings",
+		Short: "Update the operator settings of the middleware, only callable by the middleware admin.",
+		Long:  `Update the operator settings of the middleware. Settings that are not set keep their current value`,
+		Example: `sfpd operator update-settings --required-operator-stake 1000000000000000000000 ` +
+			`--max-operator-commission 2000 --home /home/user/.sfpd`,
+		Args: cobra.NoArgs,
+		RunE: runUpdateOperatorSettingsCmd,
+	}
+	f := cmd.Flags()
+	f.String(unregisterTokenUnlockWindowFlag, "", "The window in seconds before the token of an unregistered operator is unlocked")
+	f.String(requiredOperatorStakeFlag, "", "The stake in wei required to register an operator")
+	f.String(minOperatorCommissionFlag, "", "The minimal commission of an operator")
+	f.String(maxOperatorCommissionFlag, "", "The maximal commission of an operator")
+	return cmd
+}
+
+func runUpdateOperatorSettingsCmd(cmd *cobra.Command, _ []string) error {
+	return runOperatorTx(cmd, func(ctx context.Context, oc *mantastaking.OperatorClient) (*types.Receipt, error) {
+		settings, err := oc.QueryOperatorSettings(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query the current operator settings: %w", err)
+		}
+		for flag, value := range map[string]**big.Int{
+			unregisterTokenUnlockWindowFlag: &settings.UnregisterTokenUnlockWindow,
+			requiredOperatorStakeFlag:       &settings.RequiredOperatorStake,
+			minOperatorCommissionFlag:       &settings.MinOperatorCommission,
+			maxOperatorCommissionFlag:       &settings.MaxOperatorCommission,
+		} {
+			if !cmd.Flags().Changed(flag) {
+				continue
+			}
+			str, err := cmd.Flags().GetString(flag)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read flag %s: %w", flag, err)
+			}
+			v, ok := new(big.Int).SetString(str, 10)
+			if !ok || v.Sign() < 0 {
+				return nil, fmt.Errorf("invalid value of flag %s: %s", flag, str)
+			}
+			*value = v
+		}
+		return oc.UpdateOperatorSettings(ctx, *settings)
+	})
+}
+
+func commandClaimUnlockedToken() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "claim-unlocked-token",
+		Short:   "Claim the staked token of the unregistered operator after the unlock window.",
+		Example: `sfpd operator claim-unlocked-token --home /home/user/.sfpd`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runOperatorTx(cmd, func(ctx context.Context, oc *mantastaking.OperatorClient) (*types.Receipt, error) {
+				return oc.ClaimUnlockedToken(ctx)
+			})
+		},
+	}
+	return cmd
+}
+
+func commandOperatorInfo() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "info",
+		Short:   "Show the registration of an operator, defaults to the configured operator.",
+		Example: `sfpd operator info --operator 0x... --home /home/user/.sfpd`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			operatorStr, err := cmd.Flags().GetString(operatorFlag)
+			if err != nil {
+				return fmt.Errorf("failed to read flag %s: %w", operatorFlag, err)
+			}
+			return runOperatorQuery(cmd, func(ctx context.Context, oc *mantastaking.OperatorClient) (interface{}, error) {
+				operator := oc.WalletAddr
+				if operatorStr != "" {
+					operator, err = parseAddress(operatorStr)
+					if err != nil {
+						return nil, err
+					}
+				}
+				return oc.QueryOperatorInfo(ctx, operator)
+			})
+		},
+	}
+	cmd.Flags().String(operatorFlag, "", "The address of the operator to query")
+	return cmd
+}
+
+func commandOperatorSettings() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "settings",
+		Short:   "Show the operator settings of the middleware.",
+		Example: `sfpd operator settings --home /home/user/.sfpd`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runOperatorQuery(cmd, func(ctx context.Context, oc *mantastaking.OperatorClient) (interface{}, error) {
+				return oc.QueryOperatorSettings(ctx)
+			})
+		},
+	}
+	return cmd
+}
+
+func commandVaultSettings() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "vault-settings",
+		Short:   "Show the symbiotic vault settings of the middleware.",
+		Example: `sfpd operator vault-settings --home /home/user/.sfpd`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return runOperatorQuery(cmd, func(ctx context.Context, oc *mantastaking.OperatorClient) (interface{}, error) {
+				return oc.QuerySymbioticVaultSettings(ctx)
+			})
+		},
+	}
+	return cmd
+}
+
+func runOperatorTx(cmd *cobra.Command, send func(ctx context.Context, oc *mantastaking.OperatorClient) (*types.Receipt, error)) error {
+	oc, err := newOperatorClient(cmd)
+	if err != nil {
+		return err
+	}
+	receipt, err := send(cmd.Context(), oc)
+	if err != nil {
+		return fmt.Errorf("failed to send the operator transaction: %w", err)
+	}
+	printRespJSON(&txResponse{
+		TxHash:      receipt.TxHash.String(),
+		BlockNumber: receipt.BlockNumber.String(),
+		GasUsed:     receipt.GasUsed,
+		Success:     receipt.Status == types.ReceiptStatusSuccessful,
+	})
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("operator transaction %s reverted", receipt.TxHash.String())
+	}
+	return nil
+}
+
+func runOperatorQuery(cmd *cobra.Command, query func(ctx context.Context, oc *mantastaking.OperatorClient) (interface{}, error)) error {
+	oc, err := newOperatorClient(cmd)
+	if err != nil {
+		return err
+	}
+	res, err := query(cmd.Context(), oc)
+	if err != nil {
+		return err
+	}
+	printRespJSON(res)
+	return nil
+}
+
+// newOperatorClient loads sfpd.conf from the home directory and creates the
+// operator client signing with the configured operator signer
+func newOperatorClient(cmd *cobra.Command) (*mantastaking.OperatorClient, error) {
+	home, err := cmd.Flags().GetString(HomeFlag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read flag %s: %w", HomeFlag, err)
+	}
+	homePath, err := filepath.Abs(home)
+	if err != nil {
+		return nil, err
+	}
+	homePath = util.CleanAndExpandPath(homePath)
+
+	cfg, err := fpcfg.LoadConfig(homePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	logger, err := log.NewRootLoggerWithFile(fpcfg.LogFile(homePath), cfg.LogLevel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize the logger: %w", err)
+	}
+
+	operatorSigner, err := signer.NewSigner(cmd.Context(), cfg.SignerConfig, cfg.OpEventConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize the operator signer: %w", err)
+	}
+
+	mSMCfg, err := mantastaking.NewMantaStakingMiddlewareConfig(cmd.Context(), cfg, logger, operatorSigner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize the manta staking middleware config: %w", err)
+	}
+
+	return mantastaking.NewOperatorClient(mSMCfg, logger)
+}
+
+func parseAddress(str string) (common.Address, error) {
+	if !common.IsHexAddress(str) {
+		return common.Address{}, fmt.Errorf("invalid address: %s", str)
+	}
+	return common.HexToAddress(str), nil
+}
+
+func printRespJSON(resp interface{}) {
+	jsonBytes, err := json.MarshalIndent(resp, "", "    ")
+	if err != nil {
+		fmt.Println("unable to decode response: ", err)
+		return
+	}
+
+	fmt.Printf("%s\n", jsonBytes)
+}
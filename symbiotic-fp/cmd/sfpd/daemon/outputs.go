@@ -0,0 +1,96 @@
+package daemon
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Manta-Network/manta-fp/l2chain/opstack"
+	fpcfg "github.com/Manta-Network/manta-fp/symbiotic-fp/config"
+	"github.com/Manta-Network/manta-fp/util"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	indexFlag          = "index"
+	l2BlockFlag        = "l2-block"
+	fromIndexFlag      = "from-index"
+	toIndexFlag        = "to-index"
+	disputeGamesFlag   = "dispute-games"
+	metricsAddressFlag = "metrics-address"
+)
+
+// CommandOutputs returns the outputs command of sfpd
+func CommandOutputs() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "outputs",
+		Short: "Query the outputs indexed by the running sfpd and whether they were signed.",
+		Example: `sfpd outputs --index 100 --home /home/user/.sfpd
+sfpd outputs --from-index 100 --to-index 120 --home /home/user/.sfpd
+sfpd outputs --l2-block 360000 --home /home/user/.sfpd
+sfpd outputs --dispute-games --index 7 --home /home/user/.sfpd`,
+		Args: cobra.NoArgs,
+		RunE: runOutputsCmd,
+	}
+	cmd.Flags().Uint64(indexFlag, 0, "The L2 output index, or the game index with --dispute-games")
+	cmd.Flags().Uint64(l2BlockFlag, 0, "The L2 block of the outputs and the dispute games")
+	cmd.Flags().Uint64(fromIndexFlag, 0, "The first index of the queried range")
+	cmd.Flags().Uint64(toIndexFlag, 0, "The last index of the queried range")
+	cmd.Flags().Bool(disputeGamesFlag, false, "Query the dispute games instead of the L2OutputOracle outputs")
+	cmd.Flags().String(metricsAddressFlag, "", "The metrics address the sfpd queries are served at, read from sfpd.conf if empty")
+	return cmd
+}
+
+func runOutputsCmd(cmd *cobra.Command, _ []string) error {
+	home, err := cmd.Flags().GetString(HomeFlag)
+	if err != nil {
+		return fmt.Errorf("failed to read flag %s: %w", HomeFlag, err)
+	}
+	addr, err := cmd.Flags().GetString(metricsAddressFlag)
+	if err != nil {
+		return fmt.Errorf("failed to read flag %s: %w", metricsAddressFlag, err)
+	}
+	disputeGames, err := cmd.Flags().GetBool(disputeGamesFlag)
+	if err != nil {
+		return fmt.Errorf("failed to read flag %s: %w", disputeGamesFlag, err)
+	}
+
+	if addr == "" {
+		homePath, err := filepath.Abs(home)
+		if err != nil {
+			return err
+		}
+		cfg, err := fpcfg.LoadConfig(util.CleanAndExpandPath(homePath))
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		addr, err = cfg.Metrics.Address()
+		if err != nil {
+			return err
+		}
+	}
+
+	query := &opstack.OutputsQuery{}
+	for name, v := range map[string]**uint64{
+		indexFlag:     &query.Index,
+		l2BlockFlag:   &query.L2Block,
+		fromIndexFlag: &query.FromIndex,
+		toIndexFlag:   &query.ToIndex,
+	} {
+		if !cmd.Flags().Changed(name) {
+			continue
+		}
+		n, err := cmd.Flags().GetUint64(name)
+		if err != nil {
+			return fmt.Errorf("failed to read flag %s: %w", name, err)
+		}
+		*v = &n
+	}
+
+	outputs, err := opstack.QueryOutputs(cmd.Context(), addr, disputeGames, query)
+	if err != nil {
+		return fmt.Errorf("failed to query the outputs: %w", err)
+	}
+	printRespJSON(outputs)
+	return nil
+}
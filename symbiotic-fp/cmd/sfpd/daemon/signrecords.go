@@ -0,0 +1,153 @@
+package daemon
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	fpcfg "github.com/Manta-Network/manta-fp/symbiotic-fp/config"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/signer"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/store"
+	"github.com/Manta-Network/manta-fp/types"
+	"github.com/Manta-Network/manta-fp/util"
+
+	"github.com/spf13/cobra"
+)
+
+// CommandSignRecords returns the commands moving the output sign records between hosts in
+// the sign record interchange format shared with eotsd, sfpd must be stopped as they open
+// the database
+func CommandSignRecords() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "sign-records",
+		Short: "Export or import the output sign records in the sign record interchange format.",
+	}
+	cmd.AddCommand(commandSignRecordsExport(), commandSignRecordsImport())
+	return cmd
+}
+
+func commandSignRecordsExport() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "export [file]",
+		Short:   "Export the sign records of the operator to an interchange file.",
+		Long:    "Export the sign records of the operator configured in sfpd.conf to an interchange file. Stop sfpd before exporting so that no signature is missed.",
+		Example: `sfpd sign-records export sign-records.json --home /home/user/.sfpd`,
+		Args:    cobra.ExactArgs(1),
+		RunE:    runSignRecordsExportCmd,
+	}
+	return cmd
+}
+
+func commandSignRecordsImport() *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "import [file]",
+		Short: "Import the sign records of the operator from an interchange file.",
+		Long: "Import the sign records of the operator configured in sfpd.conf from an interchange file. The records are " +
+			"merged with the existing ones, the import is refused if a different state root is recorded at an imported index.",
+		Example: `sfpd sign-records import sign-records.json --home /home/user/.sfpd`,
+		Args:    cobra.ExactArgs(1),
+		RunE:    runSignRecordsImportCmd,
+	}
+	return cmd
+}
+
+func runSignRecordsExportCmd(cmd *cobra.Command, args []string) error {
+	env, err := openSignRecordStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer env.close()
+
+	history, err := env.store.ExportSignRecords(env.operatorAddr.Hex(), env.chainID)
+	if err != nil {
+		return fmt.Errorf("failed to export the sign records: %w", err)
+	}
+	interchange := types.NewSignInterchange(types.SigningSchemeECDSA)
+	interchange.Data = append(interchange.Data, history)
+	if err := interchange.Save(args[0]); err != nil {
+		return fmt.Errorf("failed to write the interchange file: %w", err)
+	}
+
+	cmd.Printf("exported %d sign records of %s to %s\n", len(history.SignedMessages), env.operatorAddr.Hex(), args[0])
+	return nil
+}
+
+func runSignRecordsImportCmd(cmd *cobra.Command, args []string) error {
+	interchange, err := types.LoadSignInterchange(args[0], types.SigningSchemeECDSA)
+	if err != nil {
+		return fmt.Errorf("failed to load the interchange file: %w", err)
+	}
+
+	env, err := openSignRecordStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer env.close()
+
+	// only the history of the configured operator on the configured chain is imported
+	var operatorHistory *types.InterchangeSigner
+	for _, history := range interchange.Data {
+		if common.IsHexAddress(history.PubKey) && common.HexToAddress(history.PubKey) == env.operatorAddr && history.ChainID == env.chainID {
+			operatorHistory = history
+			break
+		}
+	}
+	if operatorHistory == nil {
+		return fmt.Errorf("the interchange file has no sign records of %s on chain %s", env.operatorAddr.Hex(), env.chainID)
+	}
+
+	imported, skipped, err := env.store.ImportSignRecords(operatorHistory)
+	if err != nil {
+		return fmt.Errorf("failed to import the sign records: %w", err)
+	}
+	cmd.Printf("imported %d sign records, %d already recorded\n", imported, skipped)
+	return nil
+}
+
+// signRecordsEnv is the sign record store of the daemon along with the address of the
+// configured operator signer and the chain id the records are signed on
+type signRecordsEnv struct {
+	store        *store.SignRecordStore
+	operatorAddr common.Address
+	chainID      string
+	close        func()
+}
+
+func openSignRecordStore(cmd *cobra.Command) (*signRecordsEnv, error) {
+	home, err := cmd.Flags().GetString(HomeFlag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read flag %s: %w", HomeFlag, err)
+	}
+	homePath, err := filepath.Abs(home)
+	if err != nil {
+		return nil, err
+	}
+	homePath = util.CleanAndExpandPath(homePath)
+
+	cfg, err := fpcfg.LoadConfig(homePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	operatorSigner, err := signer.NewSigner(cmd.Context(), cfg.SignerConfig, cfg.OpEventConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize the operator signer: %w", err)
+	}
+
+	dbBackend, err := cfg.DatabaseConfig.GetDBBackend()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create db backend: %w", err)
+	}
+	signRecordStore, err := store.NewSignRecordStore(dbBackend)
+	if err != nil {
+		_ = dbBackend.Close()
+		return nil, fmt.Errorf("failed to initiate sign record store: %w", err)
+	}
+	return &signRecordsEnv{
+		store:        signRecordStore,
+		operatorAddr: operatorSigner.Address(),
+		chainID:      strconv.FormatUint(uint64(cfg.OpEventConfig.ChainId), 10),
+		close:        func() { _ = dbBackend.Close() },
+	}, nil
+}
@@ -8,6 +8,7 @@ import (
 	fpcfg "github.com/Manta-Network/manta-fp/symbiotic-fp/config"
 	"github.com/Manta-Network/manta-fp/symbiotic-fp/mantastaking"
 	"github.com/Manta-Network/manta-fp/symbiotic-fp/service"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/signer"
 	"github.com/Manta-Network/manta-fp/util"
 
 	"github.com/lightningnetwork/lnd/signal"
@@ -19,12 +20,13 @@ func CommandStart() *cobra.Command {
 	var cmd = &cobra.Command{
 		Use:     "start",
 		Short:   "Start the symbiotic-fp app daemon.",
-		Long:    `Start the symbiotic-fp app. Note that privateKey should be started beforehand`,
-		Example: `sfpd start --home /home/user/.sfpd --private-key abcd1234 `,
+		Long:    `Start the symbiotic-fp app. Note that the operator signer should be configured in sfpd.conf beforehand`,
+		Example: `sfpd start --home /home/user/.sfpd`,
 		Args:    cobra.NoArgs,
 		RunE:    runStartCmd,
 	}
-	cmd.Flags().String(PrivateKeyFlag, "", "The private key of the symbiotic-fp to sign")
+	cmd.Flags().String(PrivateKeyFlag, "", "The private key of the symbiotic-fp to sign, overrides the signer configured in sfpd.conf")
+	_ = cmd.Flags().MarkDeprecated(PrivateKeyFlag, "it leaks the key into the shell history, configure the signer in sfpd.conf instead")
 	cmd.Flags().String(AuthTokenFlag, "", "The auth token of celestia node")
 	return cmd
 }
@@ -69,9 +71,16 @@ func runStartCmd(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 
-	server := service.NewFinalityProviderServer(cfg, logger, dbBackend, shutdownInterceptor)
+	if priKey != "" {
+		cfg.SignerConfig.Type = fpcfg.SignerTypePrivateKey
+		cfg.SignerConfig.PrivateKey = priKey
+	}
+	operatorSigner, err := signer.NewSigner(cmd.Context(), cfg.SignerConfig, cfg.OpEventConfig)
+	if err != nil {
+		return fmt.Errorf("failed to initialize the operator signer: %w", err)
+	}
 
-	mSMCfg, err := mantastaking.NewMantaStakingMiddlewareConfig(cmd.Context(), cfg, logger, priKey)
+	mSMCfg, err := mantastaking.NewMantaStakingMiddlewareConfig(cmd.Context(), cfg, logger, operatorSigner)
 	if err != nil {
 		return fmt.Errorf("failed to initialize the manta staking middleware config: %w", err)
 	}
@@ -84,5 +93,10 @@ func runStartCmd(cmd *cobra.Command, _ []string) error {
 		return fmt.Errorf("failed to start the manta staking service: %w", err)
 	}
 
+	server := service.NewFinalityProviderServer(cfg, logger, dbBackend, shutdownInterceptor, mantaStakeServer)
+	if err := server.StartFinalityProviderServer(); err != nil {
+		return fmt.Errorf("failed to start the symbiotic-fp server: %w", err)
+	}
+
 	return server.RunUntilShutdown()
 }
@@ -27,7 +27,8 @@ func NewRootCmd() *cobra.Command {
 func main() {
 	cmd := NewRootCmd()
 	cmd.AddCommand(
-		daemon.CommandInit(), daemon.CommandStart(),
+		daemon.CommandInit(), daemon.CommandStart(), daemon.CommandOperator(), daemon.CommandDA(), daemon.CommandOutputs(),
+		daemon.CommandSignRecords(),
 		version.CommandVersion("sfpd"),
 	)
 
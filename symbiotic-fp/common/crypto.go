@@ -131,18 +131,27 @@ func PrivateKeySignerFn(key *ecdsa.PrivateKey, chainID *big.Int) bind.SignerFn {
 	}
 }
 
-func NewHSMTransactOpts(ctx context.Context, hsmAPIName string, hsmAddress string, chainID *big.Int, hsmCreden string) (*bind.TransactOpts, error) {
+func NewHSMManagedKey(ctx context.Context, hsmAPIName string, hsmAddress string, hsmCreden string) (*hsm.ManagedKey, error) {
 	proBytes, err := hex.DecodeString(hsmCreden)
+	if err != nil {
+		return nil, err
+	}
 	apikey := option.WithCredentialsJSON(proBytes)
 	client, err := kms.NewKeyManagementClient(ctx, apikey)
 	if err != nil {
 		return nil, err
 	}
-	mk := &hsm.ManagedKey{
+	return &hsm.ManagedKey{
 		KeyName:      hsmAPIName,
 		EthereumAddr: common.HexToAddress(hsmAddress),
 		Gclient:      client,
+	}, nil
+}
+
+func NewHSMTransactOpts(ctx context.Context, hsmAPIName string, hsmAddress string, chainID *big.Int, hsmCreden string) (*bind.TransactOpts, error) {
+	mk, err := NewHSMManagedKey(ctx, hsmAPIName, hsmAddress, hsmCreden)
+	if err != nil {
+		return nil, err
 	}
-	opts, err := mk.NewEthereumTransactorrWithChainID(ctx, chainID)
-	return opts, nil
+	return mk.NewEthereumTransactorrWithChainID(ctx, chainID)
 }
@@ -1,6 +1,21 @@
 package config
 
-import "time"
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// DABackendCelestia submits the blobs to a celestia node through DaRpc
+	DABackendCelestia = "celestia"
+	// DABackendFile keeps the blobs in a local append-only file
+	DABackendFile = "file"
+	// DABackendMemory keeps the blobs in memory, they are lost on restart
+	DABackendMemory = "memory"
+
+	defaultDAFileName = "da.jsonl"
+)
 
 var (
 	defaultNamespace = "00000000000000000000"
@@ -8,15 +23,38 @@ var (
 )
 
 type CelestiaConfig struct {
+	Backend   string        `long:"backend" description:"The data availability backend, file and memory are local backends for devnets and tests" choice:"celestia" choice:"file" choice:"memory"`
 	Namespace string        `long:"namespace" description:"Namespace ID for DA node"`
 	DaRpc     string        `long:"da_rpc" description:"Dial address of data availability grpc client"`
 	Timeout   time.Duration `long:"time_out" description:"Timeout for celestia requests"`
+	FilePath  string        `long:"file_path" description:"The append-only file of the file backend"`
 }
 
 func DefaultCelestiaConfig() CelestiaConfig {
 	return CelestiaConfig{
+		Backend:   DABackendCelestia,
 		Namespace: defaultNamespace,
 		DaRpc:     "",
 		Timeout:   defaultTimeout,
 	}
 }
+
+func DefaultCelestiaConfigWithHomePath(homePath string) CelestiaConfig {
+	cfg := DefaultCelestiaConfig()
+	cfg.FilePath = filepath.Join(DataDir(homePath), defaultDAFileName)
+	return cfg
+}
+
+func (cfg *CelestiaConfig) Validate() error {
+	switch cfg.Backend {
+	// the config files written before the backend was selectable use celestia
+	case "", DABackendCelestia, DABackendMemory:
+	case DABackendFile:
+		if cfg.FilePath == "" {
+			return fmt.Errorf("file_path is required by the %s backend", DABackendFile)
+		}
+	default:
+		return fmt.Errorf("unsupported da backend %q", cfg.Backend)
+	}
+	return nil
+}
@@ -44,12 +44,15 @@ type Config struct {
 
 	CelestiaConfig *CelestiaConfig `group:"celestiaconfig" namespace:"celestiaconfig"`
 
+	SignerConfig *SignerConfig `group:"signerconfig" namespace:"signerconfig"`
+
 	Metrics *metrics.Config `group:"metrics" namespace:"metrics"`
 }
 
 func DefaultConfigWithHome(homePath string) Config {
 	opEventConfig := DefaultOpEventConfig()
-	celestiaConfig := DefaultCelestiaConfig()
+	celestiaConfig := DefaultCelestiaConfigWithHomePath(homePath)
+	signerConfig := DefaultSignerConfig()
 	cfg := Config{
 		SignatureSubmissionInterval: defaultSignatureSubmissionInterval,
 		SubmissionRetryInterval:     defaultSubmitRetryInterval,
@@ -61,6 +64,7 @@ func DefaultConfigWithHome(homePath string) Config {
 		DatabaseConfig:              DefaultDBConfigWithHomePath(homePath),
 		OpEventConfig:               &opEventConfig,
 		CelestiaConfig:              &celestiaConfig,
+		SignerConfig:                &signerConfig,
 		Metrics:                     metrics.DefaultFpConfig(),
 	}
 
@@ -118,5 +122,29 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("invalid metrics config")
 	}
 
+	if cfg.OpEventConfig == nil {
+		return fmt.Errorf("empty op event config")
+	}
+
+	if err := cfg.OpEventConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid op event config: %w", err)
+	}
+
+	if cfg.CelestiaConfig == nil {
+		return fmt.Errorf("empty celestia config")
+	}
+
+	if err := cfg.CelestiaConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid celestia config: %w", err)
+	}
+
+	if cfg.SignerConfig == nil {
+		return fmt.Errorf("empty signer config")
+	}
+
+	if err := cfg.SignerConfig.Validate(); err != nil {
+		return fmt.Errorf("invalid signer config: %w", err)
+	}
+
 	return nil
 }
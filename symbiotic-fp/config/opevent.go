@@ -1,10 +1,16 @@
 package config
 
 import (
+	"fmt"
 	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Manta-Network/manta-fp/ethereum/node"
 )
 
 var (
+	defaultHeadMode                  = string(node.HeadModeLatest)
 	defaultScanSize                  = uint32(500)
 	defaultEthRpc                    = "http://127.0.0.1:8545"
 	defaultEthAddr                   = "0x0"
@@ -12,6 +18,7 @@ var (
 	defaultStartHeight               = uint64(1)
 	defaultNumConfirmations          = uint64(10)
 	defaultSafeAbortNonceTooLowCount = uint64(3)
+	defaultFeeBumpPercent            = uint64(10)
 )
 
 type OpEventConfig struct {
@@ -20,16 +27,26 @@ type OpEventConfig struct {
 	BlockStep                        uint64        `long:"block_step" description:"The block step of chain blocks scan"`
 	BufferSize                       uint32        `long:"buffer_size" description:"The maximum number of ethereum blocks that can be stored in the buffer"`
 	EthRpc                           string        `long:"eth_rpc" description:"The rpc uri of ethereum"`
+	EthRpcs                          []string      `long:"eth_rpcs" description:"The rpc uris of ethereum the chain events and the contracts are read from, used instead of eth_rpc when set; the reads fail over between the endpoints and the transactions are sent to all of them"`
+	EthRpcQuorum                     uint          `long:"eth_rpc_quorum" description:"The number of the eth_rpcs endpoints that must return the same block hashes and logs before they are used, 0 or 1 disables the quorum"`
+	L2Rpc                            string        `long:"l2_rpc" description:"The rpc uri of an L2 op-node or execution node, the proposed output roots are verified against it before signing when it's set"`
 	NumConfirmations                 uint64        `long:"num_confirmations" description:"Specifies how many blocks are need to consider a transaction confirmed."`
 	SafeAbortNonceTooLowCount        uint64        `long:"safe_abort_nonce_too_low_count" description:"Specifies how many ErrNonceTooLow observations are required to give up on a tx at a particular nonce without receiving confirmation."`
+	FeeBumpPercent                   uint64        `long:"fee_bump_percent" description:"The percentage by which the fees are raised on every resubmission of a transaction, nodes usually require at least 10"`
+	MaxGasFeeCapGwei                 uint64        `long:"max_gas_fee_cap_gwei" description:"The max fee cap in gwei that a resubmitted transaction may pay, 0 means no cap"`
 	L2OutputOracleAddr               string        `long:"l2_output_oracle_addr" description:"The contract address of L2OutputOracle address"`
+	IndexDisputeGames                bool          `long:"index_dispute_games" description:"Whether to index the games created by the DisputeGameFactory and sign their root claims along with the L2OutputOracle outputs"`
+	DisputeGameFactoryAddr           string        `long:"dispute_game_factory_addr" description:"The contract address of DisputeGameFactory, required when the dispute games are indexed"`
 	PollInterval                     time.Duration `long:"poll_interval" description:"The interval between each polling of blocks; the value should be set depending on the block production time but could be set smaller for quick catching up"`
+	HeadMode                         string        `long:"head_mode" description:"The L1 head the chain is polled up to, the safe and finalized heads trade latency for reorg safety" choice:"latest" choice:"safe" choice:"finalized"`
+	ConfirmationDepth                uint64        `long:"confirmation_depth" description:"The number of blocks below the head that are not polled yet"`
 	EnableHsm                        bool          `long:"enable_hsm" description:"Whether to use cloud hsm"`
 	HsmApiName                       string        `long:"hsm_api_name" description:"The api name of hsm"`
 	HsmCreden                        string        `long:"hsm_creden" description:"The creden of hsm"`
 	HsmAddress                       string        `long:"hsm_address" description:"The address of hsm"`
 	MantaStakingMiddlewareAddress    string        `long:"manta_staking_middleware_address" description:"the contract address of the manta-staking-middleware"`
 	SymbioticOperatorRegisterAddress string        `long:"symbiotic_operator_register_address" description:"the contract address of the symbiotic_operator_register_address"`
+	FinalitySignatureInboxAddress    string        `long:"finality_signature_inbox_address" description:"the contract address that receives finality signatures on ethereum when celestia submission is unavailable"`
 }
 
 func DefaultOpEventConfig() OpEventConfig {
@@ -40,12 +57,42 @@ func DefaultOpEventConfig() OpEventConfig {
 		L2OutputOracleAddr:               defaultEthAddr,
 		MantaStakingMiddlewareAddress:    defaultEthAddr,
 		SymbioticOperatorRegisterAddress: defaultEthAddr,
+		FinalitySignatureInboxAddress:    defaultEthAddr,
 		PollInterval:                     defaultPollInterval,
+		HeadMode:                         defaultHeadMode,
 		NumConfirmations:                 defaultNumConfirmations,
 		SafeAbortNonceTooLowCount:        defaultSafeAbortNonceTooLowCount,
+		FeeBumpPercent:                   defaultFeeBumpPercent,
 		EnableHsm:                        false,
 		HsmApiName:                       "",
 		HsmAddress:                       "",
 		HsmCreden:                        "",
 	}
 }
+
+// EthRpcUrls returns the rpc uris the chain events are read from
+func (cfg *OpEventConfig) EthRpcUrls() []string {
+	if len(cfg.EthRpcs) > 0 {
+		return cfg.EthRpcs
+	}
+	return []string{cfg.EthRpc}
+}
+
+func (cfg *OpEventConfig) Validate() error {
+	if _, err := node.ParseHeadMode(cfg.HeadMode); err != nil {
+		return err
+	}
+	if int(cfg.EthRpcQuorum) > len(cfg.EthRpcUrls()) {
+		return fmt.Errorf("the eth rpc quorum %d is above the number of eth rpc uris %d", cfg.EthRpcQuorum, len(cfg.EthRpcUrls()))
+	}
+	if cfg.IndexDisputeGames {
+		if !common.IsHexAddress(cfg.DisputeGameFactoryAddr) || common.HexToAddress(cfg.DisputeGameFactoryAddr) == (common.Address{}) {
+			return fmt.Errorf("invalid dispute game factory address %q, it's required when the dispute games are indexed", cfg.DisputeGameFactoryAddr)
+		}
+		// anyone can create a dispute game, its root claim is only checked against an L2 node
+		if cfg.L2Rpc == "" {
+			return fmt.Errorf("the l2_rpc is required when the dispute games are indexed")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+)
+
+const (
+	SignerTypePrivateKey = "private_key"
+	SignerTypeKeystore   = "keystore"
+	SignerTypeMnemonic   = "mnemonic"
+	SignerTypeHsm        = "hsm"
+
+	defaultHdPath = "m/44'/60'/0'/0/0"
+)
+
+// SignerConfig selects the key used to sign transactions and finality signatures.
+// The cloud hsm is configured by the hsm fields of the opeventconfig group
+type SignerConfig struct {
+	Type               string `long:"type" description:"The type of the operator signer" choice:"private_key" choice:"keystore" choice:"mnemonic" choice:"hsm"`
+	PrivateKey         string `long:"private_key" description:"The hex encoded private key, used by the private_key signer"`
+	KeystorePath       string `long:"keystore_path" description:"The path of the encrypted V3 keystore file, used by the keystore signer"`
+	KeystorePassphrase string `long:"keystore_passphrase" description:"The passphrase to decrypt the keystore file, used by the keystore signer"`
+	Mnemonic           string `long:"mnemonic" description:"The BIP-39 mnemonic, used by the mnemonic signer"`
+	HdPath             string `long:"hd_path" description:"The HD derivation path of the key, used by the mnemonic signer"`
+	MnemonicPassphrase string `long:"mnemonic_passphrase" description:"The optional BIP-39 passphrase of the mnemonic, used by the mnemonic signer"`
+}
+
+func DefaultSignerConfig() SignerConfig {
+	return SignerConfig{
+		Type:   SignerTypePrivateKey,
+		HdPath: defaultHdPath,
+	}
+}
+
+func (cfg *SignerConfig) Validate() error {
+	switch cfg.Type {
+	case SignerTypePrivateKey, SignerTypeHsm:
+	case SignerTypeKeystore:
+		if cfg.KeystorePath == "" {
+			return fmt.Errorf("keystore path is required by the %s signer", cfg.Type)
+		}
+	case SignerTypeMnemonic:
+		if cfg.Mnemonic == "" || cfg.HdPath == "" {
+			return fmt.Errorf("mnemonic and hd path are required by the %s signer", cfg.Type)
+		}
+	default:
+		return fmt.Errorf("invalid signer type: %s", cfg.Type)
+	}
+	return nil
+}
@@ -2,7 +2,11 @@ package hsm
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/x509/pkix"
 	"encoding/asn1"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"math/big"
 
@@ -120,6 +124,39 @@ func (mk *ManagedKey) SignHash(ctx context.Context, hash common.Hash) ([]byte, e
 	return nil, fmt.Errorf("Google KMS asymmetric signature address recovery mis: %w", recoverErr)
 }
 
+// PublicKey fetches the public key of the managed key from Google KMS and checks
+// that it matches the configured ethereum address
+func (mk *ManagedKey) PublicKey(ctx context.Context) (*ecdsa.PublicKey, error) {
+	resp, err := mk.Gclient.GetPublicKey(ctx, &kmspb.GetPublicKeyRequest{Name: mk.KeyName})
+	if err != nil {
+		return nil, fmt.Errorf("Google KMS public key retrieval: %w", err)
+	}
+
+	block, _ := pem.Decode([]byte(resp.Pem))
+	if block == nil {
+		return nil, errors.New("Google KMS public key is not PEM encoded")
+	}
+
+	// secp256k1 is not supported by crypto/x509, decode the SubjectPublicKeyInfo by hand
+	var info struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(block.Bytes, &info); err != nil {
+		return nil, fmt.Errorf("Google KMS public key encoding: %w", err)
+	}
+
+	pubKey, err := crypto.UnmarshalPubkey(info.PublicKey.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("Google KMS public key is not a secp256k1 key: %w", err)
+	}
+	if crypto.PubkeyToAddress(*pubKey) != mk.EthereumAddr {
+		return nil, fmt.Errorf("Google KMS public key does not match address %s", mk.EthereumAddr.String())
+	}
+
+	return pubKey, nil
+}
+
 func pubKeyAddr(bytes []byte) common.Address {
 	digest := crypto.Keccak256(bytes[1:])
 	var addr common.Address
@@ -0,0 +1,323 @@
+package mantastaking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/rollkit/go-da"
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+
+	"github.com/Manta-Network/manta-fp/bindings"
+	"github.com/Manta-Network/manta-fp/l2chain/opstack"
+	"github.com/Manta-Network/manta-fp/metrics"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/celestia"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/config"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/signer"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/store"
+	types2 "github.com/Manta-Network/manta-fp/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testMantaStakingAddr = common.HexToAddress("0x1000")
+	testInboxAddr        = common.HexToAddress("0x2000")
+	testVaultAddr        = common.HexToAddress("0x3000")
+	testNamespace        = da.Namespace{0, 0, 0, 0, 0, 0, 0, 0, 0, 1}
+)
+
+// testBlockTime is the L1 block time of the fake backend, the block n is at n*testBlockTime
+const testBlockTime = 12
+
+// fakeBackend serves the manta staking middleware and the vault of the operator, the
+// sent transactions are mined at once. The state of the past blocks is pruned as by a
+// non-archive node
+type fakeBackend struct {
+	mu sync.Mutex
+
+	vault             common.Address
+	vaultQueries      int
+	epochDurationInit uint64
+	epochDuration     uint64
+	stake             *big.Int
+	latestBlock       uint64
+	// callErr fails the contract calls
+	callErr error
+
+	stakeQueries []uint64
+	sent         []*types.Transaction
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{
+		vault:         testVaultAddr,
+		epochDuration: 100,
+		stake:         big.NewInt(1),
+		latestBlock:   1000,
+	}
+}
+
+func (b *fakeBackend) setCallErr(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.callErr = err
+}
+
+func (b *fakeBackend) setStake(stake *big.Int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stake = stake
+}
+
+func (b *fakeBackend) sentTxs() []*types.Transaction {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]*types.Transaction{}, b.sent...)
+}
+
+func (b *fakeBackend) CodeAt(context.Context, common.Address, *big.Int) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (b *fakeBackend) CallContract(_ context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.callErr != nil {
+		return nil, b.callErr
+	}
+	if blockNumber != nil && blockNumber.Uint64() < b.latestBlock {
+		return nil, errors.New("missing trie node")
+	}
+	mantaStakingABI, err := bindings.MantaStakingMiddlewareMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	parsedVaultABI, err := vaultABI()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		method *abi.Method
+		out    []interface{}
+	)
+	switch {
+	case *call.To == testMantaStakingAddr:
+		method, err = mantaStakingABI.MethodById(call.Data)
+		if err != nil || method.Name != "operators" {
+			return nil, fmt.Errorf("unexpected middleware call %x", call.Data)
+		}
+		b.vaultQueries++
+		out = []interface{}{b.vault, false, "operator", common.Address{}, big.NewInt(0)}
+	case *call.To == b.vault:
+		method, err = parsedVaultABI.MethodById(call.Data)
+		if err != nil {
+			return nil, err
+		}
+		switch method.Name {
+		case "epochDurationInit":
+			out = []interface{}{new(big.Int).SetUint64(b.epochDurationInit)}
+		case "epochDuration":
+			out = []interface{}{new(big.Int).SetUint64(b.epochDuration)}
+		case "activeStakeAt":
+			args, err := method.Inputs.Unpack(call.Data[4:])
+			if err != nil {
+				return nil, err
+			}
+			b.stakeQueries = append(b.stakeQueries, args[0].(*big.Int).Uint64())
+			out = []interface{}{b.stake}
+		default:
+			out = []interface{}{b.stake}
+		}
+	default:
+		return nil, fmt.Errorf("unexpected call to %s", call.To.String())
+	}
+	return method.Outputs.Pack(out...)
+}
+
+func (b *fakeBackend) HeaderByNumber(_ context.Context, number *big.Int) (*types.Header, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if number == nil {
+		number = new(big.Int).SetUint64(b.latestBlock)
+	}
+	return &types.Header{Number: number, Time: number.Uint64() * testBlockTime, BaseFee: big.NewInt(1)}, nil
+}
+
+func (b *fakeBackend) PendingCodeAt(context.Context, common.Address) ([]byte, error) {
+	return []byte{1}, nil
+}
+
+func (b *fakeBackend) PendingNonceAt(context.Context, common.Address) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return uint64(len(b.sent)), nil
+}
+
+func (b *fakeBackend) SuggestGasPrice(context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (b *fakeBackend) SuggestGasTipCap(context.Context) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (b *fakeBackend) EstimateGas(context.Context, ethereum.CallMsg) (uint64, error) {
+	return 100000, nil
+}
+
+func (b *fakeBackend) SendTransaction(_ context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sent = append(b.sent, tx)
+	return nil
+}
+
+func (b *fakeBackend) FilterLogs(context.Context, ethereum.FilterQuery) ([]types.Log, error) {
+	return nil, nil
+}
+
+func (b *fakeBackend) SubscribeFilterLogs(context.Context, ethereum.FilterQuery, chan<- types.Log) (ethereum.Subscription, error) {
+	return nil, errors.New("subscriptions are not supported")
+}
+
+func (b *fakeBackend) BlockNumber(context.Context) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.latestBlock, nil
+}
+
+func (b *fakeBackend) TransactionReceipt(_ context.Context, txHash common.Hash) (*types.Receipt, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, tx := range b.sent {
+		if tx.Hash() == txHash {
+			return &types.Receipt{
+				Status:      types.ReceiptStatusSuccessful,
+				TxHash:      txHash,
+				BlockNumber: new(big.Int).SetUint64(b.latestBlock),
+			}, nil
+		}
+	}
+	return nil, ethereum.NotFound
+}
+
+func (b *fakeBackend) BalanceAt(context.Context, common.Address, *big.Int) (*big.Int, error) {
+	return big.NewInt(1), nil
+}
+
+func (b *fakeBackend) Close() {}
+
+// failingDA is a DA backend whose submissions fail
+type failingDA struct {
+	*celestia.LocalDA
+}
+
+func (d *failingDA) Submit(context.Context, []da.Blob, float64, da.Namespace) ([]da.ID, error) {
+	return nil, errors.New("celestia is unavailable")
+}
+
+// newTestMiddleware returns the middleware of an active operator over the backend, the
+// blocks are indexed into the returned store
+func newTestMiddleware(t *testing.T, backend *fakeBackend, daClient *celestia.DAClient) (*MantaStakingMiddleware, *opstack.OpStateRootStore) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	mCfg := &MantaStakingMiddlewareConfig{
+		EthClient:                  backend,
+		ChainID:                    big.NewInt(1),
+		MantaStakingMiddlewareAddr: testMantaStakingAddr,
+		FinalitySignatureInboxAddr: testInboxAddr,
+		Signer:                     signer.NewPrivateKeySigner(key),
+		PublicKey:                  &key.PublicKey,
+		NumConfirmations:           1,
+		SafeAbortNonceTooLowCount:  3,
+		FeeBumpPercent:             10,
+		MaxGasFeeCap:               big.NewInt(1e18),
+	}
+	operatorClient, err := NewOperatorClient(mCfg, zap.NewNop())
+	require.NoError(t, err)
+	inbox, rawInbox, err := newFinalitySignatureInboxContract(mCfg)
+	require.NoError(t, err)
+
+	dbBackend, err := config.DefaultDBConfigWithHomePath(t.TempDir()).GetDBBackend()
+	require.NoError(t, err)
+	t.Cleanup(func() { dbBackend.Close() })
+	sRStore, err := opstack.NewOpStateRootStore(dbBackend)
+	require.NoError(t, err)
+	signRecordStore, err := store.NewSignRecordStore(dbBackend)
+	require.NoError(t, err)
+	daRefStore, err := store.NewDARefStore(dbBackend)
+	require.NoError(t, err)
+
+	operatorEvents, err := newOperatorEventHandler(testMantaStakingAddr, operatorClient.WalletAddr, 10, zap.NewNop())
+	require.NoError(t, err)
+
+	return &MantaStakingMiddleware{
+		OperatorClient:                    operatorClient,
+		Ctx:                               context.Background(),
+		FinalitySignatureInboxContract:    inbox,
+		RawFinalitySignatureInboxContract: rawInbox,
+		sfpMetrics:                        metrics.NewSfpMetrics(),
+		SRStore:                           sRStore,
+		SignRecordStore:                   signRecordStore,
+		DARefStore:                        daRefStore,
+		DAClient:                          daClient,
+		operatorEvents:                    operatorEvents,
+		bufferSize:                        10,
+		signDomain: &types2.SignDomain{
+			ChainID:                    mCfg.ChainID,
+			MantaStakingMiddlewareAddr: testMantaStakingAddr,
+		},
+		SignatureSubmissionInterval: 10 * time.Millisecond,
+		SubmissionRetryInterval:     10 * time.Millisecond,
+		MaxSubmissionRetries:        1,
+		submissions:                 metrics.NewSubmissionTracker(time.Minute),
+		operatorStatus:              atomic.NewInt32(int32(types2.OperatorStatusActive)),
+		isStarted:                   atomic.NewBool(false),
+	}, sRStore
+}
+
+func testOutput(l1BlockNumber uint64, l2OutputIndex int64, stateRoot byte) *types2.StateRoot {
+	return &types2.StateRoot{
+		StateRoot:     [32]byte{stateRoot},
+		L2BlockNumber: big.NewInt(100 * l2OutputIndex),
+		L2OutputIndex: big.NewInt(l2OutputIndex),
+		L1BlockNumber: l1BlockNumber,
+	}
+}
+
+func testBlock(l1BlockNumber uint64, l2OutputIndex int64, stateRoot byte) *types2.BlockInfo {
+	return &types2.BlockInfo{
+		Height:    l1BlockNumber,
+		StateRoot: *testOutput(l1BlockNumber, l2OutputIndex, stateRoot),
+	}
+}
+
+// submittedSignatures decodes the signatures submitted to the inbox by the transactions
+func submittedSignatures(t *testing.T, txs []*types.Transaction) []bindings.IFinalitySignatureInboxFinalitySignature {
+	inboxABI, err := bindings.FinalitySignatureInboxMetaData.GetAbi()
+	require.NoError(t, err)
+	var signatures []bindings.IFinalitySignatureInboxFinalitySignature
+	for _, tx := range txs {
+		require.Equal(t, testInboxAddr, *tx.To())
+		method, err := inboxABI.MethodById(tx.Data())
+		require.NoError(t, err)
+		args, err := method.Inputs.Unpack(tx.Data()[4:])
+		require.NoError(t, err)
+		var batch []bindings.IFinalitySignatureInboxFinalitySignature
+		require.NoError(t, method.Inputs.Copy(&batch, args))
+		signatures = append(signatures, batch...)
+	}
+	return signatures
+}
@@ -0,0 +1,103 @@
+package mantastaking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Manta-Network/manta-fp/bindings"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/txmgr"
+	types2 "github.com/Manta-Network/manta-fp/types"
+)
+
+var (
+	ErrFinalitySignatureInboxNotConfigured = errors.New("finality signature inbox address is not configured")
+)
+
+// newFinalitySignatureInboxContract binds the contract that accepts the finality signatures
+// on ethereum when celestia is not available, nil is returned if it's not configured
+func newFinalitySignatureInboxContract(mCfg *MantaStakingMiddlewareConfig) (*bindings.FinalitySignatureInbox, *bind.BoundContract, error) {
+	if mCfg.FinalitySignatureInboxAddr == (common.Address{}) {
+		return nil, nil, nil
+	}
+	finalitySignatureInboxContract, err := bindings.NewFinalitySignatureInbox(mCfg.FinalitySignatureInboxAddr, mCfg.EthClient)
+	if err != nil {
+		return nil, nil, err
+	}
+	parsed, err := abi.JSON(strings.NewReader(
+		bindings.FinalitySignatureInboxMetaData.ABI,
+	))
+	if err != nil {
+		return nil, nil, err
+	}
+	rawFinalitySignatureInboxContract := bind.NewBoundContract(
+		mCfg.FinalitySignatureInboxAddr, parsed, mCfg.EthClient, mCfg.EthClient,
+		mCfg.EthClient,
+	)
+	return finalitySignatureInboxContract, rawFinalitySignatureInboxContract, nil
+}
+
+// finalitySignatures returns the sign requests as the typed signatures of the inbox
+// contract, so that the contract can check the signer and the signed output
+func finalitySignatures(signRequests []types2.SignRequest) ([]bindings.IFinalitySignatureInboxFinalitySignature, error) {
+	signatures := make([]bindings.IFinalitySignatureInboxFinalitySignature, 0, len(signRequests))
+	for _, signRequest := range signRequests {
+		stateRoot, err := types2.DecodeHash(signRequest.StateRoot)
+		if err != nil {
+			return nil, fmt.Errorf("invalid state root %q: %w", signRequest.StateRoot, err)
+		}
+		var l1BlockHash common.Hash
+		if signRequest.L1BlockHash != "" {
+			l1BlockHash, err = types2.DecodeHash(signRequest.L1BlockHash)
+			if err != nil {
+				return nil, fmt.Errorf("invalid l1 block hash %q: %w", signRequest.L1BlockHash, err)
+			}
+		}
+		signature := bindings.IFinalitySignatureInboxFinalitySignature{
+			Version:       signRequest.Version,
+			StateRoot:     stateRoot,
+			L2BlockNumber: signRequest.L2BlockNumber,
+			L2OutputIndex: signRequest.L2OutputIndex,
+			L1BlockHash:   l1BlockHash,
+			Signature:     signRequest.Signature,
+		}
+		if signRequest.IsDisputeGame() {
+			signature.DisputeGameProxy = common.HexToAddress(signRequest.DisputeGameProxy)
+			signature.GameType = uint32(signRequest.DisputeGameType)
+		}
+		signatures = append(signatures, signature)
+	}
+	return signatures, nil
+}
+
+// submitToEth sends the signatures to the finality signature inbox contract and waits
+// for the transaction to be confirmed
+func (msm *MantaStakingMiddleware) submitToEth(ctx context.Context, signRequests []types2.SignRequest) (*types.Receipt, error) {
+	if msm.FinalitySignatureInboxContract == nil {
+		return nil, ErrFinalitySignatureInboxNotConfigured
+	}
+	signatures, err := finalitySignatures(signRequests)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := msm.sendTx(ctx, msm.RawFinalitySignatureInboxContract, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return msm.FinalitySignatureInboxContract.SubmitFinalitySignatures(opts, signatures)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("finality signature transaction %s reverted", receipt.TxHash.String())
+	}
+	return receipt, nil
+}
+
+func (msm *MantaStakingMiddleware) UpdateFinalitySignatureGasPrice(ctx context.Context, tx *types.Transaction, feeBumper *txmgr.FeeBumper) (*types.Transaction, error) {
+	return msm.updateGasPrice(ctx, msm.RawFinalitySignatureInboxContract, tx, feeBumper)
+}
@@ -0,0 +1,58 @@
+package mantastaking
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/celestia"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/store"
+	types2 "github.com/Manta-Network/manta-fp/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmitFallsBackToEthWhenCelestiaFails(t *testing.T) {
+	t.Parallel()
+
+	backend := newFakeBackend()
+	msm, _ := newTestMiddleware(t, backend, &celestia.DAClient{
+		Backend:    "memory",
+		Client:     &failingDA{LocalDA: celestia.NewMemoryDA()},
+		Namespace:  testNamespace,
+		GetTimeout: time.Second,
+	})
+
+	blocks := []*types2.BlockInfo{testBlock(500, 1, 1), testBlock(501, 2, 2)}
+	err := msm.SubmitBatchFinalitySignatures(context.Background(), blocks)
+	require.NoError(t, err)
+
+	// the signatures are submitted to the inbox in a single transaction
+	txs := backend.sentTxs()
+	require.Len(t, txs, 1)
+	signatures := submittedSignatures(t, txs)
+	require.Len(t, signatures, 2)
+	for i, signature := range signatures {
+		require.Equal(t, blocks[i].StateRoot.StateRoot, signature.StateRoot)
+		require.Equal(t, blocks[i].L2OutputIndex, signature.L2OutputIndex)
+		require.Equal(t, types2.SignRequestVersionTyped, signature.Version)
+
+		signRequest := &types2.SignRequest{
+			Version:       signature.Version,
+			StateRoot:     common.Hash(signature.StateRoot).Hex(),
+			Signature:     signature.Signature,
+			SignAddress:   msm.WalletAddr.String(),
+			L2BlockNumber: signature.L2BlockNumber,
+			L2OutputIndex: signature.L2OutputIndex,
+			L1BlockHash:   common.Hash(signature.L1BlockHash).Hex(),
+		}
+		require.NoError(t, types2.VerifySignRequest(signRequest, msm.signDomain))
+	}
+
+	// nothing was published to celestia
+	_, found, err := msm.DARefStore.GetDARef(store.RecordIndex(1, false))
+	require.NoError(t, err)
+	require.False(t, found)
+}
@@ -0,0 +1,38 @@
+package mantastaking
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Manta-Network/manta-fp/metrics"
+	types2 "github.com/Manta-Network/manta-fp/types"
+)
+
+// RegisterHealthChecks registers the health of the indexer, the operator, the eth rpc,
+// the signature submissions and the output verifier on the metrics server
+func (msm *MantaStakingMiddleware) RegisterHealthChecks(s *metrics.Server) {
+	s.AddLivenessCheck("indexer", msm.Indexer.CheckHealth)
+	s.AddReadinessCheck("operator", msm.checkOperator)
+	s.AddReadinessCheck("eth_rpc", msm.checkEthRpc)
+	s.AddReadinessCheck("submission", msm.submissions.Check)
+	if msm.outputVerifier != nil {
+		s.AddReadinessCheck("output_verifier", msm.outputVerifier.CheckHealth)
+	}
+}
+
+// checkOperator fails while the signing is suspended
+func (msm *MantaStakingMiddleware) checkOperator(_ context.Context) (string, error) {
+	status := msm.OperatorStatus()
+	if status != types2.OperatorStatusActive {
+		return status.String(), fmt.Errorf("the signing is suspended while the operator is %s", status.String())
+	}
+	return status.String(), nil
+}
+
+func (msm *MantaStakingMiddleware) checkEthRpc(ctx context.Context) (string, error) {
+	latestBlock, err := msm.Cfg.EthClient.BlockNumber(ctx)
+	if err != nil {
+		return "", fmt.Errorf("the eth rpc is unreachable: %w", err)
+	}
+	return fmt.Sprintf("latest block %d", latestBlock), nil
+}
@@ -1,32 +1,25 @@
 package mantastaking
 
 import (
-	"bytes"
 	"context"
-	"crypto/ecdsa"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"math/big"
-	"strings"
 	"sync"
 	"time"
 
-	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 
 	"github.com/Manta-Network/manta-fp/bindings"
+	"github.com/Manta-Network/manta-fp/ethereum/node"
+	"github.com/Manta-Network/manta-fp/l2chain/opstack"
 	"github.com/Manta-Network/manta-fp/metrics"
 	"github.com/Manta-Network/manta-fp/symbiotic-fp/celestia"
-	common2 "github.com/Manta-Network/manta-fp/symbiotic-fp/common"
 	"github.com/Manta-Network/manta-fp/symbiotic-fp/config"
 	"github.com/Manta-Network/manta-fp/symbiotic-fp/store"
-	"github.com/Manta-Network/manta-fp/symbiotic-fp/txmgr"
 	types2 "github.com/Manta-Network/manta-fp/types"
 
 	"github.com/lightningnetwork/lnd/kvdb"
@@ -35,89 +28,131 @@ import (
 	"golang.org/x/crypto/sha3"
 )
 
+// walletBalanceInterval is the interval between the records of the wallet balance
+const walletBalanceInterval = time.Minute
+
+var (
+	// ErrDoubleSign indicates that a different state root was requested to be
+	// signed for an L2 output index that has already been signed
+	ErrDoubleSign = errors.New("double sign requested")
+
+	errOperatorSuspended = errors.New("the operator is suspended")
+)
+
 type MantaStakingMiddleware struct {
-	Ctx                                  context.Context
-	Cfg                                  *MantaStakingMiddlewareConfig
-	MantaStakingMiddlewareContract       *bindings.MantaStakingMiddleware
-	RawMantaStakingMiddlewareContract    *bind.BoundContract
-	SymbioticOperatorRegisterContract    *bindings.SymbioticOperatorRegister
-	RawSymbioticOperatorRegisterContract *bind.BoundContract
-	WalletAddr                           common.Address
-	PrivateKey                           *ecdsa.PrivateKey
-	txMgr                                txmgr.TxManager
-	log                                  *zap.Logger
-	ChainPoller                          *OpChainPoller
-	DAClient                             *celestia.DAClient
+	*OperatorClient
+	Ctx                               context.Context
+	FinalitySignatureInboxContract    *bindings.FinalitySignatureInbox
+	RawFinalitySignatureInboxContract *bind.BoundContract
+	Indexer                           *opstack.Indexer
+	sfpMetrics                        *metrics.SfpMetrics
+	SRStore                           *opstack.OpStateRootStore
+	SignRecordStore                   *store.SignRecordStore
+	DARefStore                        *store.DARefStore
+	DAClient                          *celestia.DAClient
+	// outputVerifier is nil when no L2 rpc is configured
+	outputVerifier *opstack.OutputVerifier
+	subscription   *opstack.Subscription
+	operatorEvents *operatorEventHandler
+	// pendingBlocks are the blocks whose signing was interrupted by the suspension of
+	// the operator or failed every retry, they are only accessed by the submission loop
+	pendingBlocks []*types2.BlockInfo
+	bufferSize    uint32
+	// signDomain is the EIP-712 domain of the state root signatures
+	signDomain *types2.SignDomain
+	// lastStake is the active stake of the operator at the last queried epoch
+	lastStake *epochStake
+	// vaultAddr is the cached vault of the operator, nil until it's read
+	vaultAddr *common.Address
+	// vaultEpochsCache are the epoch settings of the queried vaults
+	vaultEpochsCache map[common.Address]*vaultEpochs
+	stakeMu          sync.Mutex
 
 	SignatureSubmissionInterval time.Duration
 	SubmissionRetryInterval     time.Duration
 	MaxSubmissionRetries        uint32
 
-	isStarted *atomic.Bool
-	wg        sync.WaitGroup
-	quit      chan struct{}
+	// submissions reports the blocks pending submission for too long to the health checks
+	submissions *metrics.SubmissionTracker
+
+	operatorStatus *atomic.Int32
+	isStarted      *atomic.Bool
+	wg             sync.WaitGroup
+	quit           chan struct{}
 }
 
-func NewMantaStakingMiddleware(mCfg *MantaStakingMiddlewareConfig, config *config.Config, db kvdb.Backend, log *zap.Logger, authToken string) (*MantaStakingMiddleware, error) {
-	mantaStakingMiddlewareContract, err := bindings.NewMantaStakingMiddleware(
-		mCfg.MantaStakingMiddlewareAddr, mCfg.EthClient,
-	)
+// newIndexer returns the indexer of the op event configuration, the events of the operator
+// in the manta staking middleware are indexed along with the state roots
+func newIndexer(cfg *config.OpEventConfig, operator common.Address, sRStore *opstack.OpStateRootStore, sfpMetrics *metrics.SfpOperatorMetrics, log *zap.Logger) (*opstack.Indexer, *operatorEventHandler, error) {
+	opClient, err := node.DialMultiEthClient(context.Background(), cfg.EthRpcUrls(), cfg.EthRpcQuorum, log)
 	if err != nil {
-		return nil, err
+		return nil, nil, fmt.Errorf("failed to dial eth client: %w", err)
 	}
-	mParsed, err := abi.JSON(strings.NewReader(
-		bindings.MantaStakingMiddlewareMetaData.ABI,
-	))
+	eventProvider, err := opstack.NewEventProvider(context.Background(), log)
 	if err != nil {
-		return nil, err
+		return nil, nil, fmt.Errorf("failed to initiate op event provider: %w", err)
+	}
+
+	indexer, err := opstack.NewIndexer(log, &opstack.IndexerConfig{
+		EthRpcs:                cfg.EthRpcUrls(),
+		EthRpcQuorum:           cfg.EthRpcQuorum,
+		ChainId:                cfg.ChainId,
+		StartHeight:            cfg.StartHeight,
+		BlockStep:              cfg.BlockStep,
+		PollInterval:           cfg.PollInterval,
+		HeadMode:               cfg.HeadMode,
+		ConfirmationDepth:      cfg.ConfirmationDepth,
+		L2OutputOracleAddr:     common.HexToAddress(cfg.L2OutputOracleAddr),
+		IndexDisputeGames:      cfg.IndexDisputeGames,
+		DisputeGameFactoryAddr: common.HexToAddress(cfg.DisputeGameFactoryAddr),
+	}, opClient, sRStore, eventProvider, sfpMetrics)
+	if err != nil {
+		return nil, nil, err
 	}
-	rawMantaStakingMiddlewareContract := bind.NewBoundContract(
-		mCfg.MantaStakingMiddlewareAddr, mParsed, mCfg.EthClient, mCfg.EthClient,
-		mCfg.EthClient,
-	)
 
-	symbioticOperatorRegisterContract, err := bindings.NewSymbioticOperatorRegister(
-		mCfg.SymbioticOperatorRegisterAddr, mCfg.EthClient,
-	)
+	mantaStakingAddr := common.HexToAddress(cfg.MantaStakingMiddlewareAddress)
+	operatorEvents, err := newOperatorEventHandler(mantaStakingAddr, operator, cfg.BufferSize, log)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if mantaStakingAddr != (common.Address{}) {
+		indexer.AddLogHandler(mantaStakingAddr, operatorEvents.handleLog)
 	}
-	sParsed, err := abi.JSON(strings.NewReader(
-		bindings.SymbioticOperatorRegisterMetaData.ABI,
-	))
+	return indexer, operatorEvents, nil
+}
+
+func NewMantaStakingMiddleware(mCfg *MantaStakingMiddlewareConfig, config *config.Config, db kvdb.Backend, log *zap.Logger, authToken string) (*MantaStakingMiddleware, error) {
+	operatorClient, err := NewOperatorClient(mCfg, log)
 	if err != nil {
 		return nil, err
 	}
-	rawSymbioticOperatorRegisterContract := bind.NewBoundContract(
-		mCfg.SymbioticOperatorRegisterAddr, sParsed, mCfg.EthClient, mCfg.EthClient,
-		mCfg.EthClient,
-	)
 
-	txManagerConfig := txmgr.Config{
-		ResubmissionTimeout:       time.Second * 5,
-		ReceiptQueryInterval:      time.Second,
-		NumConfirmations:          mCfg.NumConfirmations,
-		SafeAbortNonceTooLowCount: mCfg.SafeAbortNonceTooLowCount,
+	finalitySignatureInboxContract, rawFinalitySignatureInboxContract, err := newFinalitySignatureInboxContract(mCfg)
+	if err != nil {
+		return nil, err
 	}
 
-	txMgr := txmgr.NewSimpleTxManager(txManagerConfig, mCfg.EthClient)
-	var walletAddr common.Address
-	if mCfg.EnableHsm {
-		walletAddr = common.HexToAddress(mCfg.HsmAddress)
-	} else {
-		walletAddr = crypto.PubkeyToAddress(mCfg.PrivateKey.PublicKey)
+	sfpMetrics := metrics.NewSfpMetrics()
+
+	sRStore, err := opstack.NewOpStateRootStore(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initiate op state root store, err: %w", err)
 	}
 
-	fpMetrics := metrics.NewFpMetrics()
+	signRecordStore, err := store.NewSignRecordStore(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initiate sign record store, err: %w", err)
+	}
 
-	sRStore, err := store.NewOpStateRootStore(db)
+	daRefStore, err := store.NewDARefStore(db)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initiate op state root store, err: %w", err)
+		return nil, fmt.Errorf("failed to initiate da ref store, err: %w", err)
 	}
 
-	poller, err := NewOpChainPoller(log, config.OpEventConfig, sRStore, fpMetrics)
+	operatorMetrics := sfpMetrics.ForOperator(operatorClient.WalletAddr.String())
+	indexer, operatorEvents, err := newIndexer(config.OpEventConfig, operatorClient.WalletAddr, sRStore, operatorMetrics, log)
 	if err != nil {
-		return nil, fmt.Errorf("failed to new op chain poller, err: %w", err)
+		return nil, fmt.Errorf("failed to new indexer, err: %w", err)
 	}
 
 	daClient, err := celestia.NewDAClient(*config.CelestiaConfig, authToken)
@@ -125,25 +160,68 @@ func NewMantaStakingMiddleware(mCfg *MantaStakingMiddlewareConfig, config *confi
 		return nil, fmt.Errorf("failed to new celestia da client, err: %w", err)
 	}
 
+	var outputVerifier *opstack.OutputVerifier
+	if config.OpEventConfig.L2Rpc != "" {
+		outputVerifier, err = opstack.NewOutputVerifier(context.Background(), config.OpEventConfig.L2Rpc, operatorMetrics, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to new output verifier, err: %w", err)
+		}
+	}
+
 	return &MantaStakingMiddleware{
-		Ctx:                                  context.Background(),
-		Cfg:                                  mCfg,
-		MantaStakingMiddlewareContract:       mantaStakingMiddlewareContract,
-		RawMantaStakingMiddlewareContract:    rawMantaStakingMiddlewareContract,
-		SymbioticOperatorRegisterContract:    symbioticOperatorRegisterContract,
-		RawSymbioticOperatorRegisterContract: rawSymbioticOperatorRegisterContract,
-		WalletAddr:                           walletAddr,
-		txMgr:                                txMgr,
-		log:                                  log,
-		ChainPoller:                          poller,
-		DAClient:                             daClient,
-		PrivateKey:                           mCfg.PrivateKey,
-		isStarted:                            atomic.NewBool(false),
-		SignatureSubmissionInterval:          config.SignatureSubmissionInterval,
-		SubmissionRetryInterval:              config.SubmissionRetryInterval,
+		OperatorClient:                    operatorClient,
+		Ctx:                               context.Background(),
+		FinalitySignatureInboxContract:    finalitySignatureInboxContract,
+		RawFinalitySignatureInboxContract: rawFinalitySignatureInboxContract,
+		Indexer:                           indexer,
+		operatorEvents:                    operatorEvents,
+		bufferSize:                        config.OpEventConfig.BufferSize,
+		signDomain: &types2.SignDomain{
+			ChainID:                    mCfg.ChainID,
+			MantaStakingMiddlewareAddr: mCfg.MantaStakingMiddlewareAddr,
+		},
+		sfpMetrics:                  sfpMetrics,
+		operatorStatus:              atomic.NewInt32(int32(types2.OperatorStatusActive)),
+		SRStore:                     sRStore,
+		SignRecordStore:             signRecordStore,
+		DARefStore:                  daRefStore,
+		DAClient:                    daClient,
+		outputVerifier:              outputVerifier,
+		isStarted:                   atomic.NewBool(false),
+		SignatureSubmissionInterval: config.SignatureSubmissionInterval,
+		SubmissionRetryInterval:     config.SubmissionRetryInterval,
+		MaxSubmissionRetries:        config.MaxSubmissionRetries,
+		submissions:                 metrics.NewSubmissionTracker(metrics.SubmissionTimeout(config.SignatureSubmissionInterval, config.SubmissionRetryInterval, config.MaxSubmissionRetries)),
 	}, nil
 }
 
+// QueryHandler returns the queries of the indexed outputs, an output is signed if its
+// root is recorded as signed at its index, even if it was deleted since
+func (msm *MantaStakingMiddleware) QueryHandler() *opstack.QueryHandler {
+	return opstack.NewQueryHandler(msm.SRStore, msm.isSigned, msm.log)
+}
+
+func (msm *MantaStakingMiddleware) isSigned(stateRoot *types2.StateRoot) (bool, error) {
+	recordIndex := store.RecordIndex(stateRoot.L2OutputIndex.Uint64(), stateRoot.IsDisputeGame())
+	record, found, err := msm.SignRecordStore.GetSignRecord(recordIndex)
+	if err != nil {
+		return false, err
+	}
+	if found && record.StateRoot == stateRoot.StateRoot {
+		return true, nil
+	}
+	deletedRecords, err := msm.SignRecordStore.GetDeletedSignRecords(recordIndex)
+	if err != nil {
+		return false, err
+	}
+	for _, deletedRecord := range deletedRecords {
+		if deletedRecord.StateRoot == stateRoot.StateRoot {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (msm *MantaStakingMiddleware) Start() error {
 	if msm.isStarted.Swap(true) {
 		return fmt.Errorf("the symbiotic-fp %s is already started", msm.WalletAddr.String())
@@ -192,18 +270,29 @@ func (msm *MantaStakingMiddleware) Start() error {
 		msm.log.Info("success to register manta staking operator", zap.String("tx_hash", receipt.TxHash.String()))
 	} else {
 		if operator.Paused {
-			msm.log.Error("operator is paused", zap.String("address", msm.WalletAddr.String()))
-			return errors.New("operator is paused")
+			// the service keeps indexing while paused and resumes signing once the operator is unpaused
+			msm.log.Warn("operator is paused, the finality signature submission is suspended", zap.String("address", msm.WalletAddr.String()))
+			msm.setOperatorStatus(types2.OperatorStatusPaused)
 		}
 	}
 
-	if err := msm.ChainPoller.Start(); err != nil {
-		return fmt.Errorf("failed to start the poller %w", err)
+	msm.sfpMetrics.RecordOperatorStatus(msm.WalletAddr.String(), msm.OperatorStatus())
+	// the status is read at the latest block, the earlier events indexed again must not change it
+	msm.operatorEvents.setStatusHeight(latestBlock)
+
+	if err := msm.Indexer.Start(0); err != nil {
+		return fmt.Errorf("failed to start the indexer %w", err)
 	}
+	subscription, err := msm.Indexer.Subscribe("symbiotic-fp-"+msm.WalletAddr.String(), 0, msm.bufferSize)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to the indexer: %w", err)
+	}
+	msm.subscription = subscription
 
 	msm.quit = make(chan struct{})
-	msm.wg.Add(1)
+	msm.wg.Add(2)
 	go msm.finalitySigSubmissionLoop()
+	go msm.walletBalanceLoop()
 
 	return nil
 }
@@ -213,8 +302,12 @@ func (msm *MantaStakingMiddleware) Stop() error {
 		return fmt.Errorf("the symbiotic-fp %s has already stopped", msm.WalletAddr.String())
 	}
 
-	if err := msm.ChainPoller.Stop(); err != nil {
-		return fmt.Errorf("failed to stop the poller: %w", err)
+	if msm.subscription != nil {
+		msm.subscription.Close()
+	}
+	msm.operatorEvents.close()
+	if err := msm.Indexer.Stop(); err != nil {
+		return fmt.Errorf("failed to stop the indexer: %w", err)
 	}
 
 	msm.log.Info("stopping symbiotic-fp service", zap.String("address", msm.WalletAddr.String()))
@@ -222,6 +315,10 @@ func (msm *MantaStakingMiddleware) Stop() error {
 	close(msm.quit)
 	msm.wg.Wait()
 
+	if err := msm.DAClient.Close(); err != nil {
+		msm.log.Error("failed to close the da client", zap.String("err", err.Error()))
+	}
+
 	msm.log.Info("the symbiotic-fp service is successfully stopped", zap.String("address", msm.WalletAddr.String()))
 
 	return nil
@@ -233,14 +330,21 @@ func (msm *MantaStakingMiddleware) finalitySigSubmissionLoop() {
 	for {
 		select {
 		case <-time.After(msm.SignatureSubmissionInterval):
-			if err := msm.checkOperatorIsPaused(); err != nil {
-				msm.log.Error("the symbiotic-fp failed to check operator is paused",
+			msm.processOperatorEvents()
+			msm.processOutputsDeleted()
+
+			if status := msm.OperatorStatus(); status != types2.OperatorStatusActive {
+				// the new blocks are left in the subscription and the pending ones are kept,
+				// they are signed once the operator resumes
+				msm.log.Debug("the operator is suspended, skip signing the new block(s)",
 					zap.String("address", msm.WalletAddr.String()),
-					zap.String("error", err.Error()),
+					zap.String("status", status.String()),
+					zap.Int("pending_blocks", len(msm.pendingBlocks)),
 				)
+				continue
 			}
-
-			pollerBlocks := msm.getAllBlocksFromChan()
+			pollerBlocks := append(msm.pendingBlocks, msm.getAllBlocksFromChan()...)
+			msm.pendingBlocks = nil
 			if len(pollerBlocks) == 0 {
 				continue
 			}
@@ -250,13 +354,31 @@ func (msm *MantaStakingMiddleware) finalitySigSubmissionLoop() {
 				zap.Uint64("start_height", pollerBlocks[0].Height),
 				zap.Uint64("end_height", targetHeight),
 			)
+			msm.submissions.Pending()
 			err := msm.retrySubmitSigsUntilFinalized(pollerBlocks)
+			if errors.Is(err, errOperatorSuspended) {
+				msm.log.Warn("the operator is suspended, the block(s) are signed once it resumes",
+					zap.String("address", msm.WalletAddr.String()),
+					zap.Uint64("start_height", pollerBlocks[0].Height),
+					zap.Uint64("end_height", targetHeight),
+				)
+				msm.pendingBlocks = pollerBlocks
+				msm.submissions.Skipped()
+				continue
+			}
 			if err != nil {
-				msm.log.Error("the symbiotic-fp failed to submit signature",
+				// the blocks are neither dropped nor acknowledged, they are retried in the next
+				// cycle and stay pending in the health check until they are submitted
+				msm.log.Error("the symbiotic-fp failed to submit signature, the block(s) are retried in the next cycle",
 					zap.String("address", msm.WalletAddr.String()),
+					zap.Uint64("start_height", pollerBlocks[0].Height),
+					zap.Uint64("end_height", targetHeight),
 					zap.String("err", err.Error()))
+				msm.pendingBlocks = pollerBlocks
 				continue
 			}
+			msm.submissions.Submitted()
+			msm.ackBlocks(pollerBlocks)
 
 			msm.log.Info(
 				"successfully submitted the finality signature to the consumer chain",
@@ -272,6 +394,27 @@ func (msm *MantaStakingMiddleware) finalitySigSubmissionLoop() {
 	}
 }
 
+// walletBalanceLoop records the balance of the operator wallet, which pays for the
+// submissions to eth, so that an empty wallet is alerted before the submissions fail
+func (msm *MantaStakingMiddleware) walletBalanceLoop() {
+	defer msm.wg.Done()
+
+	for {
+		balance, err := msm.Cfg.EthClient.BalanceAt(msm.Ctx, msm.WalletAddr, nil)
+		if err != nil {
+			msm.log.Warn("failed to get the wallet balance", zap.String("address", msm.WalletAddr.String()), zap.String("err", err.Error()))
+		} else {
+			msm.sfpMetrics.RecordWalletBalance(msm.WalletAddr.String(), balance)
+		}
+
+		select {
+		case <-time.After(walletBalanceInterval):
+		case <-msm.quit:
+			return
+		}
+	}
+}
+
 // retrySubmitSigsUntilFinalized periodically tries to submit finality signature until success or the block is finalized
 // error will be returned if maximum retries have been reached or the query to the consumer chain fails
 func (msm *MantaStakingMiddleware) retrySubmitSigsUntilFinalized(targetBlocks []*types2.BlockInfo) error {
@@ -287,6 +430,10 @@ func (msm *MantaStakingMiddleware) retrySubmitSigsUntilFinalized(targetBlocks []
 	for {
 		select {
 		case <-time.After(msm.SubmissionRetryInterval):
+			msm.processOperatorEvents()
+			if status := msm.OperatorStatus(); status != types2.OperatorStatusActive {
+				return fmt.Errorf("%w with status %s", errOperatorSuspended, status.String())
+			}
 			// error will be returned if max retries have been reached
 			var err error
 			var ctx = context.Background()
@@ -317,7 +464,8 @@ func (msm *MantaStakingMiddleware) retrySubmitSigsUntilFinalized(targetBlocks []
 	}
 }
 
-// SubmitBatchFinalitySignatures builds and sends a finality signature over the given block to the consumer chain
+// SubmitBatchFinalitySignatures signs the state root of every given block and sends all the
+// signatures to the consumer chain in a single submission
 // NOTE: the input blocks should be in the ascending order of height
 func (msm *MantaStakingMiddleware) SubmitBatchFinalitySignatures(ctx context.Context, blocks []*types2.BlockInfo) error {
 	if len(blocks) == 0 {
@@ -328,279 +476,281 @@ func (msm *MantaStakingMiddleware) SubmitBatchFinalitySignatures(ctx context.Con
 		return fmt.Errorf("should not submit batch finality signature with too many blocks")
 	}
 
-	stateRoot := blocks[len(blocks)-1].StateRoot.StateRoot
-	signature, err := crypto.Sign(stateRoot[:], msm.PrivateKey)
+	// never sign an output root or a dispute game claim the L2 node disagrees with, the
+	// mismatching outputs are skipped and fail the health check
+	blocks, err := msm.outputVerifier.VerifyBlocks(ctx, blocks)
 	if err != nil {
-		msm.log.Error("failed to sign data", zap.String("err", err.Error()))
 		return err
 	}
 
-	signRequest := types2.SignRequest{
-		StateRoot:   hex.EncodeToString(stateRoot[:]),
-		Signature:   signature,
-		SignAddress: msm.WalletAddr.String(),
+	signRequests := make([]types2.SignRequest, 0, len(blocks))
+	for _, b := range blocks {
+		if !b.IsDisputeGame() {
+			deleted, err := msm.SRStore.IsOutputDeleted(b.L2OutputIndex, b.StateRoot.StateRoot)
+			if err != nil {
+				return fmt.Errorf("failed to check the deleted outputs: %w", err)
+			}
+			if deleted {
+				// never sign an output deleted from the L2OutputOracle, skip the output
+				msm.log.Warn("skip signing the deleted output", zap.String("l2_output_index", b.L2OutputIndex.String()))
+				continue
+			}
+		}
+		hasStake, err := msm.hasStake(ctx, &b.StateRoot)
+		if err != nil {
+			return fmt.Errorf("failed to get the active stake: %w", err)
+		}
+		if !hasStake {
+			// an output signed without stake carries no weight, skip the output
+			msm.sfpMetrics.RecordZeroStakeSkip(msm.WalletAddr.String())
+			continue
+		}
+		signRequest, err := msm.signStateRoot(&b.StateRoot)
+		if err != nil {
+			if errors.Is(err, ErrDoubleSign) {
+				// never sign a conflicting state root, skip the output
+				continue
+			}
+			return err
+		}
+		signRequests = append(signRequests, *signRequest)
 	}
-
-	data, err := json.Marshal(signRequest)
-	if err != nil {
-		msm.log.Error("failed to marshal data", zap.String("err", err.Error()))
-		return err
+	if len(signRequests) == 0 {
+		return nil
 	}
 
 	if msm.DAClient != nil && msm.DAClient.Client != nil {
-		commit, err := celestia.CreateCommitment(data, msm.DAClient.Namespace)
+		start := time.Now()
+		err := msm.submitToCelestia(ctx, signRequests)
+		msm.sfpMetrics.RecordDASubmit(msm.WalletAddr.String(), msm.DAClient.Backend, time.Since(start), err)
 		if err == nil {
-			ctx2, cancel := context.WithTimeout(ctx, msm.DAClient.GetTimeout)
-			ids, err := msm.DAClient.Client.Submit(ctx2, [][]byte{data}, -1, msm.DAClient.Namespace)
-			cancel()
-			if err == nil && len(ids) == 1 && len(ids[0]) == 40 && bytes.Equal(commit, ids[0][8:]) {
-				msm.log.Info("celestia: blob successfully submitted", zap.String("id", hex.EncodeToString(ids[0])))
-				ctx2, cancel := context.WithTimeout(ctx, msm.DAClient.GetTimeout)
-				proofs, err := msm.DAClient.Client.GetProofs(ctx2, ids, msm.DAClient.Namespace)
-				cancel()
-				if err == nil && len(proofs) == 1 {
-					ctx2, cancel := context.WithTimeout(ctx, msm.DAClient.GetTimeout)
-					valids, err := msm.DAClient.Client.Validate(ctx2, ids, proofs, msm.DAClient.Namespace)
-					cancel()
-					if err == nil && len(valids) == 1 && valids[0] == true {
-						msm.log.Info("success to send finality signature to celestia")
-					} else {
-						msm.log.Error("celestia: failed to validate proof",
-							zap.String("err", err.Error()),
-							zap.Any("valid", valids))
-					}
-				} else {
-					msm.log.Error("celestia: failed to get proof", zap.String("err", err.Error()))
-				}
-			} else {
-				msm.log.Info("celestia: blob submission failed; falling back to eth",
-					zap.String("err", err.Error()),
-					zap.Any("ids", ids),
-					zap.ByteString("commit", commit))
-			}
-		} else {
-			msm.log.Info("celestia: failed to create commitment", zap.String("err", err.Error()))
+			msm.sfpMetrics.RecordSignatures(msm.WalletAddr.String(), msm.DAClient.Backend, signRequests)
+			msm.log.Info("success to send finality signatures to celestia", zap.Int("count", len(signRequests)))
+			return nil
 		}
+		msm.log.Warn("celestia: blob submission failed; falling back to eth", zap.String("err", err.Error()))
+	}
+
+	start := time.Now()
+	receipt, err := msm.submitToEth(ctx, signRequests)
+	msm.sfpMetrics.RecordDASubmit(msm.WalletAddr.String(), metrics.DAPathEth, time.Since(start), err)
+	if err != nil {
+		return fmt.Errorf("failed to submit finality signature to eth: %w", err)
 	}
+	msm.sfpMetrics.RecordSignatures(msm.WalletAddr.String(), metrics.DAPathEth, signRequests)
+	msm.log.Info("success to send finality signatures to eth", zap.Int("count", len(signRequests)), zap.String("tx_hash", receipt.TxHash.String()))
 
 	return nil
 }
 
-func (msm *MantaStakingMiddleware) getAllBlocksFromChan() []*types2.BlockInfo {
-	var pollerBlocks []*types2.BlockInfo
-	for {
-		select {
-		case b := <-msm.ChainPoller.GetBlockInfoChan():
-			pollerBlocks = append(pollerBlocks, b)
-		case <-msm.quit:
-			msm.log.Info("the get all blocks loop is closing")
-			return nil
-		default:
-			return pollerBlocks
+// signStateRoot signs the output root of a single L2 output. A signature is made at most once
+// per L2 output index or dispute game index: the saved signature is returned for an identical
+// request and ErrDoubleSign is returned if a different state root is requested for a signed index
+func (msm *MantaStakingMiddleware) signStateRoot(stateRoot *types2.StateRoot) (*types2.SignRequest, error) {
+	outputIndex := store.RecordIndex(stateRoot.L2OutputIndex.Uint64(), stateRoot.IsDisputeGame())
+	record, found, err := msm.SignRecordStore.GetSignRecord(outputIndex)
+	if err != nil {
+		return nil, fmt.Errorf("error getting sign record: %w", err)
+	}
+
+	if found && record.StateRoot != stateRoot.StateRoot && !stateRoot.IsDisputeGame() {
+		// the output may be proposed again after the signed one is deleted
+		deleted, err := msm.SRStore.IsOutputDeleted(stateRoot.L2OutputIndex, record.StateRoot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check the deleted outputs: %w", err)
+		}
+		if deleted {
+			if err := msm.markSignRecordDeleted(stateRoot.L2OutputIndex.Uint64(), record.StateRoot); err != nil {
+				return nil, err
+			}
+			found = false
 		}
 	}
-}
 
-func (msm *MantaStakingMiddleware) UpdateMantaStakingGasPrice(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
-	var opts *bind.TransactOpts
-	var err error
-	if !msm.Cfg.EnableHsm {
-		opts, err = bind.NewKeyedTransactorWithChainID(
-			msm.Cfg.PrivateKey, msm.Cfg.ChainID,
+	var (
+		signature   []byte
+		version     uint8
+		l1BlockHash common.Hash
+	)
+	if found {
+		if record.StateRoot != stateRoot.StateRoot {
+			msm.log.Error(
+				"double sign requested",
+				zap.Uint64("l2_output_index", outputIndex),
+				zap.String("signed_state_root", hex.EncodeToString(record.StateRoot[:])),
+				zap.String("state_root", hex.EncodeToString(stateRoot.StateRoot[:])),
+			)
+			return nil, ErrDoubleSign
+		}
+
+		msm.log.Warn(
+			"duplicate sign requested",
+			zap.Uint64("l2_output_index", outputIndex),
+			zap.String("state_root", hex.EncodeToString(stateRoot.StateRoot[:])),
 		)
+		// the saved signature is returned as it was made, the legacy ones are not signed again
+		signature, version, l1BlockHash = record.Signature, record.SignatureVersion, record.L1BlockHash
 	} else {
-		opts, err = common2.NewHSMTransactOpts(ctx, msm.Cfg.HsmApiName,
-			msm.Cfg.HsmAddress, msm.Cfg.ChainID, msm.Cfg.HsmCreden)
-	}
-	if err != nil {
-		return nil, err
-	}
-	opts.Context = ctx
-	opts.Nonce = new(big.Int).SetUint64(tx.Nonce())
-	opts.NoSend = true
-	finalTx, err := msm.RawMantaStakingMiddlewareContract.RawTransact(opts, tx.Data())
-	if err != nil {
-		return nil, err
+		hash, err := types2.StateRootSigningHash(msm.signDomain, stateRoot)
+		if err != nil {
+			return nil, err
+		}
+		signature, err = msm.signHash(hash)
+		if err != nil {
+			msm.log.Error("failed to sign data", zap.String("err", err.Error()))
+			return nil, err
+		}
+		version, l1BlockHash = types2.SignRequestVersionTyped, stateRoot.L1BlockHash
+		if err := msm.SignRecordStore.SaveSignRecord(outputIndex, stateRoot.L2BlockNumber, stateRoot.StateRoot, signature, version, l1BlockHash); err != nil {
+			return nil, fmt.Errorf("failed to save signing record: %w", err)
+		}
 	}
-	return finalTx, nil
-}
 
-func (msm *MantaStakingMiddleware) UpdateSymbioticGasPrice(ctx context.Context, tx *types.Transaction) (*types.Transaction, error) {
-	var opts *bind.TransactOpts
-	var err error
-	if !msm.Cfg.EnableHsm {
-		opts, err = bind.NewKeyedTransactorWithChainID(
-			msm.Cfg.PrivateKey, msm.Cfg.ChainID,
-		)
-	} else {
-		opts, err = common2.NewHSMTransactOpts(ctx, msm.Cfg.HsmApiName,
-			msm.Cfg.HsmAddress, msm.Cfg.ChainID, msm.Cfg.HsmCreden)
+	signRequest := &types2.SignRequest{
+		Version:       version,
+		StateRoot:     hex.EncodeToString(stateRoot.StateRoot[:]),
+		Signature:     signature,
+		SignAddress:   msm.WalletAddr.String(),
+		L2BlockNumber: stateRoot.L2BlockNumber,
+		L2OutputIndex: stateRoot.L2OutputIndex,
 	}
-	if err != nil {
-		return nil, err
+	if version == types2.SignRequestVersionTyped {
+		signRequest.L1BlockHash = l1BlockHash.Hex()
 	}
-	opts.Context = ctx
-	opts.Nonce = new(big.Int).SetUint64(tx.Nonce())
-	opts.NoSend = true
-	finalTx, err := msm.RawSymbioticOperatorRegisterContract.RawTransact(opts, tx.Data())
-	if err != nil {
-		return nil, err
+	if stateRoot.IsDisputeGame() {
+		signRequest.DisputeGameProxy = stateRoot.DisputeGameProxy.String()
+		signRequest.DisputeGameType = stateRoot.DisputeGameType
 	}
-	return finalTx, nil
+	return signRequest, nil
 }
 
-func (msm *MantaStakingMiddleware) SendTransaction(ctx context.Context, tx *types.Transaction) error {
-	return msm.Cfg.EthClient.SendTransaction(ctx, tx)
-}
-
-func (msm *MantaStakingMiddleware) IsMaxPriorityFeePerGasNotFoundError(err error) bool {
-	return strings.Contains(
-		err.Error(), common2.ErrMaxPriorityFeePerGasNotFound.Error(),
-	)
+// signHash signs the hash with the operator key, the signature is in the
+// [R || S || V] format where V is 0 or 1
+func (msm *MantaStakingMiddleware) signHash(hash common.Hash) ([]byte, error) {
+	return msm.Cfg.Signer.SignHash(msm.Ctx, hash)
 }
 
-func (msm *MantaStakingMiddleware) registerSymbioticOperator(ctx context.Context) (*types.Transaction, error) {
-	balance, err := msm.Cfg.EthClient.BalanceAt(
-		ctx, msm.WalletAddr, nil,
-	)
+// submitToCelestia publishes the sign requests as a single blob to celestia and saves
+// the reference of the blob for every output index
+func (msm *MantaStakingMiddleware) submitToCelestia(ctx context.Context, signRequests []types2.SignRequest) error {
+	ref, err := msm.DAClient.SubmitBatch(ctx, signRequests)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	msm.log.Info("manta wallet address balance", zap.String("balance", balance.String()))
-
-	nonce64, err := msm.Cfg.EthClient.NonceAt(
-		ctx, msm.WalletAddr, nil,
+	msm.log.Info("celestia: blob successfully submitted",
+		zap.String("id", hex.EncodeToString(ref.ID)),
+		zap.Uint64("height", ref.Height),
+		zap.Int("count", len(signRequests)),
 	)
-	if err != nil {
-		return nil, err
+
+	outputIndexes := make([]uint64, 0, len(signRequests))
+	for _, signRequest := range signRequests {
+		outputIndexes = append(outputIndexes, store.RecordIndex(signRequest.L2OutputIndex.Uint64(), signRequest.IsDisputeGame()))
 	}
-	nonce := new(big.Int).SetUint64(nonce64)
-	var opts *bind.TransactOpts
-	if !msm.Cfg.EnableHsm {
-		opts, err = bind.NewKeyedTransactorWithChainID(
-			msm.Cfg.PrivateKey, msm.Cfg.ChainID,
+	// the signatures are published, failing to save the references must not republish them
+	if err := msm.DARefStore.SaveDARefs(outputIndexes, ref.ID, ref.Height, ref.Commitment, msm.DAClient.Namespace); err != nil {
+		msm.log.Error("failed to save the da refs",
+			zap.String("id", hex.EncodeToString(ref.ID)),
+			zap.String("err", err.Error()),
 		)
-	} else {
-		opts, err = common2.NewHSMTransactOpts(ctx, msm.Cfg.HsmApiName,
-			msm.Cfg.HsmAddress, msm.Cfg.ChainID, msm.Cfg.HsmCreden)
-	}
-	if err != nil {
-		return nil, err
-	}
-	opts.Context = ctx
-	opts.Nonce = nonce
-	opts.NoSend = true
-	tx, err := msm.SymbioticOperatorRegisterContract.RegisterOperator(opts)
-	if err != nil {
-		return nil, err
 	}
-	return tx, nil
+	return nil
 }
 
-func (msm *MantaStakingMiddleware) RegisterSymbioticOperator() (*types.Receipt, error) {
-	ctx := context.Background()
-	tx, err := msm.registerSymbioticOperator(ctx)
-	if err != nil {
-		return nil, err
-	}
-	updateGasPrice := func(ctx context.Context) (*types.Transaction, error) {
-		return msm.UpdateSymbioticGasPrice(ctx, tx)
-	}
-	receipt, err := msm.txMgr.Send(
-		ctx, updateGasPrice, msm.SendTransaction,
-	)
-	if err != nil {
-		return nil, err
+func (msm *MantaStakingMiddleware) getAllBlocksFromChan() []*types2.BlockInfo {
+	var pollerBlocks []*types2.BlockInfo
+	for {
+		select {
+		case b := <-msm.subscription.GetBlockInfoChan():
+			pollerBlocks = append(pollerBlocks, b)
+		case <-msm.quit:
+			msm.log.Info("the get all blocks loop is closing")
+			return nil
+		default:
+			return pollerBlocks
+		}
 	}
-	return receipt, nil
 }
 
-func (msm *MantaStakingMiddleware) registerOperator(ctx context.Context) (*types.Transaction, error) {
-	balance, err := msm.Cfg.EthClient.BalanceAt(
-		ctx, msm.WalletAddr, nil,
-	)
-	if err != nil {
-		return nil, err
-	}
-	msm.log.Info("manta wallet address balance", zap.String("balance", balance.String()))
-
-	nonce64, err := msm.Cfg.EthClient.NonceAt(
-		ctx, msm.WalletAddr, nil,
-	)
-	if err != nil {
-		return nil, err
-	}
-	nonce := new(big.Int).SetUint64(nonce64)
-	var opts *bind.TransactOpts
-	if !msm.Cfg.EnableHsm {
-		opts, err = bind.NewKeyedTransactorWithChainID(
-			msm.Cfg.PrivateKey, msm.Cfg.ChainID,
-		)
-	} else {
-		opts, err = common2.NewHSMTransactOpts(ctx, msm.Cfg.HsmApiName,
-			msm.Cfg.HsmAddress, msm.Cfg.ChainID, msm.Cfg.HsmCreden)
+// ackBlocks acknowledges the handled blocks to the subscription, the blocks which are not
+// acknowledged are delivered again on restart
+func (msm *MantaStakingMiddleware) ackBlocks(blocks []*types2.BlockInfo) {
+	for _, b := range blocks {
+		msm.subscription.Ack(b.Seq)
 	}
-	if err != nil {
-		return nil, err
-	}
-	opts.Context = ctx
-	opts.Nonce = nonce
-	opts.NoSend = true
-
-	xBytes := msm.PrivateKey.PublicKey.X.Bytes()
-	yBytes := msm.PrivateKey.PublicKey.Y.Bytes()
-	paddedX := make([]byte, 32)
-	copy(paddedX[32-len(xBytes):], xBytes)
-	paddedY := make([]byte, 32)
-	copy(paddedY[32-len(yBytes):], yBytes)
-	publicKeyBytes := append(paddedX, paddedY...)
+}
 
-	tx, err := msm.MantaStakingMiddlewareContract.RegisterOperator(opts, publicKeyBytes, msm.Cfg.OperatorName, common.HexToAddress(msm.Cfg.RewardAddress), big.NewInt(msm.Cfg.Commission))
-	if err != nil {
-		return nil, err
+// processOperatorEvents applies the status changes of the operator detected by the indexer,
+// the signing is suspended while the operator is paused or unregistered
+func (msm *MantaStakingMiddleware) processOperatorEvents() {
+	for {
+		select {
+		case event := <-msm.operatorEvents.operatorEventChan:
+			if event.Status == types2.OperatorStatusActive && msm.OperatorStatus() == types2.OperatorStatusUnregistered {
+				msm.log.Warn("ignore unpausing the unregistered operator", zap.String("address", msm.WalletAddr.String()))
+				continue
+			}
+			msm.log.Info("the operator status is changed",
+				zap.String("address", msm.WalletAddr.String()),
+				zap.String("status", event.Status.String()),
+				zap.Uint64("l1_block_number", event.L1BlockNumber),
+			)
+			msm.setOperatorStatus(event.Status)
+			// the vault is read again once the status of the operator changes
+			msm.resetVault()
+		default:
+			return
+		}
 	}
-	return tx, nil
 }
 
-func (msm *MantaStakingMiddleware) RegisterOperator() (*types.Receipt, error) {
-	ctx := context.Background()
-	tx, err := msm.registerOperator(ctx)
-	if err != nil {
-		return nil, err
-	}
-	updateGasPrice := func(ctx context.Context) (*types.Transaction, error) {
-		return msm.UpdateMantaStakingGasPrice(ctx, tx)
-	}
-	receipt, err := msm.txMgr.Send(
-		ctx, updateGasPrice, msm.SendTransaction,
-	)
-	if err != nil {
-		return nil, err
+// processOutputsDeleted records that the signatures of the outputs deleted from the
+// L2OutputOracle covered deleted roots, the deleted outputs are never signed again
+func (msm *MantaStakingMiddleware) processOutputsDeleted() {
+	for {
+		select {
+		case outputsDeleted := <-msm.subscription.GetOutputsDeletedChan():
+			marked := true
+			for _, stateRoot := range outputsDeleted.StateRoots {
+				if err := msm.markSignRecordDeleted(stateRoot.L2OutputIndex.Uint64(), stateRoot.StateRoot); err != nil {
+					msm.log.Error("failed to mark the sign record of the deleted output",
+						zap.String("l2_output_index", stateRoot.L2OutputIndex.String()),
+						zap.String("err", err.Error()),
+					)
+					marked = false
+				}
+			}
+			if marked {
+				msm.subscription.Ack(outputsDeleted.Seq)
+			}
+		default:
+			return
+		}
 	}
-	return receipt, nil
 }
 
-func (msm *MantaStakingMiddleware) checkOperatorIsPaused() error {
-	latestBlock, err := msm.Cfg.EthClient.BlockNumber(msm.Ctx)
+func (msm *MantaStakingMiddleware) markSignRecordDeleted(l2OutputIndex uint64, stateRoot [32]byte) error {
+	marked, err := msm.SignRecordStore.MarkSignRecordDeleted(l2OutputIndex, stateRoot)
 	if err != nil {
-		return fmt.Errorf("failed to get latest block, err: %v", err)
+		return fmt.Errorf("failed to mark the sign record deleted: %w", err)
 	}
-
-	cOpts := &bind.CallOpts{
-		BlockNumber: big.NewInt(int64(latestBlock)),
-		From:        msm.WalletAddr,
-	}
-	operator, err := msm.MantaStakingMiddlewareContract.Operators(cOpts, msm.WalletAddr)
-	if err != nil {
-		return fmt.Errorf("failed to get operator info at block: %v, err: %v", latestBlock, err)
+	if marked {
+		msm.log.Warn("the signed output is deleted from the l2 output oracle",
+			zap.Uint64("l2_output_index", l2OutputIndex),
+			zap.String("state_root", hex.EncodeToString(stateRoot[:])),
+		)
 	}
+	return nil
+}
 
-	if operator.Paused {
-		msm.log.Info("the operator is paused, stopping service")
-		return msm.Stop()
-	}
+// OperatorStatus returns the status of the operator in the manta staking middleware
+func (msm *MantaStakingMiddleware) OperatorStatus() types2.OperatorStatus {
+	return types2.OperatorStatus(msm.operatorStatus.Load())
+}
 
-	return nil
+func (msm *MantaStakingMiddleware) setOperatorStatus(status types2.OperatorStatus) {
+	msm.operatorStatus.Store(int32(status))
+	msm.sfpMetrics.RecordOperatorStatus(msm.WalletAddr.String(), status)
 }
 
 func Keccak256Hash(data []byte) [32]byte {
@@ -0,0 +1,131 @@
+package mantastaking
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Manta-Network/manta-fp/l2chain/opstack"
+	types2 "github.com/Manta-Network/manta-fp/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+// startSubmissionLoop subscribes the middleware to the outputs saved in the store and
+// starts its submission loop, the loop is stopped at the end of the test
+func startSubmissionLoop(t *testing.T, msm *MantaStakingMiddleware, sRStore *opstack.OpStateRootStore) {
+	indexer, err := opstack.NewIndexer(zap.NewNop(), &opstack.IndexerConfig{PollInterval: 10 * time.Millisecond}, nil, sRStore, nil, nil)
+	require.NoError(t, err)
+	subscription, err := indexer.Subscribe("test", 0, msm.bufferSize)
+	require.NoError(t, err)
+	msm.subscription = subscription
+
+	msm.quit = make(chan struct{})
+	msm.wg.Add(1)
+	go msm.finalitySigSubmissionLoop()
+	t.Cleanup(func() {
+		close(msm.quit)
+		msm.wg.Wait()
+		subscription.Close()
+	})
+}
+
+// signedOutputIndexes returns the output indexes of the signatures sent to the inbox
+func signedOutputIndexes(t *testing.T, backend *fakeBackend) []int64 {
+	var indexes []int64
+	for _, signature := range submittedSignatures(t, backend.sentTxs()) {
+		indexes = append(indexes, signature.L2OutputIndex.Int64())
+	}
+	return indexes
+}
+
+func sendOperatorEvent(msm *MantaStakingMiddleware, status types2.OperatorStatus, l1BlockNumber uint64) {
+	msm.operatorEvents.operatorEventChan <- &types2.OperatorEvent{Status: status, L1BlockNumber: l1BlockNumber}
+}
+
+func TestSubmissionSuspendedWhileOperatorIsPaused(t *testing.T) {
+	t.Parallel()
+
+	backend := newFakeBackend()
+	msm, sRStore := newTestMiddleware(t, backend, nil)
+	msm.MaxSubmissionRetries = 1000
+	startSubmissionLoop(t, msm, sRStore)
+
+	// the submission of the first output keeps failing until the operator is paused
+	backend.setCallErr(errors.New("eth is unavailable"))
+	require.NoError(t, sRStore.SaveStateRoot(testOutput(500, 1, 1)))
+	time.Sleep(50 * time.Millisecond)
+	sendOperatorEvent(msm, types2.OperatorStatusPaused, 501)
+	require.Eventually(t, func() bool {
+		return msm.OperatorStatus() == types2.OperatorStatusPaused
+	}, 5*time.Second, 10*time.Millisecond)
+	backend.setCallErr(nil)
+
+	// nothing is signed while the operator is paused
+	require.NoError(t, sRStore.SaveStateRoot(testOutput(502, 2, 2)))
+	time.Sleep(100 * time.Millisecond)
+	require.Empty(t, backend.sentTxs())
+
+	// the interrupted output and the new one are signed once the operator is unpaused
+	sendOperatorEvent(msm, types2.OperatorStatusActive, 503)
+	require.Eventually(t, func() bool {
+		return len(signedOutputIndexes(t, backend)) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []int64{1, 2}, signedOutputIndexes(t, backend))
+}
+
+func TestSubmissionStoppedOnceOperatorIsUnregistered(t *testing.T) {
+	t.Parallel()
+
+	backend := newFakeBackend()
+	msm, sRStore := newTestMiddleware(t, backend, nil)
+	startSubmissionLoop(t, msm, sRStore)
+
+	require.NoError(t, sRStore.SaveStateRoot(testOutput(500, 1, 1)))
+	require.Eventually(t, func() bool {
+		return len(signedOutputIndexes(t, backend)) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	sendOperatorEvent(msm, types2.OperatorStatusUnregistered, 501)
+	require.Eventually(t, func() bool {
+		return msm.OperatorStatus() == types2.OperatorStatusUnregistered
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// an unregistered operator is never unpaused and never signs again
+	require.NoError(t, sRStore.SaveStateRoot(testOutput(502, 2, 2)))
+	sendOperatorEvent(msm, types2.OperatorStatusActive, 503)
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, types2.OperatorStatusUnregistered, msm.OperatorStatus())
+	require.Equal(t, []int64{1}, signedOutputIndexes(t, backend))
+}
+
+func TestFailedSubmissionRetriedInNextCycle(t *testing.T) {
+	t.Parallel()
+
+	backend := newFakeBackend()
+	msm, sRStore := newTestMiddleware(t, backend, nil)
+	startSubmissionLoop(t, msm, sRStore)
+
+	// the submission fails over several cycles of max retries
+	backend.setCallErr(errors.New("eth is unavailable"))
+	require.NoError(t, sRStore.SaveStateRoot(testOutput(500, 1, 1)))
+	time.Sleep(200 * time.Millisecond)
+	require.Empty(t, backend.sentTxs())
+	cursor, _, err := sRStore.GetCursor("test")
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), cursor)
+
+	// the output is kept and signed once the submission succeeds, then acknowledged
+	backend.setCallErr(nil)
+	require.NoError(t, sRStore.SaveStateRoot(testOutput(501, 2, 2)))
+	require.Eventually(t, func() bool {
+		return len(signedOutputIndexes(t, backend)) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []int64{1, 2}, signedOutputIndexes(t, backend))
+	require.Eventually(t, func() bool {
+		cursor, _, err = sRStore.GetCursor("test")
+		return err == nil && cursor == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
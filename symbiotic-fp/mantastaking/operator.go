@@ -0,0 +1,374 @@
+package mantastaking
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Manta-Network/manta-fp/bindings"
+	"github.com/Manta-Network/manta-fp/metrics"
+	common2 "github.com/Manta-Network/manta-fp/symbiotic-fp/common"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/txmgr"
+
+	"go.uber.org/zap"
+)
+
+// OperatorInfo is the registration of an operator in the manta staking middleware
+type OperatorInfo struct {
+	Operator             common.Address `json:"operator"`
+	Vault                common.Address `json:"vault"`
+	Paused               bool           `json:"paused"`
+	OperatorName         string         `json:"operator_name"`
+	RewardAddress        common.Address `json:"reward_address"`
+	Commission           *big.Int       `json:"commission"`
+	TokenUnlockTimestamp *big.Int       `json:"token_unlock_timestamp"`
+	// ActiveStake is the stake delegated to the operator through its vault at the latest block
+	ActiveStake *big.Int `json:"active_stake"`
+}
+
+// OperatorClient sends the operator transactions of the manta staking middleware
+// and the symbiotic operator register through the tx manager
+type OperatorClient struct {
+	Cfg                                  *MantaStakingMiddlewareConfig
+	MantaStakingMiddlewareContract       *bindings.MantaStakingMiddleware
+	RawMantaStakingMiddlewareContract    *bind.BoundContract
+	SymbioticOperatorRegisterContract    *bindings.SymbioticOperatorRegister
+	RawSymbioticOperatorRegisterContract *bind.BoundContract
+	WalletAddr                           common.Address
+	txMgr                                txmgr.TxManager
+	nonceManager                         *txmgr.NonceManager
+	log                                  *zap.Logger
+}
+
+func NewOperatorClient(mCfg *MantaStakingMiddlewareConfig, log *zap.Logger) (*OperatorClient, error) {
+	mantaStakingMiddlewareContract, err := bindings.NewMantaStakingMiddleware(mCfg.MantaStakingMiddlewareAddr, mCfg.EthClient)
+	if err != nil {
+		return nil, err
+	}
+	mParsed, err := abi.JSON(strings.NewReader(
+		bindings.MantaStakingMiddlewareMetaData.ABI,
+	))
+	if err != nil {
+		return nil, err
+	}
+	rawMantaStakingMiddlewareContract := bind.NewBoundContract(
+		mCfg.MantaStakingMiddlewareAddr, mParsed, mCfg.EthClient, mCfg.EthClient,
+		mCfg.EthClient,
+	)
+
+	symbioticOperatorRegisterContract, err := bindings.NewSymbioticOperatorRegister(mCfg.SymbioticOperatorRegisterAddr, mCfg.EthClient)
+	if err != nil {
+		return nil, err
+	}
+	sParsed, err := abi.JSON(strings.NewReader(
+		bindings.SymbioticOperatorRegisterMetaData.ABI,
+	))
+	if err != nil {
+		return nil, err
+	}
+	rawSymbioticOperatorRegisterContract := bind.NewBoundContract(
+		mCfg.SymbioticOperatorRegisterAddr, sParsed, mCfg.EthClient, mCfg.EthClient,
+		mCfg.EthClient,
+	)
+
+	walletAddr := mCfg.Signer.Address()
+	txManagerConfig := txmgr.Config{
+		ResubmissionTimeout:       time.Second * 5,
+		ReceiptQueryInterval:      time.Second,
+		NumConfirmations:          mCfg.NumConfirmations,
+		SafeAbortNonceTooLowCount: mCfg.SafeAbortNonceTooLowCount,
+		FeeBumpPercent:            mCfg.FeeBumpPercent,
+		MaxGasFeeCap:              mCfg.MaxGasFeeCap,
+		Metrics:                   metrics.NewSfpMetrics().ForOperator(walletAddr.String()),
+	}
+
+	txMgr := txmgr.NewSimpleTxManager(txManagerConfig, mCfg.EthClient)
+
+	return &OperatorClient{
+		Cfg:                                  mCfg,
+		MantaStakingMiddlewareContract:       mantaStakingMiddlewareContract,
+		RawMantaStakingMiddlewareContract:    rawMantaStakingMiddlewareContract,
+		SymbioticOperatorRegisterContract:    symbioticOperatorRegisterContract,
+		RawSymbioticOperatorRegisterContract: rawSymbioticOperatorRegisterContract,
+		WalletAddr:                           walletAddr,
+		txMgr:                                txMgr,
+		nonceManager:                         txmgr.NewNonceManager(mCfg.EthClient, walletAddr),
+		log:                                  log,
+	}, nil
+}
+
+func (oc *OperatorClient) UpdateMantaStakingGasPrice(ctx context.Context, tx *types.Transaction, feeBumper *txmgr.FeeBumper) (*types.Transaction, error) {
+	return oc.updateGasPrice(ctx, oc.RawMantaStakingMiddlewareContract, tx, feeBumper)
+}
+
+func (oc *OperatorClient) UpdateSymbioticGasPrice(ctx context.Context, tx *types.Transaction, feeBumper *txmgr.FeeBumper) (*types.Transaction, error) {
+	return oc.updateGasPrice(ctx, oc.RawSymbioticOperatorRegisterContract, tx, feeBumper)
+}
+
+// updateGasPrice re-signs the transaction data with the same nonce and the fees of the next attempt
+func (oc *OperatorClient) updateGasPrice(ctx context.Context, contract *bind.BoundContract, tx *types.Transaction, feeBumper *txmgr.FeeBumper) (*types.Transaction, error) {
+	opts, err := oc.newTransactOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	market, err := oc.suggestGasFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	feeBumper.Bump(market).Apply(opts)
+	opts.Nonce = new(big.Int).SetUint64(tx.Nonce())
+	opts.NoSend = true
+	finalTx, err := contract.RawTransact(opts, tx.Data())
+	if err != nil {
+		return nil, err
+	}
+	return finalTx, nil
+}
+
+// suggestGasFees returns the market fees, the legacy gas price is used when the
+// node doesn't support eth_maxPriorityFeePerGas
+func (oc *OperatorClient) suggestGasFees(ctx context.Context) (*txmgr.GasFees, error) {
+	gasTipCap, err := oc.Cfg.EthClient.SuggestGasTipCap(ctx)
+	if err != nil {
+		if !oc.IsMaxPriorityFeePerGasNotFoundError(err) {
+			return nil, err
+		}
+		oc.log.Warn("eth_maxPriorityFeePerGas is unsupported, fall back to the legacy gas price")
+		return oc.suggestLegacyGasFees(ctx)
+	}
+	head, err := oc.Cfg.EthClient.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if head.BaseFee == nil {
+		return oc.suggestLegacyGasFees(ctx)
+	}
+	return &txmgr.GasFees{
+		GasTipCap: gasTipCap,
+		GasFeeCap: txmgr.CalcGasFeeCap(head.BaseFee, gasTipCap),
+	}, nil
+}
+
+func (oc *OperatorClient) suggestLegacyGasFees(ctx context.Context) (*txmgr.GasFees, error) {
+	gasPrice, err := oc.Cfg.EthClient.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &txmgr.GasFees{GasPrice: gasPrice}, nil
+}
+
+// newTxBuildOpts returns the transact opts building a transaction at the next nonce of
+// the operator, the transaction is priced again and sent by the tx manager
+func (oc *OperatorClient) newTxBuildOpts(ctx context.Context) (*bind.TransactOpts, error) {
+	opts, err := oc.newTransactOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fees, err := oc.suggestGasFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := oc.nonceManager.Next(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fees.Apply(opts)
+	opts.Nonce = new(big.Int).SetUint64(nonce)
+	opts.NoSend = true
+	return opts, nil
+}
+
+// sendTx builds the transaction at the next nonce of the operator and sends it through
+// the tx manager, every resubmission bumps the fees of the previous one. The nonce of a
+// transaction which never reached the mempool is released, otherwise the nonces are synced
+// from the chain since the transaction may still be pending
+func (oc *OperatorClient) sendTx(
+	ctx context.Context,
+	contract *bind.BoundContract,
+	build func(opts *bind.TransactOpts) (*types.Transaction, error),
+) (*types.Receipt, error) {
+	opts, err := oc.newTxBuildOpts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := build(opts)
+	if err != nil {
+		oc.nonceManager.Release(opts.Nonce.Uint64())
+		return nil, err
+	}
+
+	feeBumper := oc.txMgr.NewFeeBumper()
+	updateGasPrice := func(ctx context.Context) (*types.Transaction, error) {
+		return oc.updateGasPrice(ctx, contract, tx, feeBumper)
+	}
+	receipt, err := oc.txMgr.Send(
+		ctx, updateGasPrice, oc.SendTransaction,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, txmgr.ErrNonceTooLowAbort):
+			oc.log.Warn("the operator nonce is too low, sync it from the chain", zap.Uint64("nonce", tx.Nonce()))
+			oc.nonceManager.Reset()
+		case errors.Is(err, txmgr.ErrTxNotPublished):
+			oc.nonceManager.Release(tx.Nonce())
+		default:
+			// the transaction may be pending, the next nonce is synced from the pending nonce of the chain
+			oc.log.Warn("the operator transaction may be pending, sync the nonce from the chain", zap.Uint64("nonce", tx.Nonce()), zap.String("err", err.Error()))
+			oc.nonceManager.Reset()
+		}
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// newTransactOpts returns the transact opts signing with the operator key
+func (oc *OperatorClient) newTransactOpts(ctx context.Context) (*bind.TransactOpts, error) {
+	return oc.Cfg.Signer.TransactOpts(ctx, oc.Cfg.ChainID)
+}
+
+func (oc *OperatorClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	return oc.Cfg.EthClient.SendTransaction(ctx, tx)
+}
+
+func (oc *OperatorClient) IsMaxPriorityFeePerGasNotFoundError(err error) bool {
+	return strings.Contains(
+		err.Error(), common2.ErrMaxPriorityFeePerGasNotFound.Error(),
+	)
+}
+
+func (oc *OperatorClient) logWalletBalance(ctx context.Context) error {
+	balance, err := oc.Cfg.EthClient.BalanceAt(
+		ctx, oc.WalletAddr, nil,
+	)
+	if err != nil {
+		return err
+	}
+	oc.log.Info("manta wallet address balance", zap.String("balance", balance.String()))
+	return nil
+}
+
+func (oc *OperatorClient) RegisterSymbioticOperator() (*types.Receipt, error) {
+	ctx := context.Background()
+	if err := oc.logWalletBalance(ctx); err != nil {
+		return nil, err
+	}
+	return oc.sendTx(ctx, oc.RawSymbioticOperatorRegisterContract, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return oc.SymbioticOperatorRegisterContract.RegisterOperator(opts)
+	})
+}
+
+func (oc *OperatorClient) RegisterOperator() (*types.Receipt, error) {
+	ctx := context.Background()
+	if err := oc.logWalletBalance(ctx); err != nil {
+		return nil, err
+	}
+
+	xBytes := oc.Cfg.PublicKey.X.Bytes()
+	yBytes := oc.Cfg.PublicKey.Y.Bytes()
+	paddedX := make([]byte, 32)
+	copy(paddedX[32-len(xBytes):], xBytes)
+	paddedY := make([]byte, 32)
+	copy(paddedY[32-len(yBytes):], yBytes)
+	publicKeyBytes := append(paddedX, paddedY...)
+
+	return oc.sendTx(ctx, oc.RawMantaStakingMiddlewareContract, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return oc.MantaStakingMiddlewareContract.RegisterOperator(opts, publicKeyBytes, oc.Cfg.OperatorName, common.HexToAddress(oc.Cfg.RewardAddress), big.NewInt(oc.Cfg.Commission))
+	})
+}
+
+// sendMantaStakingTx builds a manta staking middleware transaction with the
+// operator key and sends it through the tx manager
+func (oc *OperatorClient) sendMantaStakingTx(
+	ctx context.Context,
+	build func(opts *bind.TransactOpts) (*types.Transaction, error),
+) (*types.Receipt, error) {
+	return oc.sendTx(ctx, oc.RawMantaStakingMiddlewareContract, build)
+}
+
+func (oc *OperatorClient) PauseOperator(ctx context.Context, operator common.Address) (*types.Receipt, error) {
+	return oc.sendMantaStakingTx(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return oc.MantaStakingMiddlewareContract.PauseOperator(opts, operator)
+	})
+}
+
+func (oc *OperatorClient) UnpauseOperator(ctx context.Context, operator common.Address) (*types.Receipt, error) {
+	return oc.sendMantaStakingTx(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return oc.MantaStakingMiddlewareContract.UnpauseOperator(opts, operator)
+	})
+}
+
+func (oc *OperatorClient) UnregisterOperator(ctx context.Context) (*types.Receipt, error) {
+	return oc.sendMantaStakingTx(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return oc.MantaStakingMiddlewareContract.UnregisterOperator(opts)
+	})
+}
+
+func (oc *OperatorClient) SetRewardAddress(ctx context.Context, rewardAddress common.Address) (*types.Receipt, error) {
+	return oc.sendMantaStakingTx(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return oc.MantaStakingMiddlewareContract.SetRewardAddress(opts, rewardAddress)
+	})
+}
+
+func (oc *OperatorClient) UpdateOperatorSettings(ctx context.Context, settings bindings.OperatorSettings) (*types.Receipt, error) {
+	return oc.sendMantaStakingTx(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return oc.MantaStakingMiddlewareContract.UpdateOperatorSettings(opts, settings)
+	})
+}
+
+func (oc *OperatorClient) ClaimUnlockedToken(ctx context.Context) (*types.Receipt, error) {
+	return oc.sendMantaStakingTx(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return oc.MantaStakingMiddlewareContract.ClaimUnlockedToken(opts)
+	})
+}
+
+// QueryOperatorInfo returns the registration, the token unlock timestamp and the active stake of the operator
+func (oc *OperatorClient) QueryOperatorInfo(ctx context.Context, operator common.Address) (*OperatorInfo, error) {
+	cOpts := &bind.CallOpts{Context: ctx}
+	res, err := oc.MantaStakingMiddlewareContract.Operators(cOpts, operator)
+	if err != nil {
+		return nil, err
+	}
+	unlockTimestamp, err := oc.MantaStakingMiddlewareContract.OperatorTokenUnlockTimestamps(cOpts, operator)
+	if err != nil {
+		return nil, err
+	}
+	activeStake, err := oc.queryVaultStake(ctx, res.Vault, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &OperatorInfo{
+		Operator:             operator,
+		Vault:                res.Vault,
+		Paused:               res.Paused,
+		OperatorName:         res.OperatorName,
+		RewardAddress:        res.RewardAddress,
+		Commission:           res.Commission,
+		TokenUnlockTimestamp: unlockTimestamp,
+		ActiveStake:          activeStake,
+	}, nil
+}
+
+func (oc *OperatorClient) QueryOperatorSettings(ctx context.Context) (*bindings.OperatorSettings, error) {
+	res, err := oc.MantaStakingMiddlewareContract.OperatorSettings(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, err
+	}
+	settings := bindings.OperatorSettings(res)
+	return &settings, nil
+}
+
+func (oc *OperatorClient) QuerySymbioticVaultSettings(ctx context.Context) (*bindings.SymbioticVaultSettings, error) {
+	res, err := oc.MantaStakingMiddlewareContract.SymbioticVaultSettings(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, err
+	}
+	settings := bindings.SymbioticVaultSettings(res)
+	return &settings, nil
+}
@@ -0,0 +1,115 @@
+package mantastaking
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	ctypes "github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/Manta-Network/manta-fp/bindings"
+	"github.com/Manta-Network/manta-fp/types"
+
+	"go.uber.org/atomic"
+	"go.uber.org/zap"
+)
+
+var (
+	errOperatorEventHandlerClosed = errors.New("the operator event handler is closed")
+)
+
+// operatorEventHandler parses the events of the operator in the manta staking middleware
+// indexed along with the state roots, so that the signer is suspended while the
+// operator is paused
+type operatorEventHandler struct {
+	log                *zap.Logger
+	operator           common.Address
+	mantaStakingABI    *abi.ABI
+	mantaStakingFilter *bindings.MantaStakingMiddlewareFilterer
+	operatorEventChan  chan *types.OperatorEvent
+
+	// statusHeight is the L1 block the operator status was read at, the events up to
+	// it are already reflected by the status
+	statusHeight *atomic.Uint64
+
+	closeOnce sync.Once
+	quit      chan struct{}
+}
+
+func newOperatorEventHandler(mantaStakingAddr, operator common.Address, bufferSize uint32, log *zap.Logger) (*operatorEventHandler, error) {
+	mantaStakingABI, err := bindings.MantaStakingMiddlewareMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	mantaStakingFilter, err := bindings.NewMantaStakingMiddlewareFilterer(mantaStakingAddr, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &operatorEventHandler{
+		log:                log,
+		operator:           operator,
+		mantaStakingABI:    mantaStakingABI,
+		mantaStakingFilter: mantaStakingFilter,
+		operatorEventChan:  make(chan *types.OperatorEvent, bufferSize),
+		statusHeight:       atomic.NewUint64(0),
+		quit:               make(chan struct{}),
+	}, nil
+}
+
+// setStatusHeight ignores the events up to the L1 block the operator status was read at
+func (h *operatorEventHandler) setStatusHeight(l1BlockNumber uint64) {
+	h.statusHeight.Store(l1BlockNumber)
+}
+
+// close stops waiting for the events to be consumed, the indexer stops retrying the
+// pending batch once it's stopped
+func (h *operatorEventHandler) close() {
+	h.closeOnce.Do(func() {
+		close(h.quit)
+	})
+}
+
+// handleLog emits the status changes of the operator in the manta staking middleware, the
+// events of the other operators and the events replayed below the status height are skipped
+func (h *operatorEventHandler) handleLog(log ctypes.Log) error {
+	var event *types.OperatorEvent
+	switch log.Topics[0] {
+	case h.mantaStakingABI.Events["OperatorPaused"].ID:
+		paused, err := h.mantaStakingFilter.ParseOperatorPaused(log)
+		if err != nil {
+			return err
+		}
+		event = &types.OperatorEvent{Operator: paused.Operator, Status: types.OperatorStatusPaused}
+	case h.mantaStakingABI.Events["OperatorUnpaused"].ID:
+		unpaused, err := h.mantaStakingFilter.ParseOperatorUnpaused(log)
+		if err != nil {
+			return err
+		}
+		event = &types.OperatorEvent{Operator: unpaused.Operator, Status: types.OperatorStatusActive}
+	case h.mantaStakingABI.Events["OperatorUnregistered"].ID:
+		unregistered, err := h.mantaStakingFilter.ParseOperatorUnregistered(log)
+		if err != nil {
+			return err
+		}
+		event = &types.OperatorEvent{Operator: unregistered.Operator, Status: types.OperatorStatusUnregistered}
+	default:
+		return nil
+	}
+	if event.Operator != h.operator || log.BlockNumber <= h.statusHeight.Load() {
+		return nil
+	}
+	event.L1BlockNumber = log.BlockNumber
+
+	h.log.Info("detected operator status change",
+		zap.String("operator", event.Operator.String()),
+		zap.String("status", event.Status.String()),
+		zap.Uint64("l1_block_number", event.L1BlockNumber),
+	)
+	select {
+	case h.operatorEventChan <- event:
+		return nil
+	case <-h.quit:
+		return errOperatorEventHandlerClosed
+	}
+}
@@ -0,0 +1,33 @@
+package mantastaking
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Manta-Network/manta-fp/bindings"
+)
+
+// OperatorRegistry looks the operators up in the manta staking middleware, it only
+// reads the contract and doesn't need the operator signer
+type OperatorRegistry struct {
+	caller *bindings.MantaStakingMiddlewareCaller
+}
+
+func NewOperatorRegistry(mantaStakingMiddlewareAddr common.Address, backend bind.ContractCaller) (*OperatorRegistry, error) {
+	caller, err := bindings.NewMantaStakingMiddlewareCaller(mantaStakingMiddlewareAddr, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &OperatorRegistry{caller: caller}, nil
+}
+
+// IsOperator returns true if the address is registered in the manta staking middleware
+func (r *OperatorRegistry) IsOperator(ctx context.Context, operator common.Address) (bool, error) {
+	res, err := r.caller.Operators(&bind.CallOpts{Context: ctx}, operator)
+	if err != nil {
+		return false, err
+	}
+	return res.OperatorName != "", nil
+}
@@ -3,50 +3,57 @@ package mantastaking
 import (
 	"context"
 	"crypto/ecdsa"
-	"errors"
 
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
 
 	"github.com/Manta-Network/manta-fp/ethereum/node"
 	cfg "github.com/Manta-Network/manta-fp/symbiotic-fp/config"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/signer"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/txmgr"
 
 	"go.uber.org/zap"
 	"math/big"
 )
 
+// EthBackend is the eth client of the operator, the contracts are read and transacted
+// with, and the receipts of the transactions are queried through it
+type EthBackend interface {
+	bind.ContractBackend
+	txmgr.ReceiptSource
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	Close()
+}
+
 type MantaStakingMiddlewareConfig struct {
-	EthClient                     *ethclient.Client
+	// EthClient is over all the eth rpc uris when several are set, the transactions are
+	// sent to all of them
+	EthClient                     EthBackend
 	ChainID                       *big.Int
 	MantaStakingMiddlewareAddr    common.Address
 	SymbioticOperatorRegisterAddr common.Address
-	PrivateKey                    *ecdsa.PrivateKey
+	FinalitySignatureInboxAddr    common.Address
+	Signer                        signer.Signer
+	PublicKey                     *ecdsa.PublicKey
 	NumConfirmations              uint64
 	SafeAbortNonceTooLowCount     uint64
+	FeeBumpPercent                uint64
+	MaxGasFeeCap                  *big.Int
 	OperatorName                  string
 	RewardAddress                 string
 	Commission                    int64
-	EnableHsm                     bool
-	HsmApiName                    string
-	HsmCreden                     string
-	HsmAddress                    string
 }
 
-func NewMantaStakingMiddlewareConfig(ctx context.Context, config *cfg.Config, logger *zap.Logger, priKeyS string) (*MantaStakingMiddlewareConfig, error) {
-	ethClient, err := node.DialEthClientWithTimeout(ctx, config.OpEventConfig.EthRpc, false)
+func NewMantaStakingMiddlewareConfig(ctx context.Context, config *cfg.Config, logger *zap.Logger, operatorSigner signer.Signer) (*MantaStakingMiddlewareConfig, error) {
+	ethClient, err := NewEthBackend(ctx, config.OpEventConfig, logger)
 	if err != nil {
 		logger.Error("failed to dial eth client", zap.String("err", err.Error()))
 		return nil, err
 	}
-	var privKey *ecdsa.PrivateKey
-	if priKeyS != "" {
-		privKey, err = crypto.HexToECDSA(priKeyS)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		return nil, errors.New("need to config private key")
+	pubKey, err := operatorSigner.PublicKey(ctx)
+	if err != nil {
+		return nil, err
 	}
 
 	return &MantaStakingMiddlewareConfig{
@@ -54,15 +61,28 @@ func NewMantaStakingMiddlewareConfig(ctx context.Context, config *cfg.Config, lo
 		ChainID:                       big.NewInt(int64(config.OpEventConfig.ChainId)),
 		MantaStakingMiddlewareAddr:    common.HexToAddress(config.OpEventConfig.MantaStakingMiddlewareAddress),
 		SymbioticOperatorRegisterAddr: common.HexToAddress(config.OpEventConfig.SymbioticOperatorRegisterAddress),
-		PrivateKey:                    privKey,
+		FinalitySignatureInboxAddr:    common.HexToAddress(config.OpEventConfig.FinalitySignatureInboxAddress),
+		Signer:                        operatorSigner,
+		PublicKey:                     pubKey,
 		NumConfirmations:              config.OpEventConfig.NumConfirmations,
 		SafeAbortNonceTooLowCount:     config.OpEventConfig.SafeAbortNonceTooLowCount,
+		FeeBumpPercent:                config.OpEventConfig.FeeBumpPercent,
+		MaxGasFeeCap:                  new(big.Int).Mul(new(big.Int).SetUint64(config.OpEventConfig.MaxGasFeeCapGwei), big.NewInt(params.GWei)),
 		OperatorName:                  config.OperatorName,
 		RewardAddress:                 config.RewardAddress,
 		Commission:                    int64(config.Commission),
-		EnableHsm:                     config.OpEventConfig.EnableHsm,
-		HsmApiName:                    config.OpEventConfig.HsmApiName,
-		HsmCreden:                     config.OpEventConfig.HsmCreden,
-		HsmAddress:                    config.OpEventConfig.HsmAddress,
 	}, nil
 }
+
+// NewEthBackend returns the eth client of the operator, the calls fail over between the
+// eth rpc uris, or are checked against their quorum, when several are set
+func NewEthBackend(ctx context.Context, config *cfg.OpEventConfig, logger *zap.Logger) (EthBackend, error) {
+	if len(config.EthRpcs) == 0 {
+		ethClient, err := node.DialEthClientWithTimeout(ctx, config.EthRpcUrls()[0], false)
+		if err != nil {
+			return nil, err
+		}
+		return ethClient, nil
+	}
+	return node.DialMultiContractBackend(ctx, config.EthRpcUrls(), config.EthRpcQuorum, logger)
+}
@@ -0,0 +1,240 @@
+package mantastaking
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	types2 "github.com/Manta-Network/manta-fp/types"
+
+	"go.uber.org/zap"
+)
+
+// symbioticVaultABI is the part of the symbiotic vault ABI reading the active stake and the
+// epochs, the vault of an operator is created by the manta staking middleware at its registration
+const symbioticVaultABI = `[
+	{"type":"function","name":"epochDurationInit","inputs":[],"outputs":[{"name":"","type":"uint48"}],"stateMutability":"view"},
+	{"type":"function","name":"epochDuration","inputs":[],"outputs":[{"name":"","type":"uint48"}],"stateMutability":"view"},
+	{"type":"function","name":"activeStake","inputs":[],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"},
+	{"type":"function","name":"activeStakeAt","inputs":[{"name":"timestamp","type":"uint48"},{"name":"hints","type":"bytes"}],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"}
+]`
+
+var (
+	parseVaultABIOnce sync.Once
+	parsedVaultABI    abi.ABI
+	parseVaultABIErr  error
+)
+
+func vaultABI() (abi.ABI, error) {
+	parseVaultABIOnce.Do(func() {
+		parsedVaultABI, parseVaultABIErr = abi.JSON(strings.NewReader(symbioticVaultABI))
+	})
+	return parsedVaultABI, parseVaultABIErr
+}
+
+// QueryOperatorStake returns the active stake delegated to the operator through its vault,
+// at the given timestamp or at the latest block if the timestamp is nil. An operator
+// without a vault has no stake
+func (oc *OperatorClient) QueryOperatorStake(ctx context.Context, operator common.Address, timestamp *uint64) (*big.Int, error) {
+	vaultAddr, err := oc.operatorVault(ctx, operator)
+	if err != nil {
+		return nil, err
+	}
+	return oc.queryVaultStake(ctx, vaultAddr, timestamp)
+}
+
+// operatorVault returns the vault of the operator at the latest block, the state of the
+// past blocks is pruned by the non-archive nodes
+func (oc *OperatorClient) operatorVault(ctx context.Context, operator common.Address) (common.Address, error) {
+	res, err := oc.MantaStakingMiddlewareContract.Operators(&bind.CallOpts{Context: ctx}, operator)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to get the vault of the operator: %w", err)
+	}
+	return res.Vault, nil
+}
+
+func (oc *OperatorClient) boundVault(vaultAddr common.Address) (*bind.BoundContract, error) {
+	parsed, err := vaultABI()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(vaultAddr, parsed, oc.Cfg.EthClient, nil, nil), nil
+}
+
+func (oc *OperatorClient) queryVaultStake(ctx context.Context, vaultAddr common.Address, timestamp *uint64) (*big.Int, error) {
+	if vaultAddr == (common.Address{}) {
+		return big.NewInt(0), nil
+	}
+	vault, err := oc.boundVault(vaultAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []interface{}
+	if timestamp == nil {
+		err = vault.Call(&bind.CallOpts{Context: ctx}, &out, "activeStake")
+	} else {
+		err = vault.Call(&bind.CallOpts{Context: ctx}, &out, "activeStakeAt", new(big.Int).SetUint64(*timestamp), []byte{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the active stake of vault %s: %w", vaultAddr.String(), err)
+	}
+	return abi.ConvertType(out[0], new(big.Int)).(*big.Int), nil
+}
+
+// vaultEpochs are the epoch settings of a symbiotic vault, the epochs of a vault start at
+// its epochDurationInit and last its epochDuration. Both are set at the vault creation and
+// never change
+type vaultEpochs struct {
+	durationInit uint64
+	duration     uint64
+}
+
+// epochStart returns the start of the vault epoch holding the timestamp, the timestamps
+// before the first epoch of the vault have no epoch
+func (e *vaultEpochs) epochStart(timestamp uint64) (uint64, bool) {
+	if timestamp < e.durationInit {
+		return 0, false
+	}
+	return timestamp - (timestamp-e.durationInit)%e.duration, true
+}
+
+func (oc *OperatorClient) queryVaultEpochs(ctx context.Context, vaultAddr common.Address) (*vaultEpochs, error) {
+	vault, err := oc.boundVault(vaultAddr)
+	if err != nil {
+		return nil, err
+	}
+	var durationInit, duration []interface{}
+	if err := vault.Call(&bind.CallOpts{Context: ctx}, &durationInit, "epochDurationInit"); err != nil {
+		return nil, fmt.Errorf("failed to get the epoch duration init of vault %s: %w", vaultAddr.String(), err)
+	}
+	if err := vault.Call(&bind.CallOpts{Context: ctx}, &duration, "epochDuration"); err != nil {
+		return nil, fmt.Errorf("failed to get the epoch duration of vault %s: %w", vaultAddr.String(), err)
+	}
+	epochs := &vaultEpochs{
+		durationInit: abi.ConvertType(durationInit[0], new(big.Int)).(*big.Int).Uint64(),
+		duration:     abi.ConvertType(duration[0], new(big.Int)).(*big.Int).Uint64(),
+	}
+	if epochs.duration == 0 {
+		return nil, fmt.Errorf("invalid epoch duration 0 of vault %s", vaultAddr.String())
+	}
+	return epochs, nil
+}
+
+// epochStake is the active stake of the operator vault at the start of an epoch, the stake
+// of a past timestamp never changes
+type epochStake struct {
+	vault      common.Address
+	epochStart uint64
+	stake      *big.Int
+}
+
+// hasStake tells whether the operator has an active stake at the start of the epoch of
+// the output, the epoch is given by the timestamp of the L1 block the output is proposed
+// at. The outputs of an operator without stake carry no weight and are not signed
+func (msm *MantaStakingMiddleware) hasStake(ctx context.Context, stateRoot *types2.StateRoot) (bool, error) {
+	header, err := msm.Cfg.EthClient.HeaderByNumber(ctx, new(big.Int).SetUint64(stateRoot.L1BlockNumber))
+	if err != nil {
+		return false, fmt.Errorf("failed to get the l1 block %d of the output: %w", stateRoot.L1BlockNumber, err)
+	}
+	vaultAddr, err := msm.vault(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	stake := big.NewInt(0)
+	var epochStart uint64
+	if vaultAddr != (common.Address{}) {
+		epochs, err := msm.vaultEpochs(ctx, vaultAddr)
+		if err != nil {
+			return false, err
+		}
+		var ok bool
+		if epochStart, ok = epochs.epochStart(header.Time); ok {
+			stake, err = msm.stakeAt(ctx, vaultAddr, epochStart)
+			if err != nil {
+				return false, err
+			}
+		}
+	}
+	if stake.Sign() == 0 {
+		msm.log.Debug(
+			"the operator has no active stake",
+			zap.String("address", msm.WalletAddr.String()),
+			zap.String("vault", vaultAddr.String()),
+			zap.String("l2_output_index", stateRoot.L2OutputIndex.String()),
+			zap.Uint64("epoch_start", epochStart),
+		)
+		return false, nil
+	}
+	return true, nil
+}
+
+// vault returns the vault of the operator, it's cached until the next operator event as
+// the vault is only set by the registration of the operator
+func (msm *MantaStakingMiddleware) vault(ctx context.Context) (common.Address, error) {
+	msm.stakeMu.Lock()
+	defer msm.stakeMu.Unlock()
+
+	if msm.vaultAddr != nil {
+		return *msm.vaultAddr, nil
+	}
+	vaultAddr, err := msm.operatorVault(ctx, msm.WalletAddr)
+	if err != nil {
+		return common.Address{}, err
+	}
+	msm.vaultAddr = &vaultAddr
+	return vaultAddr, nil
+}
+
+// resetVault drops the cached vault of the operator, it's read again at the next output
+func (msm *MantaStakingMiddleware) resetVault() {
+	msm.stakeMu.Lock()
+	defer msm.stakeMu.Unlock()
+	msm.vaultAddr = nil
+}
+
+// vaultEpochs returns the epoch settings of the vault, they are cached as they never change
+func (msm *MantaStakingMiddleware) vaultEpochs(ctx context.Context, vaultAddr common.Address) (*vaultEpochs, error) {
+	msm.stakeMu.Lock()
+	defer msm.stakeMu.Unlock()
+
+	if epochs, ok := msm.vaultEpochsCache[vaultAddr]; ok {
+		return epochs, nil
+	}
+	epochs, err := msm.queryVaultEpochs(ctx, vaultAddr)
+	if err != nil {
+		return nil, err
+	}
+	if msm.vaultEpochsCache == nil {
+		msm.vaultEpochsCache = make(map[common.Address]*vaultEpochs)
+	}
+	msm.vaultEpochsCache[vaultAddr] = epochs
+	return epochs, nil
+}
+
+// stakeAt returns the active stake of the vault at the epoch start, the stake of the
+// last queried epoch is cached as the outputs of an epoch are signed in a row
+func (msm *MantaStakingMiddleware) stakeAt(ctx context.Context, vaultAddr common.Address, epochStart uint64) (*big.Int, error) {
+	msm.stakeMu.Lock()
+	defer msm.stakeMu.Unlock()
+
+	if msm.lastStake != nil && msm.lastStake.vault == vaultAddr && msm.lastStake.epochStart == epochStart {
+		return msm.lastStake.stake, nil
+	}
+	stake, err := msm.queryVaultStake(ctx, vaultAddr, &epochStart)
+	if err != nil {
+		return nil, err
+	}
+	if msm.lastStake == nil || epochStart > msm.lastStake.epochStart {
+		msm.sfpMetrics.RecordOperatorStake(msm.WalletAddr.String(), stake)
+	}
+	msm.lastStake = &epochStake{vault: vaultAddr, epochStart: epochStart, stake: stake}
+	return stake, nil
+}
@@ -0,0 +1,84 @@
+package mantastaking
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	types2 "github.com/Manta-Network/manta-fp/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultEpochStart(t *testing.T) {
+	t.Parallel()
+
+	epochs := &vaultEpochs{durationInit: 1000, duration: 100}
+	tests := []struct {
+		name       string
+		timestamp  uint64
+		epochStart uint64
+		ok         bool
+	}{
+		{name: "before the first epoch", timestamp: 999},
+		{name: "start of the first epoch", timestamp: 1000, epochStart: 1000, ok: true},
+		{name: "end of the first epoch", timestamp: 1099, epochStart: 1000, ok: true},
+		{name: "start of the second epoch", timestamp: 1100, epochStart: 1100, ok: true},
+		{name: "within a later epoch", timestamp: 1550, epochStart: 1500, ok: true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			epochStart, ok := epochs.epochStart(tc.timestamp)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.epochStart, epochStart)
+		})
+	}
+}
+
+func TestHasStakeAtEpochStart(t *testing.T) {
+	t.Parallel()
+
+	backend := newFakeBackend()
+	backend.epochDurationInit = 1000
+	msm, _ := newTestMiddleware(t, backend, nil)
+
+	// the blocks 100 and 108 are in the epoch starting at 1200, its stake is read once and
+	// the vault is read at the latest block
+	hasStake, err := msm.hasStake(context.Background(), testOutput(100, 1, 1))
+	require.NoError(t, err)
+	require.True(t, hasStake)
+	hasStake, err = msm.hasStake(context.Background(), testOutput(108, 2, 2))
+	require.NoError(t, err)
+	require.True(t, hasStake)
+	require.Equal(t, []uint64{1200}, backend.stakeQueries)
+	require.Equal(t, 1, backend.vaultQueries)
+
+	// the blocks before the first epoch of the vault have no stake
+	hasStake, err = msm.hasStake(context.Background(), testOutput(50, 3, 3))
+	require.NoError(t, err)
+	require.False(t, hasStake)
+
+	// the vault is read again once the status of the operator changes
+	sendOperatorEvent(msm, types2.OperatorStatusPaused, 101)
+	msm.processOperatorEvents()
+	_, err = msm.hasStake(context.Background(), testOutput(109, 4, 4))
+	require.NoError(t, err)
+	require.Equal(t, 2, backend.vaultQueries)
+}
+
+func TestZeroStakeOutputsNotSigned(t *testing.T) {
+	t.Parallel()
+
+	backend := newFakeBackend()
+	backend.setStake(big.NewInt(0))
+	msm, _ := newTestMiddleware(t, backend, nil)
+
+	blocks := []*types2.BlockInfo{testBlock(500, 1, 1), testBlock(501, 2, 2)}
+	require.NoError(t, msm.SubmitBatchFinalitySignatures(context.Background(), blocks))
+	require.Empty(t, backend.sentTxs())
+
+	// the outputs are signed once the operator has stake
+	backend.setStake(big.NewInt(1))
+	require.NoError(t, msm.SubmitBatchFinalitySignatures(context.Background(), []*types2.BlockInfo{testBlock(600, 3, 3)}))
+	require.Equal(t, []int64{3}, signedOutputIndexes(t, backend))
+}
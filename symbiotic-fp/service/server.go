@@ -7,6 +7,7 @@ import (
 
 	"github.com/Manta-Network/manta-fp/metrics"
 	fpcfg "github.com/Manta-Network/manta-fp/symbiotic-fp/config"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/mantastaking"
 
 	"github.com/lightningnetwork/lnd/kvdb"
 	"github.com/lightningnetwork/lnd/signal"
@@ -26,17 +27,19 @@ type Server struct {
 	interceptor signal.Interceptor
 
 	metricsServer *metrics.Server
+	msm           *mantastaking.MantaStakingMiddleware
 
 	quit chan struct{}
 }
 
 // NewFinalityproviderServer creates a new server with the given config.
-func NewFinalityProviderServer(cfg *fpcfg.Config, l *zap.Logger, db kvdb.Backend, sig signal.Interceptor) *Server {
+func NewFinalityProviderServer(cfg *fpcfg.Config, l *zap.Logger, db kvdb.Backend, sig signal.Interceptor, msm *mantastaking.MantaStakingMiddleware) *Server {
 	return &Server{
 		cfg:         cfg,
 		logger:      l,
 		db:          db,
 		interceptor: sig,
+		msm:         msm,
 		quit:        make(chan struct{}, 1),
 	}
 }
@@ -52,6 +55,9 @@ func (s *Server) StartFinalityProviderServer() error {
 		return fmt.Errorf("failed to get prometheus address: %w", err)
 	}
 	s.metricsServer = metrics.Start(promAddr, s.logger)
+	s.msm.QueryHandler().Register(s.metricsServer)
+	s.metricsServer.AddLivenessCheck("db", metrics.DBHealthCheck(s.db))
+	s.msm.RegisterHealthChecks(s.metricsServer)
 
 	// All the necessary parts have been registered, so we can
 	// actually start listening for requests.
@@ -0,0 +1,142 @@
+package signer
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	common2 "github.com/Manta-Network/manta-fp/symbiotic-fp/common"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/config"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/hsm"
+)
+
+// Signer holds the operator key of the symbiotic-fp. It signs both the
+// transactions sent by the operator and the finality signatures
+type Signer interface {
+	// Address returns the ethereum address of the operator key
+	Address() common.Address
+	// PublicKey returns the public key of the operator key
+	PublicKey(ctx context.Context) (*ecdsa.PublicKey, error)
+	// SignHash signs the hash, the signature is in the [R || S || V] format where V is 0 or 1
+	SignHash(ctx context.Context, hash common.Hash) ([]byte, error)
+	// TransactOpts returns the transact opts signing transactions with the operator key
+	TransactOpts(ctx context.Context, chainID *big.Int) (*bind.TransactOpts, error)
+}
+
+// NewSigner creates the signer selected by the signer config. The cloud hsm is
+// used when either the hsm signer type is selected or the hsm is enabled in opEventCfg
+func NewSigner(ctx context.Context, cfg *config.SignerConfig, opEventCfg *config.OpEventConfig) (Signer, error) {
+	if cfg.Type == config.SignerTypeHsm || opEventCfg.EnableHsm {
+		if opEventCfg.HsmAddress == "" {
+			return nil, errors.New("need to config hsm address")
+		}
+		mk, err := common2.NewHSMManagedKey(ctx, opEventCfg.HsmApiName, opEventCfg.HsmAddress, opEventCfg.HsmCreden)
+		if err != nil {
+			return nil, err
+		}
+		return NewHsmSigner(mk), nil
+	}
+
+	switch cfg.Type {
+	case config.SignerTypePrivateKey:
+		if cfg.PrivateKey == "" {
+			return nil, errors.New("need to config private key")
+		}
+		privKey, err := common2.ParsePrivateKeyStr(cfg.PrivateKey)
+		if err != nil {
+			return nil, err
+		}
+		return NewPrivateKeySigner(privKey), nil
+	case config.SignerTypeKeystore:
+		return NewKeystoreSigner(cfg.KeystorePath, cfg.KeystorePassphrase)
+	case config.SignerTypeMnemonic:
+		privKey, err := common2.DerivePrivateKey(cfg.Mnemonic, cfg.HdPath, cfg.MnemonicPassphrase)
+		if err != nil {
+			return nil, err
+		}
+		return NewPrivateKeySigner(privKey), nil
+	default:
+		return nil, fmt.Errorf("invalid signer type: %s", cfg.Type)
+	}
+}
+
+// PrivateKeySigner signs with a private key held in memory, it backs the
+// private key, keystore and mnemonic signers
+type PrivateKeySigner struct {
+	privKey *ecdsa.PrivateKey
+	address common.Address
+}
+
+func NewPrivateKeySigner(privKey *ecdsa.PrivateKey) *PrivateKeySigner {
+	return &PrivateKeySigner{
+		privKey: privKey,
+		address: crypto.PubkeyToAddress(privKey.PublicKey),
+	}
+}
+
+// NewKeystoreSigner decrypts the go-ethereum V3 keystore file with the passphrase
+func NewKeystoreSigner(path string, passphrase string) (*PrivateKeySigner, error) {
+	keyJson, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore file %s: %w", path, err)
+	}
+	key, err := keystore.DecryptKey(keyJson, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore file %s: %w", path, err)
+	}
+	return NewPrivateKeySigner(key.PrivateKey), nil
+}
+
+func (s *PrivateKeySigner) Address() common.Address {
+	return s.address
+}
+
+func (s *PrivateKeySigner) PublicKey(_ context.Context) (*ecdsa.PublicKey, error) {
+	return &s.privKey.PublicKey, nil
+}
+
+func (s *PrivateKeySigner) SignHash(_ context.Context, hash common.Hash) ([]byte, error) {
+	return crypto.Sign(hash[:], s.privKey)
+}
+
+func (s *PrivateKeySigner) TransactOpts(ctx context.Context, chainID *big.Int) (*bind.TransactOpts, error) {
+	opts, err := bind.NewKeyedTransactorWithChainID(s.privKey, chainID)
+	if err != nil {
+		return nil, err
+	}
+	opts.Context = ctx
+	return opts, nil
+}
+
+// HsmSigner signs with a key managed by Google Cloud KMS, the key never leaves the hsm
+type HsmSigner struct {
+	mk *hsm.ManagedKey
+}
+
+func NewHsmSigner(mk *hsm.ManagedKey) *HsmSigner {
+	return &HsmSigner{mk: mk}
+}
+
+func (s *HsmSigner) Address() common.Address {
+	return s.mk.EthereumAddr
+}
+
+func (s *HsmSigner) PublicKey(ctx context.Context) (*ecdsa.PublicKey, error) {
+	return s.mk.PublicKey(ctx)
+}
+
+func (s *HsmSigner) SignHash(ctx context.Context, hash common.Hash) ([]byte, error) {
+	return s.mk.SignHash(ctx, hash)
+}
+
+func (s *HsmSigner) TransactOpts(ctx context.Context, chainID *big.Int) (*bind.TransactOpts, error) {
+	return s.mk.NewEthereumTransactorrWithChainID(ctx, chainID)
+}
@@ -0,0 +1,94 @@
+package signer_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/config"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/signer"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testMnemonic = "test test test test test test test test test test test junk"
+
+func requireSignerRecovers(t *testing.T, s signer.Signer) {
+	ctx := context.Background()
+	hash := crypto.Keccak256Hash([]byte("state root"))
+
+	sig, err := s.SignHash(ctx, hash)
+	require.NoError(t, err)
+
+	pubKey, err := crypto.SigToPub(hash[:], sig)
+	require.NoError(t, err)
+	require.Equal(t, s.Address(), crypto.PubkeyToAddress(*pubKey))
+
+	opts, err := s.TransactOpts(ctx, common.Big1)
+	require.NoError(t, err)
+	require.Equal(t, s.Address(), opts.From)
+}
+
+func TestNewSigner(t *testing.T) {
+	privKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	expectedAddr := crypto.PubkeyToAddress(privKey.PublicKey)
+	opEventCfg := config.DefaultOpEventConfig()
+
+	t.Run("private key", func(t *testing.T) {
+		cfg := config.DefaultSignerConfig()
+		cfg.PrivateKey = common.Bytes2Hex(crypto.FromECDSA(privKey))
+
+		s, err := signer.NewSigner(context.Background(), &cfg, &opEventCfg)
+		require.NoError(t, err)
+		require.Equal(t, expectedAddr, s.Address())
+		requireSignerRecovers(t, s)
+	})
+
+	t.Run("keystore", func(t *testing.T) {
+		ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+		account, err := ks.ImportECDSA(privKey, "passphrase")
+		require.NoError(t, err)
+
+		cfg := config.DefaultSignerConfig()
+		cfg.Type = config.SignerTypeKeystore
+		cfg.KeystorePath = account.URL.Path
+		cfg.KeystorePassphrase = "passphrase"
+
+		s, err := signer.NewSigner(context.Background(), &cfg, &opEventCfg)
+		require.NoError(t, err)
+		require.Equal(t, expectedAddr, s.Address())
+		requireSignerRecovers(t, s)
+
+		cfg.KeystorePassphrase = "wrong"
+		_, err = signer.NewSigner(context.Background(), &cfg, &opEventCfg)
+		require.Error(t, err)
+
+		cfg.KeystorePath = filepath.Join(t.TempDir(), "missing.json")
+		_, err = signer.NewSigner(context.Background(), &cfg, &opEventCfg)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("mnemonic", func(t *testing.T) {
+		cfg := config.DefaultSignerConfig()
+		cfg.Type = config.SignerTypeMnemonic
+		cfg.Mnemonic = testMnemonic
+
+		s, err := signer.NewSigner(context.Background(), &cfg, &opEventCfg)
+		require.NoError(t, err)
+		// the first account of the well known test mnemonic
+		require.Equal(t, common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"), s.Address())
+		requireSignerRecovers(t, s)
+	})
+
+	t.Run("missing private key", func(t *testing.T) {
+		cfg := config.DefaultSignerConfig()
+		_, err := signer.NewSigner(context.Background(), &cfg, &opEventCfg)
+		require.Error(t, err)
+	})
+}
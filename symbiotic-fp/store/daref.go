@@ -0,0 +1,118 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/lightningnetwork/lnd/kvdb"
+)
+
+var (
+	ErrCorruptedDARefDb = errors.New("da ref db is corrupted")
+
+	// ErrDARefNotFound da ref not found at given output index
+	ErrDARefNotFound = errors.New("da ref not found")
+)
+
+var (
+	DARefBucketName = []byte("daRef")
+)
+
+// DARef locates the celestia blob which published the signature of a single L2 output,
+// the output index of a dispute game is its record index (see RecordIndex)
+type DARef struct {
+	L2OutputIndex uint64 `json:"l2_output_index"`
+	BlobID        []byte `json:"blob_id"`
+	Height        uint64 `json:"height"`
+	Commitment    []byte `json:"commitment"`
+	Namespace     []byte `json:"namespace"`
+	Timestamp     int64  `json:"timestamp"` // The timestamp of the submission, in Unix milliseconds.
+}
+
+type DARefStore struct {
+	db kvdb.Backend
+}
+
+func NewDARefStore(db kvdb.Backend) (*DARefStore, error) {
+	store := &DARefStore{db}
+	if err := store.initBuckets(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *DARefStore) initBuckets() error {
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		_, err := tx.CreateTopLevelBucket(DARefBucketName)
+		return err
+	})
+}
+
+// SaveDARefs saves the reference of the blob for every given output index, the
+// reference of an output published again is replaced by the latest blob
+func (s *DARefStore) SaveDARefs(
+	l2OutputIndexes []uint64,
+	blobID []byte,
+	height uint64,
+	commitment []byte,
+	namespace []byte,
+) error {
+	timestamp := time.Now().UnixMilli()
+
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(DARefBucketName)
+		if bucket == nil {
+			return ErrCorruptedDARefDb
+		}
+
+		for _, l2OutputIndex := range l2OutputIndexes {
+			daRef := &DARef{
+				L2OutputIndex: l2OutputIndex,
+				BlobID:        blobID,
+				Height:        height,
+				Commitment:    commitment,
+				Namespace:     namespace,
+				Timestamp:     timestamp,
+			}
+
+			marshalled, err := json.Marshal(daRef)
+			if err != nil {
+				return err
+			}
+
+			if err := bucket.Put(getSignRecordKey(l2OutputIndex), marshalled); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *DARefStore) GetDARef(l2OutputIndex uint64) (*DARef, bool, error) {
+	key := getSignRecordKey(l2OutputIndex)
+	res := &DARef{}
+
+	err := s.db.View(func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(DARefBucketName)
+		if bucket == nil {
+			return ErrCorruptedDARefDb
+		}
+
+		daRefBytes := bucket.Get(key)
+		if daRefBytes == nil {
+			return ErrDARefNotFound
+		}
+
+		return json.Unmarshal(daRefBytes, res)
+	}, func() {})
+
+	if err != nil {
+		if errors.Is(err, ErrDARefNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return res, true, nil
+}
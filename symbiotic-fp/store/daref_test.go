@@ -0,0 +1,52 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/config"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/store"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDARefStore(t *testing.T) {
+	t.Parallel()
+
+	dbBackend, err := config.DefaultDBConfigWithHomePath(t.TempDir()).GetDBBackend()
+	require.NoError(t, err)
+	defer dbBackend.Close()
+
+	ds, err := store.NewDARefStore(dbBackend)
+	require.NoError(t, err)
+
+	_, found, err := ds.GetDARef(1)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	namespace := []byte("namespace")
+	err = ds.SaveDARefs([]uint64{1, 2}, []byte("id-1"), 10, []byte("commitment-1"), namespace)
+	require.NoError(t, err)
+
+	ref, found, err := ds.GetDARef(2)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, uint64(2), ref.L2OutputIndex)
+	require.Equal(t, []byte("id-1"), ref.BlobID)
+	require.Equal(t, uint64(10), ref.Height)
+	require.Equal(t, []byte("commitment-1"), ref.Commitment)
+	require.Equal(t, namespace, ref.Namespace)
+
+	// the output published again refers to the latest blob
+	err = ds.SaveDARefs([]uint64{2}, []byte("id-2"), 11, []byte("commitment-2"), namespace)
+	require.NoError(t, err)
+
+	ref, found, err = ds.GetDARef(2)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, uint64(11), ref.Height)
+
+	ref, found, err = ds.GetDARef(1)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, uint64(10), ref.Height)
+}
@@ -0,0 +1,187 @@
+package store
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/Manta-Network/manta-fp/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lightningnetwork/lnd/kvdb"
+)
+
+// ExportSignRecords returns the sign records, including the deleted ones, as the
+// signing history of the operator in the interchange format
+func (s *SignRecordStore) ExportSignRecords(operatorAddr string, chainID string) (*types.InterchangeSigner, error) {
+	var signer *types.InterchangeSigner
+	err := s.db.View(func(tx kvdb.RTx) error {
+		signer = &types.InterchangeSigner{
+			PubKey:         operatorAddr,
+			ChainID:        chainID,
+			SignedMessages: []*types.InterchangeSignedMessage{},
+		}
+		for _, bucketName := range [][]byte{SignRecordBucketName, DeletedSignRecordBucketName} {
+			bucket := tx.ReadBucket(bucketName)
+			if bucket == nil {
+				return ErrCorruptedSignRecordDb
+			}
+			err := bucket.ForEach(func(_, v []byte) error {
+				signRecord := &SigningRecord{}
+				if err := json.Unmarshal(v, signRecord); err != nil {
+					return err
+				}
+				signedMsg := toInterchangeSignedMessage(signRecord)
+				if !signedMsg.DisputeGame && signedMsg.Height > signer.HighestSignedHeight {
+					signer.HighestSignedHeight = signedMsg.Height
+				}
+				signer.SignedMessages = append(signer.SignedMessages, signedMsg)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}, func() {})
+	if err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+// ImportSignRecords merges the signing history of the operator. The import is refused as a
+// whole if a different state root is recorded at the index of an imported record. The full
+// history is required since the outputs deleted from the L2OutputOracle are signed again
+// below the highest signed output index
+func (s *SignRecordStore) ImportSignRecords(signer *types.InterchangeSigner) (imported int, skipped int, err error) {
+	signRecords := make([]*SigningRecord, 0, len(signer.SignedMessages))
+	var highestIndex uint64
+	for _, signedMsg := range signer.SignedMessages {
+		signRecord, err := fromInterchangeSignedMessage(signedMsg)
+		if err != nil {
+			return 0, 0, err
+		}
+		if !signedMsg.DisputeGame && signedMsg.Height > highestIndex {
+			highestIndex = signedMsg.Height
+		}
+		signRecords = append(signRecords, signRecord)
+	}
+	if signer.HighestSignedHeight > highestIndex {
+		return 0, 0, fmt.Errorf("the highest signed output index %d has no sign record, the full signing history is required", signer.HighestSignedHeight)
+	}
+
+	err = kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		imported, skipped = 0, 0
+		bucket := tx.ReadWriteBucket(SignRecordBucketName)
+		if bucket == nil {
+			return ErrCorruptedSignRecordDb
+		}
+		deletedBucket := tx.ReadWriteBucket(DeletedSignRecordBucketName)
+		if deletedBucket == nil {
+			return ErrCorruptedSignRecordDb
+		}
+
+		for _, signRecord := range signRecords {
+			key := getSignRecordKey(signRecord.L2OutputIndex)
+			targetBucket := bucket
+			if signRecord.DeletedAt != 0 {
+				key = getDeletedSignRecordKey(signRecord.L2OutputIndex, signRecord.StateRoot)
+				targetBucket = deletedBucket
+			}
+
+			if v := targetBucket.Get(key); v != nil {
+				existing := &SigningRecord{}
+				if err := json.Unmarshal(v, existing); err != nil {
+					return err
+				}
+				if existing.StateRoot != signRecord.StateRoot {
+					return fmt.Errorf("%w at output index %d", types.ErrConflictingSignRecord, signRecord.L2OutputIndex)
+				}
+				skipped++
+				continue
+			}
+
+			marshalled, err := json.Marshal(signRecord)
+			if err != nil {
+				return err
+			}
+			if err := targetBucket.Put(key, marshalled); err != nil {
+				return err
+			}
+			imported++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return imported, skipped, nil
+}
+
+func toInterchangeSignedMessage(signRecord *SigningRecord) *types.InterchangeSignedMessage {
+	signedMsg := &types.InterchangeSignedMessage{
+		Height:      signRecord.L2OutputIndex &^ DisputeGameRecordFlag,
+		Msg:         hex.EncodeToString(signRecord.StateRoot[:]),
+		Signature:   hex.EncodeToString(signRecord.Signature),
+		Timestamp:   signRecord.Timestamp,
+		DisputeGame: signRecord.L2OutputIndex&DisputeGameRecordFlag != 0,
+		DeletedAt:   signRecord.DeletedAt,
+	}
+	if signRecord.SignatureVersion != types.SignRequestVersionLegacy {
+		signedMsg.SignatureVersion = signRecord.SignatureVersion
+		signedMsg.L1BlockHash = signRecord.L1BlockHash.Hex()
+	}
+	if signRecord.L2BlockNumber != nil {
+		l2BlockNumber := signRecord.L2BlockNumber.Uint64()
+		signedMsg.L2BlockNumber = &l2BlockNumber
+	}
+	return signedMsg
+}
+
+func fromInterchangeSignedMessage(signedMsg *types.InterchangeSignedMessage) (*SigningRecord, error) {
+	if signedMsg.Height&DisputeGameRecordFlag != 0 {
+		return nil, fmt.Errorf("the height %d is out of range", signedMsg.Height)
+	}
+	stateRoot, err := hex.DecodeString(signedMsg.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid state root at height %d: %w", signedMsg.Height, err)
+	}
+	if len(stateRoot) != 32 {
+		return nil, fmt.Errorf("invalid state root at height %d: the state root is not 32 bytes", signedMsg.Height)
+	}
+	signature, err := hex.DecodeString(signedMsg.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature at height %d: %w", signedMsg.Height, err)
+	}
+
+	signRecord := &SigningRecord{
+		L2OutputIndex:    RecordIndex(signedMsg.Height, signedMsg.DisputeGame),
+		Signature:        signature,
+		Timestamp:        signedMsg.Timestamp,
+		DeletedAt:        signedMsg.DeletedAt,
+		SignatureVersion: signedMsg.SignatureVersion,
+	}
+	copy(signRecord.StateRoot[:], stateRoot)
+	if signedMsg.SignatureVersion != types.SignRequestVersionLegacy {
+		if !isHexHash(signedMsg.L1BlockHash) {
+			return nil, fmt.Errorf("invalid l1 block hash at height %d", signedMsg.Height)
+		}
+		signRecord.L1BlockHash = common.HexToHash(signedMsg.L1BlockHash)
+	}
+	if signedMsg.L2BlockNumber != nil {
+		signRecord.L2BlockNumber = new(big.Int).SetUint64(*signedMsg.L2BlockNumber)
+	}
+	return signRecord, nil
+}
+
+// isHexHash tells whether s is a 32 bytes hex string with the 0x prefix
+func isHexHash(s string) bool {
+	if len(s) != 2+2*common.HashLength || !strings.HasPrefix(s, "0x") {
+		return false
+	}
+	_, err := hex.DecodeString(s[2:])
+	return err == nil
+}
@@ -0,0 +1,240 @@
+package store
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/lightningnetwork/lnd/kvdb"
+)
+
+var (
+	ErrCorruptedSignRecordDb = errors.New("sign record db is corrupted")
+
+	// ErrSignRecordNotFound sign record not found at given output index
+	ErrSignRecordNotFound = errors.New("sign record not found")
+
+	// ErrDuplicateSignRecord indicates err if sign record is already saved at given output index
+	ErrDuplicateSignRecord = errors.New("sign record for given output index already exists")
+)
+
+var (
+	SignRecordBucketName        = []byte("signRecord")
+	DeletedSignRecordBucketName = []byte("deletedSignRecord")
+)
+
+// DisputeGameRecordFlag is set in the record index of a dispute game so that the
+// indexes of the games don't collide with the output indexes of the L2OutputOracle
+const DisputeGameRecordFlag = uint64(1) << 63
+
+// RecordIndex returns the index the signature of an output is recorded at, it's the
+// L2 output index or the flagged game index of a dispute game
+func RecordIndex(l2OutputIndex uint64, isDisputeGame bool) uint64 {
+	if isDisputeGame {
+		return l2OutputIndex | DisputeGameRecordFlag
+	}
+	return l2OutputIndex
+}
+
+// SigningRecord is the ECDSA signature the operator made over the
+// state root of a single L2 output, the output index of a dispute game
+// is its record index (see RecordIndex)
+type SigningRecord struct {
+	L2OutputIndex uint64   `json:"l2_output_index"`
+	L2BlockNumber *big.Int `json:"l2_block_number"`
+	StateRoot     [32]byte `json:"state_root"`
+	Signature     []byte   `json:"signature"`
+	Timestamp     int64    `json:"timestamp"` // The timestamp of the signing operation, in Unix milliseconds.
+	// DeletedAt is set once the signed output is deleted from the L2OutputOracle, in Unix milliseconds.
+	DeletedAt int64 `json:"deleted_at,omitempty"`
+	// SignatureVersion is the version of the sign request the signature is made for, the
+	// records saved before the typed data are legacy signatures of the raw state root.
+	SignatureVersion uint8 `json:"signature_version,omitempty"`
+	// L1BlockHash is the L1 block hash of the signed typed data, unset for the legacy signatures.
+	L1BlockHash common.Hash `json:"l1_block_hash"`
+}
+
+type SignRecordStore struct {
+	db kvdb.Backend
+}
+
+func NewSignRecordStore(db kvdb.Backend) (*SignRecordStore, error) {
+	store := &SignRecordStore{db}
+	if err := store.initBuckets(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (s *SignRecordStore) initBuckets() error {
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		_, err := tx.CreateTopLevelBucket(SignRecordBucketName)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.CreateTopLevelBucket(DeletedSignRecordBucketName)
+		return err
+	})
+}
+
+func (s *SignRecordStore) SaveSignRecord(
+	l2OutputIndex uint64,
+	l2BlockNumber *big.Int,
+	stateRoot [32]byte,
+	signature []byte,
+	signatureVersion uint8,
+	l1BlockHash common.Hash,
+) error {
+	key := getSignRecordKey(l2OutputIndex)
+
+	return kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		bucket := tx.ReadWriteBucket(SignRecordBucketName)
+		if bucket == nil {
+			return ErrCorruptedSignRecordDb
+		}
+
+		if bucket.Get(key) != nil {
+			return ErrDuplicateSignRecord
+		}
+
+		signRecord := &SigningRecord{
+			L2OutputIndex:    l2OutputIndex,
+			L2BlockNumber:    l2BlockNumber,
+			StateRoot:        stateRoot,
+			Signature:        signature,
+			Timestamp:        time.Now().UnixMilli(),
+			SignatureVersion: signatureVersion,
+			L1BlockHash:      l1BlockHash,
+		}
+
+		marshalled, err := json.Marshal(signRecord)
+		if err != nil {
+			return err
+		}
+
+		return bucket.Put(key, marshalled)
+	})
+}
+
+func (s *SignRecordStore) GetSignRecord(l2OutputIndex uint64) (*SigningRecord, bool, error) {
+	key := getSignRecordKey(l2OutputIndex)
+	res := &SigningRecord{}
+
+	err := s.db.View(func(tx kvdb.RTx) error {
+		bucket := tx.ReadBucket(SignRecordBucketName)
+		if bucket == nil {
+			return ErrCorruptedSignRecordDb
+		}
+
+		signRecordBytes := bucket.Get(key)
+		if signRecordBytes == nil {
+			return ErrSignRecordNotFound
+		}
+
+		return json.Unmarshal(signRecordBytes, res)
+	}, func() {})
+
+	if err != nil {
+		if errors.Is(err, ErrSignRecordNotFound) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	return res, true, nil
+}
+
+// MarkSignRecordDeleted records that the signature of the output covered a root deleted from
+// the L2OutputOracle. The record is moved to the deleted records so that the output proposed
+// again at the index can be signed, false is returned if the root wasn't signed at the index
+func (s *SignRecordStore) MarkSignRecordDeleted(l2OutputIndex uint64, stateRoot [32]byte) (bool, error) {
+	key := getSignRecordKey(l2OutputIndex)
+
+	var marked bool
+	err := kvdb.Batch(s.db, func(tx kvdb.RwTx) error {
+		marked = false
+		bucket := tx.ReadWriteBucket(SignRecordBucketName)
+		if bucket == nil {
+			return ErrCorruptedSignRecordDb
+		}
+		deletedBucket := tx.ReadWriteBucket(DeletedSignRecordBucketName)
+		if deletedBucket == nil {
+			return ErrCorruptedSignRecordDb
+		}
+
+		signRecordBytes := bucket.Get(key)
+		if signRecordBytes == nil {
+			return nil
+		}
+		signRecord := &SigningRecord{}
+		if err := json.Unmarshal(signRecordBytes, signRecord); err != nil {
+			return err
+		}
+		if signRecord.StateRoot != stateRoot {
+			return nil
+		}
+
+		signRecord.DeletedAt = time.Now().UnixMilli()
+		marshalled, err := json.Marshal(signRecord)
+		if err != nil {
+			return err
+		}
+		if err := deletedBucket.Put(getDeletedSignRecordKey(l2OutputIndex, stateRoot), marshalled); err != nil {
+			return err
+		}
+		marked = true
+		return bucket.Delete(key)
+	})
+	if err != nil {
+		return false, err
+	}
+	return marked, nil
+}
+
+// GetDeletedSignRecords returns the signatures made at the output index over the roots
+// deleted from the L2OutputOracle
+func (s *SignRecordStore) GetDeletedSignRecords(l2OutputIndex uint64) ([]*SigningRecord, error) {
+	prefix := getSignRecordKey(l2OutputIndex)
+
+	var records []*SigningRecord
+	err := s.db.View(func(tx kvdb.RTx) error {
+		records = nil
+		bucket := tx.ReadBucket(DeletedSignRecordBucketName)
+		if bucket == nil {
+			return ErrCorruptedSignRecordDb
+		}
+
+		cursor := bucket.ReadCursor()
+		for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Next() {
+			signRecord := &SigningRecord{}
+			if err := json.Unmarshal(v, signRecord); err != nil {
+				return err
+			}
+			records = append(records, signRecord)
+		}
+		return nil
+	}, func() {})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// the record key is the big endian L2 output index so that records are
+// iterated in the order of outputs
+func getSignRecordKey(l2OutputIndex uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, l2OutputIndex)
+	return key
+}
+
+// the deleted record key is the record key followed by the deleted state root
+// since several roots can be signed and deleted at an output index
+func getDeletedSignRecordKey(l2OutputIndex uint64, stateRoot [32]byte) []byte {
+	return append(getSignRecordKey(l2OutputIndex), stateRoot[:]...)
+}
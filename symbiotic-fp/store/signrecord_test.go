@@ -0,0 +1,176 @@
+package store_test
+
+import (
+	"encoding/hex"
+	"math/big"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/config"
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/store"
+	"github.com/Manta-Network/manta-fp/testutil"
+	"github.com/Manta-Network/manta-fp/types"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// FuzzSignRecordStore tests save sign records
+func FuzzSignRecordStore(f *testing.F) {
+	testutil.AddRandomSeedsToFuzzer(f, 10)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		t.Parallel()
+		r := rand.New(rand.NewSource(seed))
+
+		homePath := t.TempDir()
+		cfg := config.DefaultDBConfigWithHomePath(homePath)
+
+		dbBackend, err := cfg.GetDBBackend()
+		require.NoError(t, err)
+
+		ss, err := store.NewSignRecordStore(dbBackend)
+		require.NoError(t, err)
+
+		defer func() {
+			dbBackend.Close()
+			err := os.RemoveAll(homePath)
+			require.NoError(t, err)
+		}()
+
+		outputIndex := r.Uint64()
+		l2BlockNumber := new(big.Int).SetUint64(r.Uint64())
+		var stateRoot [32]byte
+		copy(stateRoot[:], testutil.GenRandomByteArray(r, 32))
+		sig := testutil.GenRandomByteArray(r, 65)
+		l1BlockHash := common.BytesToHash(testutil.GenRandomByteArray(r, 32))
+
+		// save for the first time
+		err = ss.SaveSignRecord(outputIndex, l2BlockNumber, stateRoot, sig, types.SignRequestVersionTyped, l1BlockHash)
+		require.NoError(t, err)
+
+		// try to save the record at the same output index
+		err = ss.SaveSignRecord(outputIndex, l2BlockNumber, stateRoot, sig, types.SignRequestVersionTyped, l1BlockHash)
+		require.ErrorIs(t, err, store.ErrDuplicateSignRecord)
+
+		signRecordFromDB, found, err := ss.GetSignRecord(outputIndex)
+		require.NoError(t, err)
+		require.True(t, found)
+		require.Equal(t, outputIndex, signRecordFromDB.L2OutputIndex)
+		require.Equal(t, l2BlockNumber, signRecordFromDB.L2BlockNumber)
+		require.Equal(t, stateRoot, signRecordFromDB.StateRoot)
+		require.Equal(t, sig, signRecordFromDB.Signature)
+		require.Equal(t, types.SignRequestVersionTyped, signRecordFromDB.SignatureVersion)
+		require.Equal(t, l1BlockHash, signRecordFromDB.L1BlockHash)
+
+		_, found, err = ss.GetSignRecord(outputIndex + 1)
+		require.NoError(t, err)
+		require.False(t, found)
+	})
+}
+
+func TestMarkSignRecordDeleted(t *testing.T) {
+	t.Parallel()
+
+	dbBackend, err := config.DefaultDBConfigWithHomePath(t.TempDir()).GetDBBackend()
+	require.NoError(t, err)
+	defer dbBackend.Close()
+
+	ss, err := store.NewSignRecordStore(dbBackend)
+	require.NoError(t, err)
+
+	deletedRoot, newRoot := [32]byte{1}, [32]byte{2}
+	err = ss.SaveSignRecord(5, big.NewInt(500), deletedRoot, []byte("sig-1"), types.SignRequestVersionLegacy, common.Hash{})
+	require.NoError(t, err)
+
+	// another root at the index isn't marked
+	marked, err := ss.MarkSignRecordDeleted(5, newRoot)
+	require.NoError(t, err)
+	require.False(t, marked)
+
+	marked, err = ss.MarkSignRecordDeleted(5, deletedRoot)
+	require.NoError(t, err)
+	require.True(t, marked)
+
+	// the output proposed again at the index can be signed
+	_, found, err := ss.GetSignRecord(5)
+	require.NoError(t, err)
+	require.False(t, found)
+	err = ss.SaveSignRecord(5, big.NewInt(500), newRoot, []byte("sig-2"), types.SignRequestVersionLegacy, common.Hash{})
+	require.NoError(t, err)
+
+	records, err := ss.GetDeletedSignRecords(5)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, deletedRoot, records[0].StateRoot)
+	require.Equal(t, []byte("sig-1"), records[0].Signature)
+	require.NotZero(t, records[0].DeletedAt)
+
+	records, err = ss.GetDeletedSignRecords(6)
+	require.NoError(t, err)
+	require.Empty(t, records)
+}
+
+func TestSignRecordsInterchange(t *testing.T) {
+	t.Parallel()
+
+	newStore := func() *store.SignRecordStore {
+		dbBackend, err := config.DefaultDBConfigWithHomePath(t.TempDir()).GetDBBackend()
+		require.NoError(t, err)
+		t.Cleanup(func() { dbBackend.Close() })
+		ss, err := store.NewSignRecordStore(dbBackend)
+		require.NoError(t, err)
+		return ss
+	}
+	from, to := newStore(), newStore()
+
+	deletedRoot, root, gameRoot := [32]byte{1}, [32]byte{2}, [32]byte{3}
+	l1BlockHash := common.Hash{4}
+	require.NoError(t, from.SaveSignRecord(5, big.NewInt(500), deletedRoot, []byte("sig-1"), types.SignRequestVersionLegacy, common.Hash{}))
+	marked, err := from.MarkSignRecordDeleted(5, deletedRoot)
+	require.NoError(t, err)
+	require.True(t, marked)
+	require.NoError(t, from.SaveSignRecord(5, big.NewInt(500), root, []byte("sig-2"), types.SignRequestVersionLegacy, common.Hash{}))
+	require.NoError(t, from.SaveSignRecord(store.RecordIndex(9, true), big.NewInt(900), gameRoot, []byte("sig-3"), types.SignRequestVersionTyped, l1BlockHash))
+
+	signer, err := from.ExportSignRecords("0x01", "1")
+	require.NoError(t, err)
+	require.Len(t, signer.SignedMessages, 3)
+	require.Equal(t, uint64(5), signer.HighestSignedHeight)
+
+	imported, skipped, err := to.ImportSignRecords(signer)
+	require.NoError(t, err)
+	require.Equal(t, 3, imported)
+	require.Equal(t, 0, skipped)
+
+	record, found, err := to.GetSignRecord(5)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, root, record.StateRoot)
+	require.Equal(t, big.NewInt(500), record.L2BlockNumber)
+	record, found, err = to.GetSignRecord(store.RecordIndex(9, true))
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, gameRoot, record.StateRoot)
+	require.Equal(t, types.SignRequestVersionTyped, record.SignatureVersion)
+	require.Equal(t, l1BlockHash, record.L1BlockHash)
+	deletedRecords, err := to.GetDeletedSignRecords(5)
+	require.NoError(t, err)
+	require.Len(t, deletedRecords, 1)
+	require.Equal(t, deletedRoot, deletedRecords[0].StateRoot)
+
+	// importing the same records again is a no-op
+	imported, skipped, err = to.ImportSignRecords(signer)
+	require.NoError(t, err)
+	require.Equal(t, 0, imported)
+	require.Equal(t, 3, skipped)
+
+	// a different root at a signed index is refused
+	signer.SignedMessages[1].Msg = hex.EncodeToString(make([]byte, 32))
+	_, _, err = to.ImportSignRecords(signer)
+	require.ErrorIs(t, err, types.ErrConflictingSignRecord)
+
+	// a history without the records up to the highest signed index is refused
+	_, _, err = newStore().ImportSignRecords(&types.InterchangeSigner{PubKey: "0x01", ChainID: "1", HighestSignedHeight: 7})
+	require.Error(t, err)
+}
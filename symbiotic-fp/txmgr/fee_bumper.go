@@ -0,0 +1,144 @@
+package txmgr
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// GasFees are the fees of a transaction, only GasPrice is set for legacy transactions
+type GasFees struct {
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+	GasPrice  *big.Int
+}
+
+func (f *GasFees) IsLegacy() bool {
+	return f.GasPrice != nil
+}
+
+// Apply sets the fees on the transact opts
+func (f *GasFees) Apply(opts *bind.TransactOpts) {
+	if f.IsLegacy() {
+		opts.GasPrice = f.GasPrice
+		opts.GasTipCap = nil
+		opts.GasFeeCap = nil
+		return
+	}
+	opts.GasPrice = nil
+	opts.GasTipCap = f.GasTipCap
+	opts.GasFeeCap = f.GasFeeCap
+}
+
+// Metricer records the submission attempts of the tx manager, the gas price of the legacy
+// transactions is recorded as both their gas tip cap and gas fee cap
+type Metricer interface {
+	RecordTxAttempt(attempt uint64, gasTipCap, gasFeeCap *big.Int)
+	RecordTxFeeCapReached()
+}
+
+type noopMetricer struct{}
+
+func (noopMetricer) RecordTxAttempt(uint64, *big.Int, *big.Int) {}
+
+func (noopMetricer) RecordTxFeeCapReached() {}
+
+// FeeBumper prices the submission attempts of a single transaction for replace-by-fee.
+// Every attempt pays at least the market fees and raises the fees of the previous
+// attempt by the bump percentage, the fee cap never exceeds the max fee cap
+type FeeBumper struct {
+	bumpPercent  uint64
+	maxGasFeeCap *big.Int
+	metrics      Metricer
+
+	mu       sync.Mutex
+	attempts uint64
+	prev     *GasFees
+}
+
+func NewFeeBumper(bumpPercent uint64, maxGasFeeCap *big.Int, metrics Metricer) *FeeBumper {
+	if metrics == nil {
+		metrics = noopMetricer{}
+	}
+	return &FeeBumper{
+		bumpPercent:  bumpPercent,
+		maxGasFeeCap: maxGasFeeCap,
+		metrics:      metrics,
+	}
+}
+
+// Bump returns the fees of the next attempt given the current market fees
+func (b *FeeBumper) Bump(market *GasFees) *GasFees {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	fees := &GasFees{}
+	if market.IsLegacy() {
+		fees.GasPrice = new(big.Int).Set(market.GasPrice)
+	} else {
+		fees.GasTipCap = new(big.Int).Set(market.GasTipCap)
+		fees.GasFeeCap = new(big.Int).Set(market.GasFeeCap)
+	}
+
+	// the fees of a replacement are only comparable within the same transaction type
+	if b.prev != nil && b.prev.IsLegacy() == fees.IsLegacy() {
+		if fees.IsLegacy() {
+			fees.GasPrice = bigMax(fees.GasPrice, b.bump(b.prev.GasPrice))
+		} else {
+			fees.GasTipCap = bigMax(fees.GasTipCap, b.bump(b.prev.GasTipCap))
+			fees.GasFeeCap = bigMax(fees.GasFeeCap, b.bump(b.prev.GasFeeCap))
+		}
+	}
+
+	if b.maxGasFeeCap != nil && b.maxGasFeeCap.Sign() > 0 {
+		capped := false
+		if fees.IsLegacy() && fees.GasPrice.Cmp(b.maxGasFeeCap) > 0 {
+			fees.GasPrice = new(big.Int).Set(b.maxGasFeeCap)
+			capped = true
+		}
+		if !fees.IsLegacy() && fees.GasFeeCap.Cmp(b.maxGasFeeCap) > 0 {
+			fees.GasFeeCap = new(big.Int).Set(b.maxGasFeeCap)
+			fees.GasTipCap = bigMin(fees.GasTipCap, fees.GasFeeCap)
+			capped = true
+		}
+		if capped {
+			log.Warn("ContractsCaller gas fee reached the max fee cap", "maxGasFeeCap", b.maxGasFeeCap)
+			b.metrics.RecordTxFeeCapReached()
+		}
+	}
+
+	b.attempts++
+	b.prev = fees
+	if fees.IsLegacy() {
+		b.metrics.RecordTxAttempt(b.attempts, fees.GasPrice, fees.GasPrice)
+	} else {
+		b.metrics.RecordTxAttempt(b.attempts, fees.GasTipCap, fees.GasFeeCap)
+	}
+	return fees
+}
+
+// bump raises the fee by the bump percentage and at least by one wei
+func (b *FeeBumper) bump(fee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, new(big.Int).SetUint64(100+b.bumpPercent))
+	bumped.Div(bumped, big.NewInt(100))
+	if bumped.Cmp(fee) <= 0 {
+		bumped.Add(fee, big.NewInt(1))
+	}
+	return bumped
+}
+
+func bigMax(a, b *big.Int) *big.Int {
+	if a.Cmp(b) >= 0 {
+		return a
+	}
+	return b
+}
+
+func bigMin(a, b *big.Int) *big.Int {
+	if a.Cmp(b) <= 0 {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,77 @@
+package txmgr_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/txmgr"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockMetricer struct {
+	attempts        uint64
+	feeCapReached   int
+	lastAttemptFees *txmgr.GasFees
+}
+
+func (m *mockMetricer) RecordTxAttempt(attempt uint64, gasTipCap, gasFeeCap *big.Int) {
+	m.attempts = attempt
+	m.lastAttemptFees = &txmgr.GasFees{GasTipCap: gasTipCap, GasFeeCap: gasFeeCap}
+}
+
+func (m *mockMetricer) RecordTxFeeCapReached() {
+	m.feeCapReached++
+}
+
+func dynamicFees(gasTipCap, gasFeeCap int64) *txmgr.GasFees {
+	return &txmgr.GasFees{GasTipCap: big.NewInt(gasTipCap), GasFeeCap: big.NewInt(gasFeeCap)}
+}
+
+func TestFeeBumperBumpsDynamicFees(t *testing.T) {
+	t.Parallel()
+
+	metrics := &mockMetricer{}
+	bumper := txmgr.NewFeeBumper(10, big.NewInt(150), metrics)
+
+	// the first attempt pays the market fees
+	fees := bumper.Bump(dynamicFees(10, 100))
+	require.Equal(t, dynamicFees(10, 100), fees)
+
+	// the resubmission raises the previous fees when the market is unchanged
+	fees = bumper.Bump(dynamicFees(10, 100))
+	require.Equal(t, dynamicFees(11, 110), fees)
+
+	// the market fees are paid if they are higher than the bumped fees
+	fees = bumper.Bump(dynamicFees(20, 130))
+	require.Equal(t, dynamicFees(20, 130), fees)
+
+	// the fee cap never exceeds the max fee cap
+	fees = bumper.Bump(dynamicFees(20, 130))
+	require.Equal(t, dynamicFees(22, 143), fees)
+	fees = bumper.Bump(dynamicFees(200, 300))
+	require.Equal(t, dynamicFees(150, 150), fees)
+
+	require.Equal(t, uint64(5), metrics.attempts)
+	require.Equal(t, 1, metrics.feeCapReached)
+	require.Equal(t, fees, metrics.lastAttemptFees)
+}
+
+func TestFeeBumperBumpsLegacyGasPrice(t *testing.T) {
+	t.Parallel()
+
+	bumper := txmgr.NewFeeBumper(10, nil, nil)
+
+	fees := bumper.Bump(&txmgr.GasFees{GasPrice: big.NewInt(100)})
+	require.True(t, fees.IsLegacy())
+	require.Equal(t, big.NewInt(100), fees.GasPrice)
+
+	fees = bumper.Bump(&txmgr.GasFees{GasPrice: big.NewInt(90)})
+	require.Equal(t, big.NewInt(110), fees.GasPrice)
+
+	// the bump is at least one wei
+	bumper = txmgr.NewFeeBumper(10, nil, nil)
+	bumper.Bump(&txmgr.GasFees{GasPrice: big.NewInt(1)})
+	fees = bumper.Bump(&txmgr.GasFees{GasPrice: big.NewInt(1)})
+	require.Equal(t, big.NewInt(2), fees.GasPrice)
+}
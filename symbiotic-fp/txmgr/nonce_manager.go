@@ -0,0 +1,120 @@
+package txmgr
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// NonceSource returns the next nonce of the account including the pending transactions
+type NonceSource interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// NonceManager hands out the nonces of an account locally so that concurrent
+// transactions don't build on the same nonce. The nonce of a transaction which
+// was never mined is released and handed out again to fill the gap, the nonces
+// are synced from the chain again after the tx manager aborts on nonce too low
+type NonceManager struct {
+	backend NonceSource
+	account common.Address
+
+	mu       sync.Mutex
+	synced   bool
+	next     uint64
+	released []uint64
+}
+
+func NewNonceManager(backend NonceSource, account common.Address) *NonceManager {
+	return &NonceManager{
+		backend: backend,
+		account: account,
+	}
+}
+
+// Next returns the lowest released nonce, or the next nonce of the account
+func (m *NonceManager) Next(ctx context.Context) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.synced {
+		if err := m.sync(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(m.released) > 0 {
+		nonce := m.released[0]
+		m.released = m.released[1:]
+		return nonce, nil
+	}
+
+	nonce := m.next
+	m.next++
+	return nonce, nil
+}
+
+// Release hands the nonce of a transaction which was never mined back out
+func (m *NonceManager) Release(nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.synced || nonce >= m.next {
+		return
+	}
+	// the tx building on the latest nonce failed, there is no gap to fill
+	if nonce == m.next-1 {
+		m.next--
+		m.trimReleased()
+		return
+	}
+	i := sort.Search(len(m.released), func(i int) bool { return m.released[i] >= nonce })
+	if i < len(m.released) && m.released[i] == nonce {
+		return
+	}
+	m.released = append(m.released, 0)
+	copy(m.released[i+1:], m.released[i:])
+	m.released[i] = nonce
+}
+
+// Reset syncs the nonces from the chain on the next call of Next, it's called
+// when the local nonce is behind the account nonce
+func (m *NonceManager) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.synced = false
+}
+
+func (m *NonceManager) sync(ctx context.Context) error {
+	nonce, err := m.backend.PendingNonceAt(ctx, m.account)
+	if err != nil {
+		return err
+	}
+	if m.next != nonce {
+		log.Info("ContractsCaller nonce synced from the chain", "account", m.account, "local", m.next, "chain", nonce)
+	}
+
+	// the released nonces below the chain nonce have been used by other transactions
+	i := sort.Search(len(m.released), func(i int) bool { return m.released[i] >= nonce })
+	m.released = m.released[i:]
+	m.next = nonce
+	m.trimReleased()
+	m.synced = true
+	return nil
+}
+
+// trimReleased drops the released nonces which are not below the next nonce
+func (m *NonceManager) trimReleased() {
+	for len(m.released) > 0 && m.released[len(m.released)-1] >= m.next {
+		m.released = m.released[:len(m.released)-1]
+	}
+	// the released nonces right below the next nonce are a tail, not a gap
+	for len(m.released) > 0 && m.released[len(m.released)-1] == m.next-1 {
+		m.released = m.released[:len(m.released)-1]
+		m.next--
+	}
+}
@@ -0,0 +1,163 @@
+package txmgr_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/Manta-Network/manta-fp/symbiotic-fp/txmgr"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockNonceSource struct {
+	mu    sync.Mutex
+	nonce uint64
+	calls int
+	err   error
+}
+
+func (s *mockNonceSource) PendingNonceAt(_ context.Context, _ common.Address) (uint64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	return s.nonce, s.err
+}
+
+func (s *mockNonceSource) setNonce(nonce uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nonce = nonce
+}
+
+func nextNonce(t *testing.T, m *txmgr.NonceManager) uint64 {
+	nonce, err := m.Next(context.Background())
+	require.NoError(t, err)
+	return nonce
+}
+
+func TestNonceManagerHandsOutSequentialNonces(t *testing.T) {
+	t.Parallel()
+
+	source := &mockNonceSource{nonce: 7}
+	m := txmgr.NewNonceManager(source, common.Address{})
+
+	require.Equal(t, uint64(7), nextNonce(t, m))
+	require.Equal(t, uint64(8), nextNonce(t, m))
+	require.Equal(t, uint64(9), nextNonce(t, m))
+	require.Equal(t, 1, source.calls)
+}
+
+func TestNonceManagerConcurrentNoncesAreUnique(t *testing.T) {
+	t.Parallel()
+
+	m := txmgr.NewNonceManager(&mockNonceSource{}, common.Address{})
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		nonces = make(map[uint64]struct{})
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, err := m.Next(context.Background())
+			require.NoError(t, err)
+			mu.Lock()
+			nonces[nonce] = struct{}{}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	require.Len(t, nonces, 50)
+}
+
+func TestNonceManagerFillsReleasedGap(t *testing.T) {
+	t.Parallel()
+
+	m := txmgr.NewNonceManager(&mockNonceSource{}, common.Address{})
+	for i := 0; i < 4; i++ {
+		nextNonce(t, m)
+	}
+
+	// the transactions at 1 and 2 are dropped, 0 and 3 are pending
+	m.Release(2)
+	m.Release(1)
+	require.Equal(t, uint64(1), nextNonce(t, m))
+	require.Equal(t, uint64(2), nextNonce(t, m))
+	require.Equal(t, uint64(4), nextNonce(t, m))
+}
+
+func TestNonceManagerReleaseLatestNonce(t *testing.T) {
+	t.Parallel()
+
+	m := txmgr.NewNonceManager(&mockNonceSource{}, common.Address{})
+	for i := 0; i < 3; i++ {
+		nextNonce(t, m)
+	}
+
+	m.Release(1)
+	m.Release(2)
+	require.Equal(t, uint64(1), nextNonce(t, m))
+	require.Equal(t, uint64(2), nextNonce(t, m))
+	require.Equal(t, uint64(3), nextNonce(t, m))
+
+	// releasing an unknown nonce is a no-op
+	m.Release(10)
+	require.Equal(t, uint64(4), nextNonce(t, m))
+}
+
+func TestNonceManagerResyncAfterReset(t *testing.T) {
+	t.Parallel()
+
+	source := &mockNonceSource{}
+	m := txmgr.NewNonceManager(source, common.Address{})
+	for i := 0; i < 4; i++ {
+		nextNonce(t, m)
+	}
+	m.Release(1)
+
+	// another sender used nonces up to 5
+	source.setNonce(6)
+	m.Reset()
+	require.Equal(t, uint64(6), nextNonce(t, m))
+	require.Equal(t, uint64(7), nextNonce(t, m))
+	require.Equal(t, 2, source.calls)
+}
+
+func TestNonceManagerResyncBehindLocalNonce(t *testing.T) {
+	t.Parallel()
+
+	source := &mockNonceSource{}
+	m := txmgr.NewNonceManager(source, common.Address{})
+	for i := 0; i < 6; i++ {
+		nextNonce(t, m)
+	}
+	m.Release(1)
+	m.Release(3)
+
+	// the transactions from 2 were dropped by the node, they are handed out again
+	source.setNonce(2)
+	m.Reset()
+	require.Equal(t, uint64(2), nextNonce(t, m))
+	require.Equal(t, uint64(3), nextNonce(t, m))
+	require.Equal(t, uint64(4), nextNonce(t, m))
+}
+
+func TestNonceManagerSyncError(t *testing.T) {
+	t.Parallel()
+
+	source := &mockNonceSource{err: errors.New("unavailable")}
+	m := txmgr.NewNonceManager(source, common.Address{})
+
+	_, err := m.Next(context.Background())
+	require.Error(t, err)
+
+	source.err = nil
+	source.setNonce(3)
+	require.Equal(t, uint64(3), nextNonce(t, m))
+}
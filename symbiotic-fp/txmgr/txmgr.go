@@ -2,16 +2,29 @@ package txmgr
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math/big"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/txpool"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
 )
 
+// ErrNonceTooLowAbort is returned by Send when the nonce of the transaction has
+// been used by another transaction, the nonce must be synced from the chain
+var ErrNonceTooLowAbort = errors.New("txmgr: aborted after too many nonce too low errors")
+
+// ErrTxNotPublished is returned by Send when every attempt of the transaction was rejected
+// by the node or never sent, the nonce of the transaction is then still unused
+var ErrTxNotPublished = errors.New("txmgr: the transaction was never published")
+
 type UpdateGasPriceFunc = func(ctx context.Context) (*types.Transaction, error)
 
 type SendTransactionFunc = func(ctx context.Context, tx *types.Transaction) error
@@ -21,10 +34,17 @@ type Config struct {
 	ReceiptQueryInterval      time.Duration
 	NumConfirmations          uint64
 	SafeAbortNonceTooLowCount uint64
+	// FeeBumpPercent is the percentage by which every resubmission raises the fees
+	FeeBumpPercent uint64
+	// MaxGasFeeCap caps the fee cap of the resubmissions, no cap if it's nil
+	MaxGasFeeCap *big.Int
+	Metrics      Metricer
 }
 
 type TxManager interface {
 	Send(ctx context.Context, updateGasPrice UpdateGasPriceFunc, sendTxn SendTransactionFunc) (*types.Receipt, error)
+	// NewFeeBumper returns the fee bumper pricing the attempts of a single transaction
+	NewFeeBumper() *FeeBumper
 }
 
 type ReceiptSource interface {
@@ -48,6 +68,10 @@ func NewSimpleTxManager(cfg Config, backend ReceiptSource) *SimpleTxManager {
 	}
 }
 
+func (m *SimpleTxManager) NewFeeBumper() *FeeBumper {
+	return NewFeeBumper(m.cfg.FeeBumpPercent, m.cfg.MaxGasFeeCap, m.cfg.Metrics)
+}
+
 func (m *SimpleTxManager) Send(ctx context.Context, updateGasPrice UpdateGasPriceFunc, sendTx SendTransactionFunc) (*types.Receipt, error) {
 	var wg sync.WaitGroup
 	defer wg.Wait()
@@ -55,6 +79,7 @@ func (m *SimpleTxManager) Send(ctx context.Context, updateGasPrice UpdateGasPric
 	defer cancel()
 
 	sendState := NewSendState(m.cfg.SafeAbortNonceTooLowCount)
+	var nonceTooLow, published atomic.Bool
 
 	receiptChan := make(chan *types.Receipt, 1)
 	sendTxAsync := func() {
@@ -78,12 +103,16 @@ func (m *SimpleTxManager) Send(ctx context.Context, updateGasPrice UpdateGasPric
 
 		err = sendTx(ctxc, tx)
 		sendState.ProcessSendError(err)
+		if !isRejected(err) {
+			published.Store(true)
+		}
 		if err != nil {
 			if err == context.Canceled || strings.Contains(err.Error(), "context canceled") {
 				return
 			}
 			log.Error("ContractsCaller unable to publish transaction", "err", err)
 			if sendState.ShouldAbortImmediately() {
+				nonceTooLow.Store(true)
 				cancel()
 			}
 			return
@@ -125,6 +154,14 @@ func (m *SimpleTxManager) Send(ctx context.Context, updateGasPrice UpdateGasPric
 			go sendTxAsync()
 
 		case <-ctxc.Done():
+			// the attempts in flight may still publish the transaction
+			wg.Wait()
+			if nonceTooLow.Load() {
+				return nil, ErrNonceTooLowAbort
+			}
+			if !published.Load() {
+				return nil, fmt.Errorf("%w: %w", ErrTxNotPublished, ctxc.Err())
+			}
 			return nil, ctxc.Err()
 
 		case receipt := <-receiptChan:
@@ -133,6 +170,19 @@ func (m *SimpleTxManager) Send(ctx context.Context, updateGasPrice UpdateGasPric
 	}
 }
 
+// isRejected tells whether the node answered the transaction with an error, so that it
+// never reached the mempool. A transport error or a timeout may hide a published
+// transaction, and a transaction already known or replaced is in the mempool
+func isRejected(err error) bool {
+	var rpcErr rpc.Error
+	if err == nil || !errors.As(err, &rpcErr) {
+		return false
+	}
+	msg := err.Error()
+	return !strings.Contains(msg, txpool.ErrAlreadyKnown.Error()) &&
+		!strings.Contains(msg, txpool.ErrReplaceUnderpriced.Error())
+}
+
 func WaitMined(
 	ctx context.Context,
 	backend ReceiptSource,
@@ -480,3 +480,68 @@ func TestWaitMinedReturnsReceiptAfterFailure(t *testing.T) {
 	require.NotNil(t, receipt)
 	require.Equal(t, receipt.TxHash, txHash)
 }
+
+func TestTxMgrAbortsOnNonceTooLow(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness()
+
+	updateGasPrice := func(ctx context.Context) (*types.Transaction, error) {
+		gasTipCap, gasFeeCap := h.gasPricer.sample()
+		return types.NewTx(&types.DynamicFeeTx{
+			GasTipCap: gasTipCap,
+			GasFeeCap: gasFeeCap,
+		}), nil
+	}
+
+	sendTx := func(ctx context.Context, tx *types.Transaction) error {
+		return core.ErrNonceTooLow
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	receipt, err := h.mgr.Send(ctx, updateGasPrice, sendTx)
+	require.ErrorIs(t, err, txmgr.ErrNonceTooLowAbort)
+	require.Nil(t, receipt)
+}
+
+// rejectedError is the error answered by the node to a transaction it rejects
+type rejectedError struct{}
+
+func (e rejectedError) Error() string  { return "insufficient funds for gas * price + value" }
+func (e rejectedError) ErrorCode() int { return -32000 }
+
+func TestTxMgrNotPublishedOnRejection(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness()
+
+	updateGasPrice := func(ctx context.Context) (*types.Transaction, error) {
+		gasTipCap, gasFeeCap := h.gasPricer.sample()
+		return types.NewTx(&types.DynamicFeeTx{
+			GasTipCap: gasTipCap,
+			GasFeeCap: gasFeeCap,
+		}), nil
+	}
+
+	// every attempt rejected by the node leaves the nonce unused
+	sendTx := func(ctx context.Context, tx *types.Transaction) error {
+		return rejectedError{}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	receipt, err := h.mgr.Send(ctx, updateGasPrice, sendTx)
+	require.ErrorIs(t, err, txmgr.ErrTxNotPublished)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Nil(t, receipt)
+
+	// a transport error may hide a published transaction
+	sendTx = func(ctx context.Context, tx *types.Transaction) error {
+		return errRpcFailure
+	}
+	ctx, cancel = context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	receipt, err = h.mgr.Send(ctx, updateGasPrice, sendTx)
+	require.NotErrorIs(t, err, txmgr.ErrTxNotPublished)
+	require.Nil(t, receipt)
+}
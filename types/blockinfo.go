@@ -18,6 +18,10 @@ type BlockInfo struct {
 	Hash      []byte
 	Finalized bool
 	StateRoot
+
+	// Seq is the sequence number of the indexer event the block is delivered from, the
+	// consumer acknowledges it once the block is handled
+	Seq uint64
 }
 
 type StateRoot struct {
@@ -27,12 +31,58 @@ type StateRoot struct {
 	L1BlockHash     common.Hash `json:"l1_block_hash"`
 	L1BlockNumber   uint64      `json:"l1_block_number"`
 	DisputeGameType uint64      `json:"dispute_game_type"`
+
+	// DisputeGameProxy is the game created by the DisputeGameFactory, it's only set
+	// for the dispute game outputs whose L2OutputIndex is the index of the game
+	DisputeGameProxy common.Address `json:"dispute_game_proxy"`
+
+	// Deleted is set once the output is deleted from the L2OutputOracle by the
+	// OutputsDeleted event emitted at DeletedL1BlockNumber, it must not be signed
+	Deleted              bool   `json:"deleted"`
+	DeletedL1BlockNumber uint64 `json:"deleted_l1_block_number,omitempty"`
 }
 
+// IsDisputeGame tells whether the output root is the root claim of a dispute game
+// instead of an output proposed to the L2OutputOracle
+func (s *StateRoot) IsDisputeGame() bool {
+	return s.DisputeGameProxy != (common.Address{})
+}
+
+// OutputsDeleted is the deletion of the L2OutputOracle outputs in the index range
+// [NewNextOutputIndex, PrevNextOutputIndex), StateRoots are the deleted outputs indexed so far
+type OutputsDeleted struct {
+	PrevNextOutputIndex *big.Int     `json:"prev_next_output_index"`
+	NewNextOutputIndex  *big.Int     `json:"new_next_output_index"`
+	L1BlockNumber       uint64       `json:"l1_block_number"`
+	StateRoots          []*StateRoot `json:"state_roots"`
+
+	// Seq is the sequence number of the indexer event the deletion is delivered from
+	Seq uint64 `json:"-"`
+}
+
+// SignRequest is the signature of an output submitted by an operator, the signed payload
+// depends on the version (see SignRequest.SigningHash)
 type SignRequest struct {
-	StateRoot   string `json:"state_root"`
-	Signature   []byte `json:"signature"`
-	SignAddress string `json:"sign_address"`
+	// Version is SignRequestVersionTyped for the EIP-712 signatures, it's omitted by the
+	// legacy requests signing the raw state root
+	Version       uint8    `json:"version,omitempty"`
+	StateRoot     string   `json:"state_root"`
+	Signature     []byte   `json:"signature"`
+	SignAddress   string   `json:"sign_address"`
+	L2BlockNumber *big.Int `json:"l2_block_number"`
+	L2OutputIndex *big.Int `json:"l2_output_index"`
+	// L1BlockHash is the L1 block the output was proposed at, it's signed by the typed requests only
+	L1BlockHash string `json:"l1_block_hash,omitempty"`
+
+	// the dispute game fields are only set for the root claim of a dispute game,
+	// the L2OutputIndex is then the index of the game in the DisputeGameFactory
+	DisputeGameProxy string `json:"dispute_game_proxy,omitempty"`
+	DisputeGameType  uint64 `json:"dispute_game_type,omitempty"`
+}
+
+// IsDisputeGame tells whether the signed state root is the root claim of a dispute game
+func (r *SignRequest) IsDisputeGame() bool {
+	return r.DisputeGameProxy != ""
 }
 
 type OperatorPaused struct {
@@ -0,0 +1,34 @@
+package types
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// OperatorStatus is the status of a symbiotic operator in the manta staking middleware
+type OperatorStatus int32
+
+const (
+	OperatorStatusActive OperatorStatus = iota
+	OperatorStatusPaused
+	OperatorStatusUnregistered
+)
+
+func (s OperatorStatus) String() string {
+	switch s {
+	case OperatorStatusActive:
+		return "ACTIVE"
+	case OperatorStatusPaused:
+		return "PAUSED"
+	case OperatorStatusUnregistered:
+		return "UNREGISTERED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// OperatorEvent is a status change of an operator emitted by the manta staking middleware
+type OperatorEvent struct {
+	Operator      common.Address
+	Status        OperatorStatus
+	L1BlockNumber uint64
+}
@@ -0,0 +1,177 @@
+package types
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// SignInterchangeVersion is the version of the sign record interchange format
+const SignInterchangeVersion = 1
+
+const (
+	// SigningSchemeEOTS is the scheme of the EOTS signatures made by eotsd, the pubkey is
+	// the hex BIP340 public key and the msg is the hex message signed at the height
+	SigningSchemeEOTS = "eots"
+	// SigningSchemeECDSA is the scheme of the output signatures made by sfpd, the pubkey
+	// is the operator address, the height is the L2 output index, or the game index of a
+	// dispute game, and the msg is the hex state root
+	SigningSchemeECDSA = "ecdsa"
+)
+
+var (
+	// ErrConflictingSignRecord indicates that a different msg was signed at the height of a
+	// sign record, the records are never merged in this case
+	ErrConflictingSignRecord = errors.New("conflicting sign record")
+)
+
+// SignInterchange is the JSON interchange format of the sign records, similar in spirit to
+// EIP-3076, it lets the double sign protection move along with a signing key:
+//
+//	{
+//	  "metadata": {"interchange_format_version": 1, "signing_scheme": "eots"},
+//	  "data": [{
+//	    "pubkey": "<hex BIP340 public key or operator address>",
+//	    "chain_id": "<chain id>",
+//	    "highest_signed_height": 120,
+//	    "signed_messages": [{"height": 120, "msg": "<hex>", "signature": "<hex>", "timestamp": 1700000000000}]
+//	  }]
+//	}
+//
+// The highest signed height is at least the height of every signed message, a signer may
+// refuse to sign at or below it without a record even if the messages are omitted
+type SignInterchange struct {
+	Metadata SignInterchangeMetadata `json:"metadata"`
+	Data     []*InterchangeSigner    `json:"data"`
+}
+
+type SignInterchangeMetadata struct {
+	Version       uint32 `json:"interchange_format_version"`
+	SigningScheme string `json:"signing_scheme"`
+}
+
+// InterchangeSigner is the signing history of one key on one chain
+type InterchangeSigner struct {
+	PubKey              string                      `json:"pubkey"`
+	ChainID             string                      `json:"chain_id"`
+	HighestSignedHeight uint64                      `json:"highest_signed_height"`
+	SignedMessages      []*InterchangeSignedMessage `json:"signed_messages"`
+}
+
+// InterchangeSignedMessage is a single signature of the signing history
+type InterchangeSignedMessage struct {
+	Height    uint64 `json:"height"`
+	Msg       string `json:"msg"`
+	Signature string `json:"signature"`
+	// Timestamp is the timestamp of the signing operation, in Unix milliseconds
+	Timestamp int64 `json:"timestamp,omitempty"`
+	// DisputeGame tells whether the height is the game index of a dispute game, ecdsa only
+	DisputeGame bool `json:"dispute_game,omitempty"`
+	// L2BlockNumber is the L2 block of the signed output, ecdsa only
+	L2BlockNumber *uint64 `json:"l2_block_number,omitempty"`
+	// DeletedAt is set once the signed output is deleted from the L2OutputOracle, in Unix
+	// milliseconds, ecdsa only
+	DeletedAt int64 `json:"deleted_at,omitempty"`
+	// SignatureVersion is the SignRequest version the signature is made for, ecdsa only
+	SignatureVersion uint8 `json:"signature_version,omitempty"`
+	// L1BlockHash is the L1 block hash of the signed typed data, ecdsa only
+	L1BlockHash string `json:"l1_block_hash,omitempty"`
+}
+
+func NewSignInterchange(signingScheme string) *SignInterchange {
+	return &SignInterchange{
+		Metadata: SignInterchangeMetadata{
+			Version:       SignInterchangeVersion,
+			SigningScheme: signingScheme,
+		},
+		Data: []*InterchangeSigner{},
+	}
+}
+
+// LoadSignInterchange reads and validates the interchange file of the signing scheme
+func LoadSignInterchange(path string, signingScheme string) (*SignInterchange, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	interchange := &SignInterchange{}
+	if err := json.Unmarshal(data, interchange); err != nil {
+		return nil, fmt.Errorf("invalid interchange file: %w", err)
+	}
+	if interchange.Metadata.SigningScheme != signingScheme {
+		return nil, fmt.Errorf("the signing scheme %q of the interchange file is not %q", interchange.Metadata.SigningScheme, signingScheme)
+	}
+	if err := interchange.Validate(); err != nil {
+		return nil, err
+	}
+	return interchange, nil
+}
+
+// Save writes the interchange file, the file is only readable by the user
+func (i *SignInterchange) Save(path string) error {
+	data, err := json.MarshalIndent(i, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// Validate checks the version and the encoding of the interchange, and that the signed
+// messages of a signer don't conflict at a height
+func (i *SignInterchange) Validate() error {
+	if i.Metadata.Version != SignInterchangeVersion {
+		return fmt.Errorf("unsupported interchange format version %d", i.Metadata.Version)
+	}
+	if i.Metadata.SigningScheme != SigningSchemeEOTS && i.Metadata.SigningScheme != SigningSchemeECDSA {
+		return fmt.Errorf("unsupported signing scheme %q", i.Metadata.SigningScheme)
+	}
+
+	type signerKey struct{ pubKey, chainID string }
+	signers := make(map[signerKey]struct{}, len(i.Data))
+	for _, signer := range i.Data {
+		key := signerKey{pubKey: signer.PubKey, chainID: signer.ChainID}
+		if _, ok := signers[key]; ok {
+			return fmt.Errorf("duplicate signer %s on chain %s", signer.PubKey, signer.ChainID)
+		}
+		signers[key] = struct{}{}
+		if err := signer.validate(); err != nil {
+			return fmt.Errorf("invalid signer %s on chain %s: %w", signer.PubKey, signer.ChainID, err)
+		}
+	}
+	return nil
+}
+
+func (s *InterchangeSigner) validate() error {
+	if s.PubKey == "" {
+		return errors.New("empty pubkey")
+	}
+
+	type heightKey struct {
+		height      uint64
+		disputeGame bool
+	}
+	msgs := make(map[heightKey]string, len(s.SignedMessages))
+	for _, signedMsg := range s.SignedMessages {
+		if _, err := hex.DecodeString(signedMsg.Msg); err != nil {
+			return fmt.Errorf("invalid msg at height %d: %w", signedMsg.Height, err)
+		}
+		if _, err := hex.DecodeString(signedMsg.Signature); err != nil {
+			return fmt.Errorf("invalid signature at height %d: %w", signedMsg.Height, err)
+		}
+		if !signedMsg.DisputeGame && signedMsg.Height > s.HighestSignedHeight {
+			return fmt.Errorf("the signed height %d is above the highest signed height %d", signedMsg.Height, s.HighestSignedHeight)
+		}
+		if signedMsg.DeletedAt != 0 {
+			// several roots can be signed and deleted at an output index
+			continue
+		}
+		key := heightKey{height: signedMsg.Height, disputeGame: signedMsg.DisputeGame}
+		if msg, ok := msgs[key]; ok && msg != signedMsg.Msg {
+			return fmt.Errorf("%w at height %d", ErrConflictingSignRecord, signedMsg.Height)
+		}
+		msgs[key] = signedMsg.Msg
+	}
+	return nil
+}
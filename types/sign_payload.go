@@ -0,0 +1,210 @@
+package types
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+const (
+	// SignRequestVersionLegacy is the sign request whose signature is made over the raw
+	// state root, it can be replayed anywhere the same root appears and is only verified
+	SignRequestVersionLegacy uint8 = 0
+	// SignRequestVersionTyped is the sign request whose signature is made over the EIP-712
+	// typed data of the output, see StateRootTypedData
+	SignRequestVersionTyped uint8 = 1
+
+	// SignDomainName and SignDomainVersion are the name and the version of the EIP-712
+	// domain of the state root signatures
+	SignDomainName    = "MantaStakingMiddleware"
+	SignDomainVersion = "1"
+
+	stateRootPrimaryType        = "StateRoot"
+	disputeGameClaimPrimaryType = "DisputeGameClaim"
+)
+
+var (
+	// ErrSignAddressMismatch indicates that the signature of a sign request is not made by its sign address
+	ErrSignAddressMismatch = errors.New("the signer doesn't match the sign address")
+)
+
+// SignDomain separates the state root signatures of a deployment of the manta staking
+// middleware from the signatures of any other chain or contract
+type SignDomain struct {
+	ChainID                    *big.Int
+	MantaStakingMiddlewareAddr common.Address
+}
+
+// StateRootTypedData returns the EIP-712 typed data signed for an output of the L2OutputOracle:
+//
+//	StateRoot(bytes32 outputRoot,uint256 l2BlockNumber,uint256 l2OutputIndex,bytes32 l1BlockHash)
+func StateRootTypedData(domain *SignDomain, outputRoot common.Hash, l2BlockNumber, l2OutputIndex *big.Int, l1BlockHash common.Hash) apitypes.TypedData {
+	return typedData(domain, stateRootPrimaryType, []apitypes.Type{
+		{Name: "outputRoot", Type: "bytes32"},
+		{Name: "l2BlockNumber", Type: "uint256"},
+		{Name: "l2OutputIndex", Type: "uint256"},
+		{Name: "l1BlockHash", Type: "bytes32"},
+	}, apitypes.TypedDataMessage{
+		"outputRoot":    outputRoot.Bytes(),
+		"l2BlockNumber": l2BlockNumber,
+		"l2OutputIndex": l2OutputIndex,
+		"l1BlockHash":   l1BlockHash.Bytes(),
+	})
+}
+
+// DisputeGameClaimTypedData returns the EIP-712 typed data signed for the root claim of a
+// dispute game:
+//
+//	DisputeGameClaim(bytes32 rootClaim,uint256 l2BlockNumber,uint256 gameIndex,address disputeGameProxy,uint32 gameType,bytes32 l1BlockHash)
+//
+// the game index is the index of the game in the DisputeGameFactory, the own primary type
+// keeps the claim of game N from being signed as the L2OutputOracle output N
+func DisputeGameClaimTypedData(domain *SignDomain, rootClaim common.Hash, l2BlockNumber, gameIndex *big.Int, disputeGameProxy common.Address, gameType uint32, l1BlockHash common.Hash) apitypes.TypedData {
+	return typedData(domain, disputeGameClaimPrimaryType, []apitypes.Type{
+		{Name: "rootClaim", Type: "bytes32"},
+		{Name: "l2BlockNumber", Type: "uint256"},
+		{Name: "gameIndex", Type: "uint256"},
+		{Name: "disputeGameProxy", Type: "address"},
+		{Name: "gameType", Type: "uint32"},
+		{Name: "l1BlockHash", Type: "bytes32"},
+	}, apitypes.TypedDataMessage{
+		"rootClaim":        rootClaim.Bytes(),
+		"l2BlockNumber":    l2BlockNumber,
+		"gameIndex":        gameIndex,
+		"disputeGameProxy": disputeGameProxy.Hex(),
+		"gameType":         new(big.Int).SetUint64(uint64(gameType)),
+		"l1BlockHash":      l1BlockHash.Bytes(),
+	})
+}
+
+func typedData(domain *SignDomain, primaryType string, fields []apitypes.Type, message apitypes.TypedDataMessage) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			primaryType: fields,
+		},
+		PrimaryType: primaryType,
+		Domain: apitypes.TypedDataDomain{
+			Name:              SignDomainName,
+			Version:           SignDomainVersion,
+			ChainId:           (*math.HexOrDecimal256)(domain.ChainID),
+			VerifyingContract: domain.MantaStakingMiddlewareAddr.Hex(),
+		},
+		Message: message,
+	}
+}
+
+// StateRootSigningHash returns the EIP-712 hash signed for the output, the root claims of
+// the dispute games are signed as DisputeGameClaim
+func StateRootSigningHash(domain *SignDomain, stateRoot *StateRoot) (common.Hash, error) {
+	if stateRoot.L2BlockNumber == nil || stateRoot.L2OutputIndex == nil {
+		return common.Hash{}, errors.New("the l2 block number and the l2 output index are required")
+	}
+	data := StateRootTypedData(domain, stateRoot.StateRoot, stateRoot.L2BlockNumber, stateRoot.L2OutputIndex, stateRoot.L1BlockHash)
+	if stateRoot.IsDisputeGame() {
+		data = DisputeGameClaimTypedData(domain, stateRoot.StateRoot, stateRoot.L2BlockNumber, stateRoot.L2OutputIndex,
+			stateRoot.DisputeGameProxy, uint32(stateRoot.DisputeGameType), stateRoot.L1BlockHash)
+	}
+	hash, _, err := apitypes.TypedDataAndHash(data)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to hash the typed data: %w", err)
+	}
+	return common.BytesToHash(hash), nil
+}
+
+// SigningHash returns the hash signed for the sign request of its version
+func (r *SignRequest) SigningHash(domain *SignDomain) (common.Hash, error) {
+	stateRoot, err := DecodeHash(r.StateRoot)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("invalid state root %q", r.StateRoot)
+	}
+
+	switch r.Version {
+	case SignRequestVersionLegacy:
+		return stateRoot, nil
+	case SignRequestVersionTyped:
+		l1BlockHash, err := DecodeHash(r.L1BlockHash)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("invalid l1 block hash %q", r.L1BlockHash)
+		}
+		signed := &StateRoot{
+			StateRoot:     stateRoot,
+			L2BlockNumber: r.L2BlockNumber,
+			L2OutputIndex: r.L2OutputIndex,
+			L1BlockHash:   l1BlockHash,
+		}
+		if r.IsDisputeGame() {
+			if !common.IsHexAddress(r.DisputeGameProxy) || common.HexToAddress(r.DisputeGameProxy) == (common.Address{}) {
+				return common.Hash{}, fmt.Errorf("invalid dispute game proxy %q", r.DisputeGameProxy)
+			}
+			signed.DisputeGameProxy = common.HexToAddress(r.DisputeGameProxy)
+			signed.DisputeGameType = r.DisputeGameType
+		}
+		return StateRootSigningHash(domain, signed)
+	default:
+		return common.Hash{}, fmt.Errorf("unsupported sign request version %d", r.Version)
+	}
+}
+
+// RecoverSigner recovers the address which signed the sign request in the domain, the
+// signature is in the [R || S || V] format where V is 0 or 1, or 27 or 28
+func (r *SignRequest) RecoverSigner(domain *SignDomain) (common.Address, error) {
+	hash, err := r.SigningHash(domain)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(r.Signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("invalid signature length: %d", len(r.Signature))
+	}
+
+	signature := make([]byte, crypto.SignatureLength)
+	copy(signature, r.Signature)
+	if signature[crypto.RecoveryIDOffset] >= 27 {
+		signature[crypto.RecoveryIDOffset] -= 27
+	}
+	pubKey, err := crypto.SigToPub(hash.Bytes(), signature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover the signer: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
+
+// VerifySignRequest checks that the sign request is signed by its sign address in the
+// domain, the relayers should refuse the legacy requests once all the operators sign the
+// typed data
+func VerifySignRequest(signRequest *SignRequest, domain *SignDomain) error {
+	signer, err := signRequest.RecoverSigner(domain)
+	if err != nil {
+		return err
+	}
+	if !common.IsHexAddress(signRequest.SignAddress) || common.HexToAddress(signRequest.SignAddress) != signer {
+		return fmt.Errorf("%w: recovered %s", ErrSignAddressMismatch, signer.Hex())
+	}
+	return nil
+}
+
+// DecodeHash decodes a 32 bytes hex string with or without the 0x prefix
+func DecodeHash(s string) (common.Hash, error) {
+	if len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		s = s[2:]
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid hash length: %d", len(b))
+	}
+	return common.BytesToHash(b), nil
+}
@@ -0,0 +1,70 @@
+package types_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/Manta-Network/manta-fp/types"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testSignDomain = &types.SignDomain{
+	ChainID:                    big.NewInt(1),
+	MantaStakingMiddlewareAddr: common.HexToAddress("0x1000"),
+}
+
+func TestDisputeGameSignatureIsNotAnOutputSignature(t *testing.T) {
+	t.Parallel()
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	// the output 3 of the L2OutputOracle and the game 3 of the DisputeGameFactory claim the same root
+	output := &types.StateRoot{
+		StateRoot:     common.HexToHash("0x01"),
+		L2BlockNumber: big.NewInt(300),
+		L2OutputIndex: big.NewInt(3),
+		L1BlockHash:   common.HexToHash("0x02"),
+	}
+	outputHash, err := types.StateRootSigningHash(testSignDomain, output)
+	require.NoError(t, err)
+	signature, err := crypto.Sign(outputHash.Bytes(), key)
+	require.NoError(t, err)
+
+	signRequest := &types.SignRequest{
+		Version:       types.SignRequestVersionTyped,
+		StateRoot:     common.Hash(output.StateRoot).Hex(),
+		Signature:     signature,
+		SignAddress:   crypto.PubkeyToAddress(key.PublicKey).Hex(),
+		L2BlockNumber: output.L2BlockNumber,
+		L2OutputIndex: output.L2OutputIndex,
+		L1BlockHash:   output.L1BlockHash.Hex(),
+	}
+	require.NoError(t, types.VerifySignRequest(signRequest, testSignDomain))
+
+	// the output signature doesn't verify as the signature of the game
+	signRequest.DisputeGameProxy = common.HexToAddress("0x10").Hex()
+	signRequest.DisputeGameType = 1
+	require.ErrorIs(t, types.VerifySignRequest(signRequest, testSignDomain), types.ErrSignAddressMismatch)
+
+	// the game signature binds the proxy and the type of the game
+	game := *output
+	game.DisputeGameProxy = common.HexToAddress("0x10")
+	game.DisputeGameType = 1
+	gameHash, err := types.StateRootSigningHash(testSignDomain, &game)
+	require.NoError(t, err)
+	require.NotEqual(t, outputHash, gameHash)
+	signRequest.Signature, err = crypto.Sign(gameHash.Bytes(), key)
+	require.NoError(t, err)
+	require.NoError(t, types.VerifySignRequest(signRequest, testSignDomain))
+
+	signRequest.DisputeGameType = 0
+	require.ErrorIs(t, types.VerifySignRequest(signRequest, testSignDomain), types.ErrSignAddressMismatch)
+	signRequest.DisputeGameType = 1
+	signRequest.DisputeGameProxy = common.HexToAddress("0x11").Hex()
+	require.ErrorIs(t, types.VerifySignRequest(signRequest, testSignDomain), types.ErrSignAddressMismatch)
+}